@@ -0,0 +1,191 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// extract is a job that extracts an image or video snippet from a video stream using ffmpeg, stores it in
+// media storage, and completes the task that launched it with the URL of the media.
+//
+// Usage:
+//
+//	extract --task=<id> --videostream=<id> --type=image/jpeg --time=00:00:01.000
+//	extract --task=<id> --videostream=<id> --type=video/mp4 --time=00:00:01.000-00:00:05.500
+//
+// YouTube streams are resolved to a direct URL using yt-dlp, so both ffmpeg and yt-dlp must be installed.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/mediatype"
+	"github.com/ausocean/openfish/cmd/openfish/types/timespan"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+func main() {
+	taskID := flag.Int64("task", 0, "ID of the task to complete")
+	videoStreamID := flag.Int64("videostream", 0, "ID of the video stream to extract media from")
+	mimeType := flag.String("type", "image/jpeg", "Mime type of the media")
+	t := flag.String("time", "", "Time of an image, or time span of a video, within the video stream")
+	useFilestore := flag.Bool("filestore", false, "Use local datastore")
+	useLocalStorage := flag.Bool("local-storage", false, "Use local storage instead of Cloud Buckets")
+	flag.Parse()
+
+	// Datastore and storage setup.
+	err := globals.InitStore(*useFilestore)
+	if err != nil {
+		log.Fatalf("could not initialize datastore: %v", err)
+	}
+	err = globals.InitStorage(*useLocalStorage)
+	if err != nil {
+		log.Fatalf("could not initialize storage: %v", err)
+	}
+
+	key, err := parseMediaKey(*videoStreamID, *mimeType, *t)
+	if err != nil {
+		failTask(*taskID, err)
+		log.Fatalf("invalid arguments: %v", err)
+	}
+
+	data, err := extract(key)
+	if err != nil {
+		failTask(*taskID, err)
+		log.Fatalf("could not extract media: %v", err)
+	}
+
+	err = services.CompleteMediaExtraction(*taskID, key, data)
+	if err != nil {
+		log.Fatalf("could not store media: %v", err)
+	}
+	log.Printf("extracted %s", key.ToStorageName())
+}
+
+// failTask marks the task as failed with err, logging if the task could not be updated.
+func failTask(id int64, err error) {
+	if err := services.FailTask(id, err); err != nil {
+		log.Printf("could not fail task %d: %v", id, err)
+	}
+}
+
+// parseMediaKey parses the arguments describing the media to extract.
+func parseMediaKey(videoStreamID int64, mimeType string, t string) (services.MediaKey, error) {
+	mtype, err := mediatype.ParseMimeType(mimeType)
+	if err != nil {
+		return services.MediaKey{}, err
+	}
+
+	key := services.MediaKey{Type: mtype, VideoStreamID: videoStreamID}
+	if mtype.IsVideo() {
+		var ts timespan.TimeSpan
+		if err := ts.UnmarshalText([]byte(t)); err != nil {
+			return services.MediaKey{}, err
+		}
+		if !ts.Valid() {
+			return services.MediaKey{}, fmt.Errorf("invalid time span, start time must occur before end time")
+		}
+		key.StartTime = ts.Start
+		key.EndTime = &ts.End
+	} else {
+		key.StartTime, err = videotime.Parse(t)
+		if err != nil {
+			return services.MediaKey{}, err
+		}
+	}
+	return key, nil
+}
+
+// extract extracts the media from its video stream using ffmpeg.
+func extract(key services.MediaKey) ([]byte, error) {
+	vs, err := services.GetVideoStreamByID(key.VideoStreamID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get video stream %d: %w", key.VideoStreamID, err)
+	}
+	src, err := resolveSource(vs.StreamURL)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"-hide_banner", "-loglevel", "error", "-ss", seconds(key.StartTime), "-i", src}
+	if key.Type.IsVideo() {
+		duration := videotime.FromInt(key.EndTime.Int() - key.StartTime.Int())
+		args = append(args, "-t", seconds(duration), "-an", "-c:v", "libx264", "-movflags", "frag_keyframe+empty_moov", "-f", "mp4")
+	} else {
+		args = append(args, "-frames:v", "1", "-q:v", "2", "-f", "image2", "-c:v", "mjpeg")
+	}
+	args = append(args, "pipe:1")
+
+	return run("ffmpeg", args...)
+}
+
+// resolveSource returns a URL that ffmpeg can read the video stream from. YouTube pages are resolved to
+// the URL of the video itself.
+func resolveSource(streamURL string) (string, error) {
+	u, err := url.Parse(streamURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid stream URL %s: %w", streamURL, err)
+	}
+	host := strings.TrimPrefix(u.Hostname(), "www.")
+	if host != "youtube.com" && host != "youtu.be" && host != "m.youtube.com" {
+		return streamURL, nil
+	}
+
+	out, err := run("yt-dlp", "--get-url", "--format", "best[ext=mp4]/best", streamURL)
+	if err != nil {
+		return "", err
+	}
+	src, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return src, nil
+}
+
+// seconds formats a video time in seconds, for ffmpeg.
+func seconds(t videotime.VideoTime) string {
+	return fmt.Sprintf("%d.%03d", t.Int()/1000, t.Int()%1000)
+}
+
+// run runs a command and returns its output, or an error including anything it wrote to stderr.
+func run(name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
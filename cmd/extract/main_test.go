@@ -0,0 +1,71 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+func TestParseMediaKeyVideo(t *testing.T) {
+	key, err := parseMediaKey(1234567890, "video/mp4", "00:00:01.000-00:00:05.500")
+	if err != nil {
+		t.Fatalf("Could not parse media key %s", err)
+	}
+
+	start := videotime.UncheckedParse("00:00:01.000")
+	end := videotime.UncheckedParse("00:00:05.500")
+	if key.StartTime != start || key.EndTime == nil || *key.EndTime != end {
+		t.Errorf("Expected time span %s-%s, got %s-%v", start, end, key.StartTime, key.EndTime)
+	}
+}
+
+func TestParseMediaKeyImage(t *testing.T) {
+	key, err := parseMediaKey(1234567890, "image/jpeg", "00:00:01.000")
+	if err != nil {
+		t.Fatalf("Could not parse media key %s", err)
+	}
+
+	if key.StartTime != videotime.UncheckedParse("00:00:01.000") || key.EndTime != nil {
+		t.Errorf("Expected image at 00:00:01.000, got %s", key.StartTime)
+	}
+}
+
+func TestParseMediaKeyInvalidTimeSpan(t *testing.T) {
+	_, err := parseMediaKey(1234567890, "video/mp4", "00:00:05.500-00:00:01.000")
+	if err == nil {
+		t.Errorf("Expected error for time span ending before it starts")
+	}
+}
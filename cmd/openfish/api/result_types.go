@@ -47,6 +47,12 @@ type Result[T any] struct {
 	Total   int `json:"total" example:"1"`
 }
 
+// TaskStarted is the JSON format to use in response bodies for starting an asynchronous task.
+// Task progress can be tracked by polling the task API endpoint.
+type TaskStarted struct {
+	TaskID int64 `json:"task_id" example:"1234567890"`
+}
+
 // Failure is the JSON format to use in response bodies for returning errors.
 type Failure struct {
 	Message string `json:"message" example:"error message here"`
@@ -72,6 +78,11 @@ func InvalidRequestURL(err error) error {
 	return fiber.NewError(400, fmt.Errorf("invalid URL in request: %w", err).Error())
 }
 
+// InvalidRequestHeader returns an error for requests with an invalid header.
+func InvalidRequestHeader(name string, err error) error {
+	return fiber.NewError(400, fmt.Errorf("invalid %s header in request: %w", name, err).Error())
+}
+
 // Unauthorized returns an error for unauthorized requests.
 func Unauthorized(err error) error {
 	return fiber.NewError(401, fmt.Errorf("Unauthorized: %w", err).Error())
@@ -98,3 +109,21 @@ func NotFound(err error) error {
 func Conflict(err error) error {
 	return fiber.NewError(409, fmt.Errorf("Conflict: %w", err).Error())
 }
+
+// PreconditionFailed returns an error for requests with a precondition that
+// does not hold, e.g. an If-Match header for an out of date version.
+func PreconditionFailed(err error) error {
+	return fiber.NewError(412, fmt.Errorf("Precondition Failed: %w", err).Error())
+}
+
+// PreconditionRequired returns an error for requests that must be made
+// conditional, e.g. updates that require an If-Match header.
+func PreconditionRequired(err error) error {
+	return fiber.NewError(428, fmt.Errorf("Precondition Required: %w", err).Error())
+}
+
+// ServiceUnavailable returns an error for requests that need a service that
+// is not available, e.g. running a job when no job runner is configured.
+func ServiceUnavailable(err error) error {
+	return fiber.NewError(503, fmt.Errorf("Service Unavailable: %w", err).Error())
+}
@@ -35,6 +35,8 @@ LICENSE
 package entities
 
 import (
+	"time"
+
 	"github.com/ausocean/cloud/datastore"
 	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
 )
@@ -49,6 +51,7 @@ type Annotation struct {
 	Keypoints     []struct {
 		Time string
 		keypoint.BoundingBox
+		Polygon string `datastore:",noindex"` // Vertices as space separated "x,y" pairs.
 	}
 	CreatedBy int64
 
@@ -57,6 +60,35 @@ type Annotation struct {
 	// multiple times if it is identified by many users.
 	IdentificationUserID    []int64
 	IdentificationSpeciesID []int64
+
+	// The consensus of the identifications is stored so we can query for annotations
+	// that have been agreed upon.
+	ConsensusSpeciesID int64
+	ConsensusStatus    string
+	ConsensusAgreement float64 `datastore:",noindex"`
+
+	// Review workflow. SubmittedAt is used to order the review queue.
+	ReviewStatus string
+	SubmittedAt  time.Time
+	ReviewedBy   int64
+	ReviewReason string `datastore:",noindex"`
+
+	// Attributes are stored as "name=value" strings, so we can query for annotations
+	// with a particular attribute value.
+	Attributes []string
+
+	// Version is incremented every time the annotation is modified, so concurrent
+	// edits can be detected.
+	Version int64
+
+	// The last change is written with the annotation, so that its revision can be
+	// recorded from the annotation if writing the revision fails.
+	LastChange          string    `datastore:",noindex"`
+	LastChangeSpeciesID *int64    `datastore:",noindex"`
+	LastChangedBy       int64     `datastore:",noindex"`
+	LastChangedAt       time.Time `datastore:",noindex"`
+
+	Key *datastore.Key `datastore:"__key__" json:"-"` // Not persistent but populated upon reading from the datastore.
 	datastore.NoCache
 }
 
@@ -0,0 +1,63 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package entities
+
+import (
+	"github.com/ausocean/cloud/datastore"
+)
+
+// Kind of entity to store / fetch from the datastore.
+const ATTRIBUTE_KIND = "Attribute"
+
+// Attribute defines a kind of observation that can be recorded on annotations, such as behaviour or life stage.
+// Attributes are keyed by their name. Options are the allowed values of enum attributes, and Min and Max
+// are the optional bounds of number attributes.
+type Attribute struct {
+	Description string
+	Type        string
+	Options     []string
+	Min         *float64 // Optional.
+	Max         *float64 // Optional.
+	datastore.NoCache
+}
+
+// Implements Copy from the Entity interface.
+func (a *Attribute) Copy(dst datastore.Entity) (datastore.Entity, error) {
+	return datastore.CopyEntity(a, dst)
+}
+
+// NewAttribute returns a new Attribute entity.
+func NewAttribute() datastore.Entity {
+	return &Attribute{}
+}
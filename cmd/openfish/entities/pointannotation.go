@@ -0,0 +1,66 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package entities
+
+import (
+	"github.com/ausocean/cloud/datastore"
+)
+
+// Kind of entity to store / fetch from the datastore.
+const POINT_ANNOTATION_KIND = "PointAnnotation"
+
+// A PointAnnotation is a count of individuals of a species at a single moment within a video stream,
+// with a point marking each individual. It is used for dense groups such as schools of fish, where
+// boxing every individual is impractical.
+type PointAnnotation struct {
+	VideoStreamID int64
+	Time          int64  // Milliseconds from the start of the video stream.
+	Points        string `datastore:",noindex"` // Points as space separated "x,y" pairs.
+	Count         int64
+	SpeciesID     int64
+	CreatedBy     int64
+
+	Key *datastore.Key `datastore:"__key__" json:"-"` // Not persistent but populated upon reading from the datastore.
+	datastore.NoCache
+}
+
+// Implements Copy from the Entity interface.
+func (p *PointAnnotation) Copy(dst datastore.Entity) (datastore.Entity, error) {
+	return datastore.CopyEntity(p, dst)
+}
+
+// NewPointAnnotation returns a new PointAnnotation entity.
+func NewPointAnnotation() datastore.Entity {
+	return &PointAnnotation{}
+}
@@ -49,6 +49,8 @@ type Species struct {
 	INaturalistTaxonID *int // Optional.
 	SearchIndex        []string
 	datastore.NoCache
+
+	Key *datastore.Key `datastore:"__key__" json:"-"` // Not persistent but populated upon reading from the datastore.
 }
 
 // Implements Copy from the Entity interface.
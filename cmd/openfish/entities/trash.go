@@ -0,0 +1,67 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package entities
+
+import (
+	"time"
+
+	"github.com/ausocean/cloud/datastore"
+)
+
+// Kind of entity to store / fetch from the datastore.
+const TRASH_KIND = "Trash"
+
+// TrashItem is a deleted entity, kept so that it can be restored until it is purged. Deleted entities are
+// removed from their own kind, so they are excluded from every query without needing a filter.
+// Trash items are keyed by the kind and ID of the deleted entity, e.g. "VideoStream.1234567890".
+type TrashItem struct {
+	Key       *datastore.Key `datastore:"__key__" json:"-"` // Not persistent but populated upon reading from the datastore.
+	Kind      string
+	EntityID  int64
+	Name      string `datastore:",noindex"` // Description of the entity, for listing the trash.
+	DeletedAt time.Time
+	DeletedBy int64
+	Snapshot  []byte `datastore:",noindex"` // JSON encoded entity.
+	datastore.NoCache
+}
+
+// Implements Copy from the Entity interface.
+func (t *TrashItem) Copy(dst datastore.Entity) (datastore.Entity, error) {
+	return datastore.CopyEntity(t, dst)
+}
+
+// NewTrashItem returns a new TrashItem entity.
+func NewTrashItem() datastore.Entity {
+	return &TrashItem{}
+}
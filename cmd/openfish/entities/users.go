@@ -46,6 +46,8 @@ type User struct {
 	DisplayName string
 	Email       string
 	Role        role.Role
+
+	Key *datastore.Key `datastore:"__key__" json:"-"` // Not persistent but populated upon reading from the datastore.
 	datastore.NoCache
 }
 
@@ -44,7 +44,7 @@ const VIDEOSTREAM_KIND = "VideoStream"
 
 // VideoStream holds the information about a single video stream.
 // VideoStream contains the url for a live or completed stream off of youtube, the start time,
-// the end time (unless it is still ongoing), and the ID of its capture source.
+// the end time (unless it is still ongoing), the ID of its capture source, and its resolution if known.
 type VideoStream struct {
 	StartTime     time.Time
 	EndTime       *time.Time // Optional.
@@ -52,6 +52,10 @@ type VideoStream struct {
 	StreamURL     string
 	CaptureSource int64
 	AnnotatorList []int64
+	Width         int // Optional.
+	Height        int // Optional.
+
+	Key *datastore.Key `datastore:"__key__" json:"-"` // Not persistent but populated upon reading from the datastore.
 	datastore.NoCache
 }
 
@@ -31,19 +31,23 @@ LICENSE
   OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 */
 
-// Package globals makes the datastore and storage available to other packages through the use of GetStore() and GetStorage().
+// Package globals makes the datastore, storage and job runner available to other packages through the use of GetStore(),
+// GetStorage() and GetJobRunner().
 package globals
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/ausocean/cloud/datastore"
 	"github.com/ausocean/openfish/cmd/openfish/entities"
+	"github.com/ausocean/openfish/jobrunner"
 	"github.com/ausocean/openfish/storage"
 )
 
 var bucket storage.Storage
 var store datastore.Store
+var runner jobrunner.JobRunner
 
 // GetStore returns the datastore global variable.
 func GetStore() datastore.Store {
@@ -56,6 +60,7 @@ func InitStore(local bool) error {
 	var err error
 	if local {
 		store, err = datastore.NewStore(ctx, "file", "openfish", "./store")
+		store = fileStore{store}
 	} else {
 		store, err = datastore.NewStore(ctx, "cloud", "openfish", "")
 	}
@@ -66,10 +71,62 @@ func InitStore(local bool) error {
 	datastore.RegisterEntity(entities.SPECIES_KIND, entities.NewSpecies)
 	datastore.RegisterEntity(entities.USER_KIND, entities.NewUser)
 	datastore.RegisterEntity(entities.TASK_KIND, entities.NewTask)
+	datastore.RegisterEntity(entities.ANNOTATION_REVISION_KIND, entities.NewAnnotationRevision)
+	datastore.RegisterEntity(entities.ATTRIBUTE_KIND, entities.NewAttribute)
+	datastore.RegisterEntity(entities.POINT_ANNOTATION_KIND, entities.NewPointAnnotation)
+	datastore.RegisterEntity(entities.TRASH_KIND, entities.NewTrashItem)
 
 	return err
 }
 
+// SetStore sets the datastore global variable, e.g. to wrap the file store in tests.
+func SetStore(s datastore.Store) {
+	store = s
+}
+
+// fileStore wraps the file store so that keys-only queries are filtered, as they are in the cloud datastore.
+// The file store ignores field filters in keys-only queries, so they read whole entities instead.
+type fileStore struct {
+	datastore.Store
+}
+
+// keysOnlyQuery is a keys-only query of a fileStore.
+type keysOnlyQuery struct {
+	datastore.Query
+	kind string
+}
+
+// NewQuery returns a new query of kind.
+func (s fileStore) NewQuery(kind string, keysOnly bool, keyParts ...string) datastore.Query {
+	query := s.Store.NewQuery(kind, false, keyParts...)
+	if keysOnly {
+		return &keysOnlyQuery{query, kind}
+	}
+	return query
+}
+
+// GetAll runs a query. Keys-only queries return the keys of the matching entities, which must have a Key field.
+func (s fileStore) GetAll(ctx context.Context, query datastore.Query, dst interface{}) ([]*datastore.Key, error) {
+	q, ok := query.(*keysOnlyQuery)
+	if !ok {
+		return s.Store.GetAll(ctx, query, dst)
+	}
+	e, err := datastore.NewEntity(q.kind)
+	if err != nil {
+		return nil, err
+	}
+	ents := reflect.New(reflect.SliceOf(reflect.TypeOf(e).Elem()))
+	_, err = s.Store.GetAll(ctx, q.Query, ents.Interface())
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*datastore.Key, ents.Elem().Len())
+	for i := range keys {
+		keys[i] = ents.Elem().Index(i).FieldByName("Key").Interface().(*datastore.Key)
+	}
+	return keys, nil
+}
+
 // GetStorage returns the storage global variable and storage API client.
 func GetStorage() storage.Storage {
 	return bucket
@@ -85,3 +142,13 @@ func InitStorage(local bool) error {
 	}
 	return err
 }
+
+// GetJobRunner returns the job runner global variable, or nil if no job runner has been initialized.
+func GetJobRunner() jobrunner.JobRunner {
+	return runner
+}
+
+// InitJobRunner initializes the job runner global variable.
+func InitJobRunner(r jobrunner.JobRunner) {
+	runner = r
+}
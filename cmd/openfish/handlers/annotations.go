@@ -35,34 +35,66 @@ LICENSE
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/ausocean/cloud/datastore"
 	"github.com/ausocean/openfish/cmd/openfish/api"
 	"github.com/ausocean/openfish/cmd/openfish/services"
 	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/mediatype"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+	"github.com/ausocean/openfish/cmd/openfish/types/timespan"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 // GetAnnotationsQuery describes the URL query parameters required for the GetAnnotations endpoint.
 type GetAnnotationsQuery struct {
-	// TimeSpan      *string           `query:"timespan"`      // Optional. TODO: choose more appropriate type.
-	// CaptureSource *int64            `query:"capturesource"` // Optional.
-	VideoStream *int64 `query:"videostream"` // Optional.
+	VideoStream   *int64                    `query:"videostream"`   // Optional.
+	CaptureSource *int64                    `query:"capturesource"` // Optional.
+	Species       *int64                    `query:"species"`       // Optional.
+	IdentifiedBy  *int64                    `query:"identified_by"` // Optional.
+	CreatedBy     *int64                    `query:"created_by"`    // Optional.
+	Consensus     *services.ConsensusStatus `query:"consensus"`     // Optional.
+	Status        *services.ReviewStatus    `query:"status"`        // Optional.
+	TimeSpan      *timespan.TimeSpan        `query:"timespan"`      // Optional.
+	From          *time.Time                `query:"from"`          // Optional.
+	To            *time.Time                `query:"to"`            // Optional.
+	Type          *services.AnnotationType  `query:"type"`          // Optional.
 	api.LimitAndOffset
 	api.Sort
 }
 
+// AnnotationTypeQuery describes the URL query parameters for selecting the type of annotation.
+type AnnotationTypeQuery struct {
+	Type *services.AnnotationType `query:"type"` // Optional, defaults to box.
+}
+
+// isPointType reports whether the request is for point annotations, using the type query parameter.
+func isPointType(ctx *fiber.Ctx) (bool, error) {
+	qry := new(AnnotationTypeQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return false, api.InvalidRequestURL(err)
+	}
+	return qry.Type != nil && *qry.Type == services.PointAnnotationType, nil
+}
+
 // GetAnnotationByID gets an annotation when provided with an ID.
 //
 //	@Summary		Get annotation by ID
-//	@Description	Gets an annotation when provided with an ID.
+//	@Description	Gets an annotation when provided with an ID. Use type=point to get a point annotation.
 //	@Tags			Annotations
 //	@Produce		json
-//	@Param			id	path		int	true	"Annotation ID"	example(1234567890)
-//	@Success		200	{object}	services.AnnotationWithJoins
+//	@Param			id		path		int		true	"Annotation ID"	example(1234567890)
+//	@Param			type	query		string	false	"Type of annotation."	Enums(box, point)	default(box)
+//	@Success		200		{object}	services.AnnotationWithJoins
 //	@Failure		400	{object}	api.Failure
 //	@Failure		401	{object}	api.Failure
 //	@Failure		403	{object}	api.Failure
@@ -74,6 +106,13 @@ func GetAnnotationByID(ctx *fiber.Ctx) error {
 	if err != nil {
 		return api.InvalidRequestURL(err)
 	}
+	point, err := isPointType(ctx)
+	if err != nil {
+		return err
+	}
+	if point {
+		return getPointAnnotationByID(ctx, id)
+	}
 
 	// Fetch data from the datastore.
 	annotation, err := services.GetAnnotationByID(id)
@@ -86,23 +125,68 @@ func GetAnnotationByID(ctx *fiber.Ctx) error {
 		return api.DatastoreReadFailure(err)
 	}
 
+	setETag(ctx, annotation.Version)
 	return ctx.JSON(joined)
 }
 
-// GetAnnotations gets a list of annotations, filtering by videostream if specified.
+// setETag sets the ETag header of the response to the annotation's version.
+func setETag(ctx *fiber.Ctx, version int64) {
+	ctx.Set(fiber.HeaderETag, fmt.Sprintf("\"%d\"", version))
+}
+
+// parseIfMatch parses the If-Match header of the request into an annotation version.
+func parseIfMatch(ctx *fiber.Ctx) (int64, error) {
+	header := ctx.Get(fiber.HeaderIfMatch)
+	if header == "" {
+		return 0, api.PreconditionRequired(errors.New("If-Match header must be set to the ETag of the annotation"))
+	}
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(header, "W/"), "\""), 10, 64)
+	if err != nil {
+		return 0, api.InvalidRequestHeader(fiber.HeaderIfMatch, err)
+	}
+	return version, nil
+}
+
+// parseAttributeFilters parses the attribute filters of a request, from query parameters of the form attr.<name>=<value>.
+func parseAttributeFilters(ctx *fiber.Ctx) map[string]string {
+	attributes := make(map[string]string)
+	for key, value := range ctx.Queries() {
+		if name, ok := strings.CutPrefix(key, "attr."); ok {
+			attributes[name] = value
+		}
+	}
+	return attributes
+}
+
+// GetAnnotations gets a list of annotations, filtering by video stream, capture source, species, user and time if specified.
 //
 //	@Summary		Get annotations
-//	@Description	Get paginated annotations, with options to filter by video stream.
+//	@Description	Get paginated annotations, with options to filter by video stream, capture source, species, the user who identified it,
+//	@Description	the user who created it, the consensus of its identifications, its review status, the time within the video stream and the date and time it occurred.
+//	@Description	Annotations can also be filtered by their attributes, using query parameters of the form attr.<name>=<value>, e.g. attr.behaviour=feeding.
+//	@Description
+//	@Description	Use type=point to get point annotations instead, which can be filtered by video stream, species, the user who created it and the time within the video stream.
 //	@Tags			Annotations
 //	@Produce		json
-//	@Param			limit		query		int		false	"Number of results to return."	minimum(1)	default(20)
-//	@Param			offset		query		int		false	"Number of results to skip."	minimum(0)
-//	@Param			name		query		string	false	"Name to filter by."
-//	@Param			videostream	query		int		false	"Video stream to filter by."
-//	@Success		200			{object}	api.Result[services.AnnotationWithJoins]
-//	@Failure		400			{object}	api.Failure
-//	@Failure		401			{object}	api.Failure
-//	@Failure		403			{object}	api.Failure
+//	@Param			limit			query		int		false	"Number of results to return."	minimum(1)	default(20)
+//	@Param			offset			query		int		false	"Number of results to skip."	minimum(0)
+//	@Param			videostream		query		int		false	"Video stream to filter by."
+//	@Param			capturesource	query		int		false	"Capture source to filter by."
+//	@Param			species			query		int		false	"Identified species to filter by."
+//	@Param			identified_by	query		int		false	"User who made an identification to filter by."
+//	@Param			created_by		query		int		false	"User who created the annotation to filter by."
+//	@Param			consensus		query		string	false	"Consensus status to filter by. Use agreed for research grade annotations."	Enums(needs_id, disputed, agreed)
+//	@Param			status			query		string	false	"Review status to filter by."	Enums(draft, submitted, verified, rejected)
+//	@Param			timespan[start]	query		string	false	"Start of time span within the video stream to filter by. Requires videostream."	example(00:01:00.000)
+//	@Param			timespan[end]	query		string	false	"End of time span within the video stream to filter by. Requires videostream."	example(00:02:00.000)
+//	@Param			from			query		string	false	"Earliest date and time to filter by."	example(2023-05-25T08:00:00Z)
+//	@Param			to				query		string	false	"Latest date and time to filter by."	example(2023-05-25T16:30:00Z)
+//	@Param			attr.behaviour	query		string	false	"Attribute value to filter by, for any attribute in the schema."	example(feeding)
+//	@Param			type			query		string	false	"Type of annotation."	Enums(box, point)	default(box)
+//	@Success		200				{object}	api.Result[services.AnnotationWithJoins]
+//	@Failure		400				{object}	api.Failure
+//	@Failure		401				{object}	api.Failure
+//	@Failure		403				{object}	api.Failure
 //	@Router			/api/v1/annotations [get]
 func GetAnnotations(ctx *fiber.Ctx) error {
 	qry := new(GetAnnotationsQuery)
@@ -112,9 +196,40 @@ func GetAnnotations(ctx *fiber.Ctx) error {
 		return api.InvalidRequestURL(err)
 	}
 
+	// Validate time filters.
+	if qry.TimeSpan != nil {
+		if !qry.TimeSpan.Valid() {
+			return api.InvalidRequestURL(fmt.Errorf("invalid time span, start time must occur before end time"))
+		}
+		if qry.VideoStream == nil {
+			return api.InvalidRequestURL(fmt.Errorf("filtering by time span requires a video stream"))
+		}
+	}
+	if qry.From != nil && qry.To != nil && qry.To.Before(*qry.From) {
+		return api.InvalidRequestURL(fmt.Errorf("invalid date range, from must occur before to"))
+	}
+
+	if qry.Type != nil && *qry.Type == services.PointAnnotationType {
+		return getPointAnnotations(ctx, qry)
+	}
+
 	// Fetch data from the datastore.
-	annotations, err := services.GetAnnotations(qry.Limit, qry.Offset, qry.Order, qry.VideoStream)
-	if err != nil {
+	annotations, err := services.GetAnnotations(qry.Limit, qry.Offset, qry.Order, services.AnnotationFilter{
+		VideoStreamID:   qry.VideoStream,
+		CaptureSourceID: qry.CaptureSource,
+		SpeciesID:       qry.Species,
+		IdentifiedByID:  qry.IdentifiedBy,
+		CreatedByID:     qry.CreatedBy,
+		Consensus:       qry.Consensus,
+		Review:          qry.Status,
+		Attributes:      parseAttributeFilters(ctx),
+		TimeSpan:        qry.TimeSpan,
+		From:            qry.From,
+		To:              qry.To,
+	})
+	if errors.Is(err, services.ErrInvalidAttributes) {
+		return api.InvalidRequestURL(err)
+	} else if err != nil {
 		return api.DatastoreReadFailure(err)
 	}
 
@@ -136,11 +251,271 @@ func GetAnnotations(ctx *fiber.Ctx) error {
 	})
 }
 
+// GetBoxQuery describes the URL query parameters required for the GetAnnotationBox endpoint.
+type GetBoxQuery struct {
+	Time videotime.VideoTime `query:"time"`
+}
+
+// GetBoxesQuery describes the URL query parameters required for the GetAnnotationBoxes endpoint.
+type GetBoxesQuery struct {
+	FPS float64 `query:"fps"`
+}
+
+// GetAnnotationBox gets the bounding box of an annotation at a given time.
+//
+//	@Summary		Get annotation bounding box at time
+//	@Description	Gets the bounding box of an annotation at any time within the annotation, linearly interpolating between its keypoints.
+//	@Description	If the annotation has polygons, the polygon is interpolated vertex by vertex and returned with its bounding box.
+//	@Tags			Annotations
+//	@Produce		json
+//	@Param			id		path		int		true	"Annotation ID"	example(1234567890)
+//	@Param			time	query		string	true	"Time"			example(01:02:03.400)
+//	@Success		200		{object}	keypoint.KeyPoint
+//	@Failure		400		{object}	api.Failure
+//	@Failure		401		{object}	api.Failure
+//	@Failure		403		{object}	api.Failure
+//	@Failure		404		{object}	api.Failure
+//	@Router			/api/v1/annotations/{id}/box [get]
+func GetAnnotationBox(ctx *fiber.Ctx) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	qry := new(GetBoxQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	// Fetch data from the datastore.
+	annotation, err := services.GetAnnotationByID(id)
+	if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return api.NotFound(err)
+	} else if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	kp, err := keypoint.Interpolate(annotation.KeyPoints, qry.Time)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	return ctx.JSON(kp)
+}
+
+// GetAnnotationBoxes gets the bounding boxes of an annotation sampled at a given frame rate.
+//
+//	@Summary		Get annotation bounding boxes at frame rate
+//	@Description	Gets the bounding box of an annotation for every frame, at the given frame rate, from the start to the end of the annotation.
+//	@Description	Bounding boxes are linearly interpolated between the annotation's keypoints. At most 90000 bounding boxes are returned.
+//	@Tags			Annotations
+//	@Produce		json
+//	@Param			id	path	int		true	"Annotation ID"	example(1234567890)
+//	@Param			fps	query	number	true	"Frame rate"	example(25)
+//	@Success		200	{array}	keypoint.KeyPoint
+//	@Failure		400	{object}	api.Failure
+//	@Failure		401	{object}	api.Failure
+//	@Failure		403	{object}	api.Failure
+//	@Failure		404	{object}	api.Failure
+//	@Router			/api/v1/annotations/{id}/boxes [get]
+func GetAnnotationBoxes(ctx *fiber.Ctx) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	qry := new(GetBoxesQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	// Fetch data from the datastore.
+	annotation, err := services.GetAnnotationByID(id)
+	if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return api.NotFound(err)
+	} else if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	samples, err := keypoint.Sample(annotation.KeyPoints, qry.FPS)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	return ctx.JSON(samples)
+}
+
+// GetChipQuery describes the URL query parameters for the GetAnnotationChip endpoint.
+type GetChipQuery struct {
+	Time    videotime.VideoTime `query:"time"`
+	Size    int                 `query:"size"`    // Optional, defaults to 224.
+	Padding float64             `query:"padding"` // Optional, defaults to 0.
+}
+
+// GetAnnotationChip gets an image of an annotation cropped from a frame.
+//
+//	@Summary		Get annotation chip
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Gets a square JPEG image cropped from the frame at the given time to the annotation's bounding box, linearly interpolated between its keypoints, for training classifiers.
+//	@Description	Padding is a fraction of the bounding box's width and height added to each side. The crop is made square around the centre of the bounding box, then resized to size by size pixels.
+//	@Description	The frame must have been extracted to media storage. Chips are stored in media storage the first time they are requested.
+//	@Tags			Media
+//	@Produce		image/jpeg
+//	@Param			id		path	int		true	"Annotation ID"	example(1234567890)
+//	@Param			time	query	string	true	"Time"			example(01:02:03.400)
+//	@Param			size	query	int		false	"Width and height in pixels."	minimum(16)	maximum(1024)	default(224)
+//	@Param			padding	query	number	false	"Padding as a fraction of the bounding box."	minimum(0)	maximum(1)	default(0)
+//	@Success		200
+//	@Failure		400	{object}	api.Failure
+//	@Failure		401	{object}	api.Failure
+//	@Failure		403	{object}	api.Failure
+//	@Failure		404	{object}	api.Failure
+//	@Router			/api/v1/annotations/{id}/chip [get]
+func GetAnnotationChip(ctx *fiber.Ctx) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	qry := new(GetChipQuery)
+	qry.Size = 224
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	// Get or create the chip.
+	data, err := services.GetChip(id, qry.Time, qry.Size, qry.Padding)
+	if errors.Is(err, services.ErrInvalidChip) {
+		return api.InvalidRequestURL(err)
+	} else if errors.Is(err, services.ErrFrameNotFound) || errors.Is(err, datastore.ErrNoSuchEntity) {
+		return api.NotFound(err)
+	} else if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	ctx.Type(mediatype.JPEG.FileExtension())
+	return ctx.Send(data)
+}
+
+// ImportAnnotationsForm describes the multipart form fields required for the ImportAnnotations endpoint, alongside the file.
+type ImportAnnotationsForm struct {
+	Format      string  `form:"format"`
+	VideoStream int64   `form:"videostream"`
+	FPS         float64 `form:"fps"`
+	Labels      string  `form:"labels"` // Optional, JSON object of label names to species IDs.
+}
+
+// ImportAnnotations imports annotations from a CVAT video XML or COCO JSON file.
+//
+//	@Summary		Import annotations
+//	@Description	Roles required: <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Imports annotations from a CVAT for video 1.1 XML file or a COCO JSON file into a video stream. Frame numbers are converted to video times using the frame rate.
+//	@Description	Labels are resolved to species by their scientific or common name, or using the labels field, a JSON object of label names to species IDs.
+//	@Description	Returns a report of which tracks were imported, skipped or could not be resolved to a species.
+//	@Tags			Annotations
+//	@Accept			mpfd
+//	@Produce		json
+//	@Param			file		formData	file	true	"CVAT XML or COCO JSON file"
+//	@Param			format		formData	string	true	"Format of the file"	Enums(cvat, coco)
+//	@Param			videostream	formData	int		true	"Video stream to import into"
+//	@Param			fps			formData	number	true	"Frame rate of the video"
+//	@Param			labels		formData	string	false	"Label names to species IDs"	example({"squid": 1234567890})
+//	@Success		200			{object}	services.ImportReport
+//	@Failure		400			{object}	api.Failure
+//	@Failure		401			{object}	api.Failure
+//	@Failure		403			{object}	api.Failure
+//	@Router			/api/v1/annotations/import [post]
+func ImportAnnotations(ctx *fiber.Ctx) error {
+	// Parse form.
+	var form ImportAnnotationsForm
+	if err := ctx.BodyParser(&form); err != nil {
+		return api.InvalidRequestJSON(err)
+	}
+	var labels map[string]int64
+	if form.Labels != "" {
+		if err := json.Unmarshal([]byte(form.Labels), &labels); err != nil {
+			return api.InvalidRequestJSON(err)
+		}
+	}
+	fh, err := ctx.FormFile("file")
+	if err != nil {
+		return api.InvalidRequestJSON(err)
+	}
+	file, err := fh.Open()
+	if err != nil {
+		return api.InvalidRequestJSON(err)
+	}
+	defer file.Close()
+
+	// Get logged in user.
+	user, ok := ctx.Locals("user").(*services.User)
+	if !ok {
+		return fmt.Errorf("failed to assert type: expected *services.User but got %T", ctx.Locals("user"))
+	}
+	if user == nil {
+		return api.Unauthorized(fmt.Errorf("user not logged in"))
+	}
+
+	opts := services.ImportOptions{
+		VideoStreamID: form.VideoStream,
+		FPS:           form.FPS,
+		CreatedByID:   user.ID,
+		Labels:        labels,
+	}
+
+	// Import annotations.
+	var report *services.ImportReport
+	switch form.Format {
+	case "cvat":
+		report, err = services.ImportCVAT(file, opts)
+	case "coco":
+		report, err = services.ImportCOCO(file, opts)
+	default:
+		return api.InvalidRequestJSON(fmt.Errorf("invalid format %q, must be cvat or coco", form.Format))
+	}
+	if errors.Is(err, services.ErrInvalidImport) {
+		return api.InvalidRequestJSON(err)
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	return ctx.JSON(report)
+}
+
 // NewAnnotationBody describes the JSON body required for the CreateAnnotation endpoint.
+// Box annotations require keypoints, point annotations require a time, points and an identification.
 type NewAnnotationBody struct {
-	KeyPoints      []keypoint.KeyPoint `json:"keypoints"`
-	Identification *int64              `json:"identification" example:"1234567890" validate:"optional"`
-	VideostreamID  int64               `json:"videostream_id" example:"1234567890"`
+	Type           services.AnnotationType `json:"type" enums:"box,point" example:"box" validate:"optional"`
+	KeyPoints      []keypoint.KeyPoint     `json:"keypoints" validate:"optional"`
+	Time           *videotime.VideoTime    `json:"time" swaggertype:"string" example:"00:01:05.500" validate:"optional"`
+	Points         []keypoint.Point        `json:"points" validate:"optional"`
+	Identification *int64                  `json:"identification" example:"1234567890" validate:"optional"`
+	VideostreamID  int64                   `json:"videostream_id" example:"1234567890"`
+	Attributes     map[string]string       `json:"attributes" example:"behaviour:feeding" validate:"optional"`
+}
+
+// toContents converts the body of a new box annotation to annotation contents, created and identified by the given user.
+func (body *NewAnnotationBody) toContents(userID int64) services.AnnotationContents {
+	// Check if we have any identifications.
+	var ids map[int64][]int64
+	if body.Identification != nil {
+		ids = map[int64][]int64{
+			*body.Identification: {userID},
+		}
+	}
+
+	return services.AnnotationContents{
+		KeyPoints:       body.KeyPoints,
+		VideostreamID:   body.VideostreamID,
+		CreatedByID:     userID,
+		Identifications: ids,
+		Attributes:      body.Attributes,
+	}
 }
 
 // CreateAnnotation creates a new annotation.
@@ -149,6 +524,12 @@ type NewAnnotationBody struct {
 //	@Description	Roles required: <role-tag>Annotator</role-tag>, <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
 //	@Description
 //	@Description	Creates a new annotation from provided JSON body.
+//	@Description
+//	@Description	Keypoints can have a polygon outlining the shape of the object instead of only a bounding box, in which case the bounding box is derived from the polygon.
+//	@Description	Polygons must be within the frame, must not intersect themselves, and all keypoints of an annotation must have polygons with the same number of vertices.
+//	@Description
+//	@Description	Set type to point to create a point annotation instead, which counts individuals of the identified species at a single time with a point marking each one.
+//	@Description	Point annotations are used for dense groups such as schools of fish, where boxing every individual is impractical.
 //	@Tags			Annotations
 //	@Accept			json
 //	@Produce		json
@@ -184,35 +565,110 @@ func CreateAnnotation(ctx *fiber.Ctx) error {
 		return api.Forbidden(fmt.Errorf("logged in user is not within annotator list for this videostream (%d)", body.VideostreamID))
 	}
 
-	// Check if we have any identifications.
-	var ids map[int64][]int64
-	if body.Identification != nil {
-		ids = map[int64][]int64{
-			*body.Identification: {annotator.ID},
-		}
-	} else {
-		ids = nil
+	if body.Type == services.PointAnnotationType {
+		return createPointAnnotation(ctx, body, annotator)
 	}
 
 	// Write data to the datastore.
-	annotation := services.AnnotationContents{
-		KeyPoints:       body.KeyPoints,
-		VideostreamID:   body.VideostreamID,
-		CreatedByID:     annotator.ID,
-		Identifications: ids,
+	created, err := services.CreateAnnotation(body.toContents(annotator.ID))
+	if errors.Is(err, services.ErrInvalidKeyPoints) || errors.Is(err, services.ErrInvalidAttributes) {
+		return api.InvalidRequestJSON(err)
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
 	}
 
-	created, err := services.CreateAnnotation(annotation)
+	// Return joined form.
+	joined, err := created.JoinFields()
 	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	return ctx.JSON(joined)
+}
+
+// UpdateAnnotation updates the keypoints of an annotation.
+//
+//	@Summary		Update annotation
+//	@Description	Roles required: <role-tag>Annotator</role-tag>, <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Partially update an annotation's keypoints and attributes. Identifications are preserved. Annotators can only update
+//	@Description	their own annotations. The If-Match header must be set to the ETag returned when fetching the annotation,
+//	@Description	so that changes made by someone else in the meantime are not overwritten.
+//	@Tags			Annotations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int									true	"Annotation ID"	example(1234567890)
+//	@Param			If-Match	header		string								true	"Annotation ETag"	example("1")
+//	@Param			body		body		services.PartialAnnotationContents	true	"Update Annotation"
+//	@Success		200			{object}	services.AnnotationWithJoins
+//	@Failure		400			{object}	api.Failure
+//	@Failure		401			{object}	api.Failure
+//	@Failure		403			{object}	api.Failure
+//	@Failure		404			{object}	api.Failure
+//	@Failure		412			{object}	api.Failure
+//	@Failure		428			{object}	api.Failure
+//	@Router			/api/v1/annotations/{id} [patch]
+func UpdateAnnotation(ctx *fiber.Ctx) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	version, err := parseIfMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Parse body.
+	var body services.PartialAnnotationContents
+	err = ctx.BodyParser(&body)
+	if err != nil {
+		return api.InvalidRequestJSON(err)
+	}
+
+	// Get logged in user.
+	user, ok := ctx.Locals("user").(*services.User)
+	if !ok {
+		return fmt.Errorf("failed to assert type: expected *services.User but got %T", ctx.Locals("user"))
+	}
+	if user == nil {
+		return api.Unauthorized(fmt.Errorf("user not logged in"))
+	}
+
+	// Check user is allowed to edit this annotation.
+	annotation, err := services.GetAnnotationByID(id)
+	if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return api.NotFound(err)
+	} else if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+	if user.Role < role.Curator && annotation.CreatedByID != user.ID {
+		return api.Forbidden(fmt.Errorf("annotators can only update their own annotations"))
+	}
+
+	// Write data to the datastore.
+	err = services.UpdateAnnotation(id, version, user.ID, body)
+	if errors.Is(err, services.ErrVersionMismatch) {
+		return api.PreconditionFailed(err)
+	} else if errors.Is(err, services.ErrInvalidKeyPoints) || errors.Is(err, services.ErrInvalidAttributes) {
+		return api.InvalidRequestJSON(err)
+	} else if err != nil {
 		return api.DatastoreWriteFailure(err)
 	}
 
-	// Return joined form.
-	joined, err := created.JoinFields()
+	// Get updated annotation.
+	modified, err := services.GetAnnotationByID(id)
 	if err != nil {
 		return api.DatastoreReadFailure(err)
 	}
 
+	joined, err := modified.JoinFields()
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	setETag(ctx, modified.Version)
 	return ctx.JSON(joined)
 }
 
@@ -335,9 +791,10 @@ func DeleteIdentification(ctx *fiber.Ctx) error {
 //	@Summary		Delete annotation
 //	@Description	Roles required: <role-tag>Admin</role-tag>
 //	@Description
-//	@Description	Delete an annotation by providing the annotation ID.
+//	@Description	Delete an annotation by providing the annotation ID. Use type=point to delete a point annotation. Deleted annotations are moved to the trash, where they can be restored until they are purged.
 //	@Tags			Annotations
-//	@Param			id	path	int	true	"Annotation ID"	example(1234567890)
+//	@Param			id		path	int		true	"Annotation ID"	example(1234567890)
+//	@Param			type	query	string	false	"Type of annotation."	Enums(box, point)	default(box)
 //	@Success		200
 //	@Failure		400	{object}	api.Failure
 //	@Failure		401	{object}	api.Failure
@@ -351,11 +808,141 @@ func DeleteAnnotation(ctx *fiber.Ctx) error {
 		return api.InvalidRequestURL(err)
 	}
 
+	// Get logged in user.
+	user, ok := ctx.Locals("user").(*services.User)
+	if !ok {
+		return fmt.Errorf("failed to assert type: expected *services.User but got %T", ctx.Locals("user"))
+	}
+	if user == nil {
+		return api.Unauthorized(fmt.Errorf("user not logged in"))
+	}
+
 	// Delete entity.
-	err = services.DeleteAnnotation(id)
+	point, err := isPointType(ctx)
+	if err != nil {
+		return err
+	}
+	if point {
+		err = services.DeletePointAnnotation(id, user.ID)
+	} else {
+		err = services.DeleteAnnotation(id, user.ID)
+	}
 	if err != nil {
 		return api.DatastoreWriteFailure(err)
 	}
 
 	return nil
 }
+
+// GetAnnotationHistory gets the revision history of an annotation.
+//
+//	@Summary		Get annotation history
+//	@Description	Gets every change made to an annotation, oldest first. Each revision has the kind of change, who made it
+//	@Description	and when, as well as the keypoints and identifications of the annotation after the change was made.
+//	@Tags			Annotations
+//	@Produce		json
+//	@Param			id	path		int	true	"Annotation ID"	example(1234567890)
+//	@Success		200	{object}	api.Result[services.AnnotationRevisionWithJoins]
+//	@Failure		400	{object}	api.Failure
+//	@Failure		401	{object}	api.Failure
+//	@Failure		403	{object}	api.Failure
+//	@Failure		404	{object}	api.Failure
+//	@Router			/api/v1/annotations/{id}/history [get]
+func GetAnnotationHistory(ctx *fiber.Ctx) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	// Fetch data from the datastore.
+	revisions, err := services.GetAnnotationHistory(id)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+	if len(revisions) == 0 {
+		return api.NotFound(fmt.Errorf("no history for annotation %d", id))
+	}
+
+	// Apply Joins.
+	joined := make([]services.AnnotationRevisionWithJoins, len(revisions))
+	for i, revision := range revisions {
+		j, err := revision.JoinFields()
+		if err != nil {
+			return api.DatastoreReadFailure(err)
+		}
+		joined[i] = *j
+	}
+
+	return ctx.JSON(api.Result[services.AnnotationRevisionWithJoins]{
+		Results: joined,
+		Offset:  0,
+		Limit:   len(joined),
+		Total:   len(joined),
+	})
+}
+
+// RestoreAnnotation restores an annotation to an earlier revision.
+//
+//	@Summary		Restore annotation revision
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Restores the keypoints and identifications of an annotation to how they were at an earlier revision.
+//	@Description	Deleted annotations can also be restored this way, unless their video stream has been deleted. The restore is recorded as a new revision.
+//	@Description	The annotation keeps its current review status, and a verified annotation goes back for review if its keypoints or consensus species change.
+//	@Tags			Annotations
+//	@Produce		json
+//	@Param			id		path		int	true	"Annotation ID"	example(1234567890)
+//	@Param			version	path		int	true	"Version"		example(2)
+//	@Success		200		{object}	services.AnnotationWithJoins
+//	@Failure		400		{object}	api.Failure
+//	@Failure		401		{object}	api.Failure
+//	@Failure		403		{object}	api.Failure
+//	@Failure		404		{object}	api.Failure
+//	@Failure		409		{object}	api.Failure
+//	@Router			/api/v1/annotations/{id}/history/{version}/restore [post]
+func RestoreAnnotation(ctx *fiber.Ctx) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	version, err := strconv.ParseInt(ctx.Params("version"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	// Get logged in user.
+	user, ok := ctx.Locals("user").(*services.User)
+	if !ok {
+		return fmt.Errorf("failed to assert type: expected *services.User but got %T", ctx.Locals("user"))
+	}
+	if user == nil {
+		return api.Unauthorized(fmt.Errorf("user not logged in"))
+	}
+
+	// Write data to the datastore.
+	err = services.RestoreAnnotation(id, version, user.ID)
+	if errors.Is(err, services.ErrRevisionNotFound) {
+		return api.NotFound(err)
+	} else if errors.Is(err, services.ErrRestoreConflict) {
+		return api.Conflict(err)
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	// Get restored annotation.
+	restored, err := services.GetAnnotationByID(id)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	joined, err := restored.JoinFields()
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	setETag(ctx, restored.Version)
+	return ctx.JSON(joined)
+}
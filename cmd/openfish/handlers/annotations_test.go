@@ -0,0 +1,67 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package handlers_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/api"
+	"github.com/ausocean/openfish/cmd/openfish/handlers"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestGetBoxesAndChipOfMissingAnnotation verifies that getting the bounding boxes or chip of an annotation that does not exist is not found.
+func TestGetBoxesAndChipOfMissingAnnotation(t *testing.T) {
+	setup(t)
+
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	app.Get("/api/v1/annotations/:id/box", handlers.GetAnnotationBox)
+	app.Get("/api/v1/annotations/:id/boxes", handlers.GetAnnotationBoxes)
+	app.Get("/api/v1/annotations/:id/chip", handlers.GetAnnotationChip)
+
+	for _, url := range []string{
+		"/api/v1/annotations/1234567890/box?time=00:00:01.000",
+		"/api/v1/annotations/1234567890/boxes?fps=25",
+		"/api/v1/annotations/1234567890/chip?time=00:00:01.000",
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", url, nil))
+		if err != nil {
+			t.Fatalf("Could not get %s: %s", url, err)
+		}
+		if resp.StatusCode != fiber.StatusNotFound {
+			t.Errorf("Expected %s to be %d, got %d", url, fiber.StatusNotFound, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// handlers package handles HTTP requests.
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ausocean/openfish/cmd/openfish/api"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetAttributes gets the attribute schema.
+//
+//	@Summary		Get attributes
+//	@Description	Gets all the attributes that can be recorded on annotations, such as behaviour or life stage.
+//	@Tags			Attributes
+//	@Produce		json
+//	@Success		200	{array}		services.Attribute
+//	@Failure		401	{object}	api.Failure
+//	@Failure		403	{object}	api.Failure
+//	@Router			/api/v1/attributes [get]
+func GetAttributes(ctx *fiber.Ctx) error {
+	// Fetch data from the datastore.
+	attributes, err := services.GetAttributes()
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	return ctx.JSON(attributes)
+}
+
+// CreateAttribute adds an attribute to the schema.
+//
+//	@Summary		Create attribute
+//	@Description	Roles required: <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Adds an attribute to the schema, so it can be recorded on annotations. Names must be lowercase letters, numbers and underscores.
+//	@Description	Enum attributes must have a list of options, and number attributes can have a minimum and maximum.
+//	@Tags			Attributes
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		services.Attribute	true	"New Attribute"
+//	@Success		201		{object}	services.Attribute
+//	@Failure		400		{object}	api.Failure
+//	@Failure		401		{object}	api.Failure
+//	@Failure		403		{object}	api.Failure
+//	@Failure		409		{object}	api.Failure
+//	@Router			/api/v1/attributes [post]
+func CreateAttribute(ctx *fiber.Ctx) error {
+	// Parse body.
+	var body services.Attribute
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return api.InvalidRequestJSON(err)
+	}
+
+	// Create attribute entity and add to the datastore.
+	created, err := services.CreateAttribute(body)
+	if errors.Is(err, services.ErrInvalidAttributes) {
+		return api.InvalidRequestJSON(err)
+	} else if errors.Is(err, services.ErrAttributeExists) {
+		return api.Conflict(err)
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(created)
+}
+
+// UpdateAttribute updates an attribute.
+//
+//	@Summary		Update attribute
+//	@Description	Roles required: <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Partially update an attribute by specifying the properties to update. The type of an attribute cannot be changed.
+//	@Description	The minimum and maximum of number attributes are removed by setting them to null.
+//	@Description	Annotations that already have a value for the attribute are not revalidated.
+//	@Tags			Attributes
+//	@Accept			json
+//	@Param			name	path	string								true	"Attribute name"	example(behaviour)
+//	@Param			body	body	services.PartialAttributeContents	true	"Update Attribute"
+//	@Success		200
+//	@Failure		400	{object}	api.Failure
+//	@Failure		401	{object}	api.Failure
+//	@Failure		403	{object}	api.Failure
+//	@Failure		404	{object}	api.Failure
+//	@Router			/api/v1/attributes/{name} [patch]
+func UpdateAttribute(ctx *fiber.Ctx) error {
+	// Parse body.
+	var body services.PartialAttributeContents
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return api.InvalidRequestJSON(err)
+	}
+
+	// Check attribute exists.
+	name := ctx.Params("name")
+	if !services.AttributeExists(name) {
+		return api.NotFound(fmt.Errorf("attribute %s does not exist", name))
+	}
+
+	// Update data in the datastore.
+	err = services.UpdateAttribute(name, body)
+	if errors.Is(err, services.ErrInvalidAttributes) {
+		return api.InvalidRequestJSON(err)
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	return nil
+}
+
+// DeleteAttribute removes an attribute from the schema.
+//
+//	@Summary		Delete attribute
+//	@Description	Roles required: <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Removes an attribute from the schema. Annotations keep their existing values for the attribute.
+//	@Tags			Attributes
+//	@Param			name	path	string	true	"Attribute name"	example(behaviour)
+//	@Success		200
+//	@Failure		400	{object}	api.Failure
+//	@Failure		401	{object}	api.Failure
+//	@Failure		403	{object}	api.Failure
+//	@Failure		404	{object}	api.Failure
+//	@Router			/api/v1/attributes/{name} [delete]
+func DeleteAttribute(ctx *fiber.Ctx) error {
+	// Check attribute exists.
+	name := ctx.Params("name")
+	if !services.AttributeExists(name) {
+		return api.NotFound(fmt.Errorf("attribute %s does not exist", name))
+	}
+
+	// Delete entity.
+	err := services.DeleteAttribute(name)
+	if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// handlers package handles HTTP requests.
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ausocean/openfish/cmd/openfish/api"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/timespan"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeleteAnnotationsQuery describes the URL query parameters for the DeleteAnnotations endpoint.
+type DeleteAnnotationsQuery struct {
+	VideoStream   *int64                    `query:"videostream"`   // Optional.
+	CaptureSource *int64                    `query:"capturesource"` // Optional.
+	Species       *int64                    `query:"species"`       // Optional.
+	IdentifiedBy  *int64                    `query:"identified_by"` // Optional.
+	CreatedBy     *int64                    `query:"created_by"`    // Optional.
+	Consensus     *services.ConsensusStatus `query:"consensus"`     // Optional.
+	Status        *services.ReviewStatus    `query:"status"`        // Optional.
+	TimeSpan      *timespan.TimeSpan        `query:"timespan"`      // Optional.
+	From          *time.Time                `query:"from"`          // Optional.
+	To            *time.Time                `query:"to"`            // Optional.
+}
+
+// DeleteAnnotationsResult is the response of the DeleteAnnotations endpoint.
+type DeleteAnnotationsResult struct {
+	Deleted int `json:"deleted" example:"120"`
+}
+
+// CreateAnnotations creates many annotations at once.
+//
+//	@Summary		Create annotations in bulk
+//	@Description	Roles required: <role-tag>Annotator</role-tag>, <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Creates up to 500 box annotations from an array of new annotations, for use by importers and machine learning pipelines.
+//	@Description	Every annotation is validated before any are written: its video stream must exist and its annotator list must include the logged in user,
+//	@Description	its identified species must exist, and its keypoints and attributes must be valid. Invalid annotations are skipped and the rest are created.
+//	@Description	Returns the result of each annotation, in the same order as the request.
+//	@Tags			Annotations
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		[]NewAnnotationBody	true	"New Annotations"
+//	@Success		200		{object}	services.BulkCreateReport
+//	@Failure		400		{object}	api.Failure
+//	@Failure		401		{object}	api.Failure
+//	@Failure		403		{object}	api.Failure
+//	@Router			/api/v1/annotations/bulk [post]
+func CreateAnnotations(ctx *fiber.Ctx) error {
+	// Parse body.
+	var body []NewAnnotationBody
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return api.InvalidRequestJSON(err)
+	}
+
+	// Get logged in user.
+	annotator, ok := ctx.Locals("user").(*services.User)
+	if !ok {
+		return fmt.Errorf("failed to assert type: expected *services.User but got %T", ctx.Locals("user"))
+	}
+	if annotator == nil {
+		return api.Unauthorized(fmt.Errorf("user not logged in"))
+	}
+
+	contents := make([]services.AnnotationContents, len(body))
+	for i := range body {
+		if body[i].Type == services.PointAnnotationType {
+			return api.InvalidRequestJSON(fmt.Errorf("annotation %d: bulk create only supports box annotations", i))
+		}
+		contents[i] = body[i].toContents(annotator.ID)
+	}
+
+	// Write data to the datastore.
+	report, err := services.CreateAnnotations(contents)
+	if errors.Is(err, services.ErrInvalidBulk) {
+		return api.InvalidRequestJSON(err)
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	return ctx.JSON(report)
+}
+
+// DeleteAnnotations deletes every annotation matching the filters.
+//
+//	@Summary		Delete annotations in bulk
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Deletes every annotation matching the filters, which are the same as for getting annotations. At least one filter is required.
+//	@Description	The history of each annotation is kept, so they can be restored later.
+//	@Tags			Annotations
+//	@Produce		json
+//	@Param			videostream		query		int		false	"Video stream to filter by."
+//	@Param			capturesource	query		int		false	"Capture source to filter by."
+//	@Param			species			query		int		false	"Identified species to filter by."
+//	@Param			identified_by	query		int		false	"User who made an identification to filter by."
+//	@Param			created_by		query		int		false	"User who created the annotation to filter by."
+//	@Param			consensus		query		string	false	"Consensus status to filter by."	Enums(needs_id, disputed, agreed)
+//	@Param			status			query		string	false	"Review status to filter by."	Enums(draft, submitted, verified, rejected)
+//	@Param			timespan[start]	query		string	false	"Start of time span within the video stream to filter by. Requires videostream."	example(00:01:00.000)
+//	@Param			timespan[end]	query		string	false	"End of time span within the video stream to filter by. Requires videostream."	example(00:02:00.000)
+//	@Param			from			query		string	false	"Earliest date and time to filter by."	example(2023-05-25T08:00:00Z)
+//	@Param			to				query		string	false	"Latest date and time to filter by."	example(2023-05-25T16:30:00Z)
+//	@Param			attr.behaviour	query		string	false	"Attribute value to filter by, for any attribute in the schema."	example(feeding)
+//	@Success		200				{object}	DeleteAnnotationsResult
+//	@Failure		400				{object}	api.Failure
+//	@Failure		401				{object}	api.Failure
+//	@Failure		403				{object}	api.Failure
+//	@Router			/api/v1/annotations [delete]
+func DeleteAnnotations(ctx *fiber.Ctx) error {
+	qry := new(DeleteAnnotationsQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	// Validate time filters.
+	if qry.TimeSpan != nil {
+		if !qry.TimeSpan.Valid() {
+			return api.InvalidRequestURL(fmt.Errorf("invalid time span, start time must occur before end time"))
+		}
+		if qry.VideoStream == nil {
+			return api.InvalidRequestURL(fmt.Errorf("filtering by time span requires a video stream"))
+		}
+	}
+	if qry.From != nil && qry.To != nil && qry.To.Before(*qry.From) {
+		return api.InvalidRequestURL(fmt.Errorf("invalid date range, from must occur before to"))
+	}
+
+	// Get logged in user.
+	user, ok := ctx.Locals("user").(*services.User)
+	if !ok {
+		return fmt.Errorf("failed to assert type: expected *services.User but got %T", ctx.Locals("user"))
+	}
+	if user == nil {
+		return api.Unauthorized(fmt.Errorf("user not logged in"))
+	}
+
+	// Delete entities.
+	deleted, err := services.DeleteAnnotations(services.AnnotationFilter{
+		VideoStreamID:   qry.VideoStream,
+		CaptureSourceID: qry.CaptureSource,
+		SpeciesID:       qry.Species,
+		IdentifiedByID:  qry.IdentifiedBy,
+		CreatedByID:     qry.CreatedBy,
+		Consensus:       qry.Consensus,
+		Review:          qry.Status,
+		Attributes:      parseAttributeFilters(ctx),
+		TimeSpan:        qry.TimeSpan,
+		From:            qry.From,
+		To:              qry.To,
+	}, user.ID)
+	if errors.Is(err, services.ErrInvalidBulk) || errors.Is(err, services.ErrInvalidAttributes) {
+		return api.InvalidRequestURL(err)
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	return ctx.JSON(DeleteAnnotationsResult{Deleted: deleted})
+}
@@ -35,7 +35,10 @@ LICENSE
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/ausocean/openfish/cmd/openfish/api"
 	"github.com/ausocean/openfish/cmd/openfish/services"
@@ -59,6 +62,13 @@ type GetCaptureSourcesQuery struct {
 	// TODO: Code could be simplified if api.Format could be embedded here. Testing shows it cannot, reason unknown.
 }
 
+// GetMaxNQuery describes the URL query parameters for the GetVideoStreamMaxN and GetCaptureSourceMaxN endpoints.
+type GetMaxNQuery struct {
+	From      *time.Time                `query:"from"`      // Optional.
+	To        *time.Time                `query:"to"`        // Optional.
+	Consensus *services.ConsensusStatus `query:"consensus"` // Optional.
+}
+
 // GetCaptureSourceByID gets a capture source when provided with an ID.
 //
 //	@Summary		Get capture source by ID
@@ -88,6 +98,51 @@ func GetCaptureSourceByID(ctx *fiber.Ctx) error {
 	return ctx.JSON(src)
 }
 
+// GetCaptureSourceMaxN gets the MaxN of each species across the video streams of a capture source.
+//
+//	@Summary		Get capture source MaxN
+//	@Description	Gets the MaxN of each species, the maximum number of individuals annotated at the same time, across the video streams produced by a capture source within a date range.
+//	@Description	MaxN is the largest MaxN of any one video stream, and the response includes the video stream and time at which it first occurred.
+//	@Tags			Capture Sources
+//	@Produce		json
+//	@Param			id			path		int		true	"Capture Source ID"	example(1234567890)
+//	@Param			from		query		string	false	"Earliest date and time to include."	example(2023-05-25T08:00:00Z)
+//	@Param			to			query		string	false	"Latest date and time to include."	example(2023-05-25T16:30:00Z)
+//	@Param			consensus	query		string	false	"Only count annotations with this consensus status."	Enums(needs_id, disputed, agreed)
+//	@Success		200			{array}		services.MaxN
+//	@Failure		400			{object}	api.Failure
+//	@Failure		401			{object}	api.Failure
+//	@Failure		403			{object}	api.Failure
+//	@Failure		404			{object}	api.Failure
+//	@Router			/api/v1/capturesources/{id}/maxn [get]
+func GetCaptureSourceMaxN(ctx *fiber.Ctx) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	qry := new(GetMaxNQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+	if qry.From != nil && qry.To != nil && qry.To.Before(*qry.From) {
+		return api.InvalidRequestURL(fmt.Errorf("invalid date range, from must occur before to"))
+	}
+
+	if !services.CaptureSourceExists(id) {
+		return api.NotFound(fmt.Errorf("capture source %d does not exist", id))
+	}
+
+	// Fetch data from the datastore.
+	maxN, err := services.GetMaxNByCaptureSource(id, qry.From, qry.To, qry.Consensus)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	return ctx.JSON(maxN)
+}
+
 // GetCaptureSources gets a list of capture sources, filtering by name, location if specified.
 //
 //	@Summary		Get capture sources
@@ -203,14 +258,18 @@ func UpdateCaptureSource(ctx *fiber.Ctx) error {
 //	@Summary		Delete capture source
 //	@Description	Roles required: <role-tag>Admin</role-tag>
 //	@Description
-//	@Description	Delete a capture source by providing the capture source ID.
+//	@Description	Delete a capture source by providing the capture source ID. Deleted items are moved to the trash, where they can be restored until they are purged.
+//	@Description	A capture source with video streams cannot be deleted unless cascade is set, the response lists how many video streams it has.
+//	@Description	A cascade is all or nothing, if any part of it fails nothing is deleted.
 //	@Tags			Capture Sources
-//	@Param			id	path	int	true	"Capture Source ID"	example(1234567890)
+//	@Param			id		path	int		true	"Capture Source ID"	example(1234567890)
+//	@Param			cascade	query	bool	false	"Also delete the video streams of the capture source and their annotations."
 //	@Success		200
 //	@Failure		400	{object}	api.Failure
 //	@Failure		401	{object}	api.Failure
 //	@Failure		403	{object}	api.Failure
 //	@Failure		404	{object}	api.Failure
+//	@Failure		409	{object}	api.Failure
 //	@Router			/api/v1/capturesources/{id} [delete]
 func DeleteCaptureSource(ctx *fiber.Ctx) error {
 
@@ -220,9 +279,26 @@ func DeleteCaptureSource(ctx *fiber.Ctx) error {
 		return api.InvalidRequestURL(err)
 	}
 
+	// Get logged in user.
+	user, ok := ctx.Locals("user").(*services.User)
+	if !ok {
+		return fmt.Errorf("failed to assert type: expected *services.User but got %T", ctx.Locals("user"))
+	}
+	if user == nil {
+		return api.Unauthorized(fmt.Errorf("user not logged in"))
+	}
+
+	// Parse query params.
+	qry := new(DeleteQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
 	// Delete capture source.
-	err = services.DeleteCaptureSource(id)
-	if err != nil {
+	err = services.DeleteCaptureSource(id, user.ID, qry.Cascade)
+	if errors.Is(err, services.ErrHasDependants) {
+		return api.Conflict(err)
+	} else if err != nil {
 		return api.DatastoreWriteFailure(err)
 	}
 
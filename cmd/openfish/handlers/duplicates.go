@@ -0,0 +1,171 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// handlers package handles HTTP requests.
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ausocean/cloud/datastore"
+	"github.com/ausocean/openfish/cmd/openfish/api"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetDuplicatesQuery describes the URL query parameters for the GetVideoStreamDuplicates endpoint.
+type GetDuplicatesQuery struct {
+	Threshold *float32 `query:"threshold"` // Optional.
+}
+
+// MergeAnnotationBody describes the JSON body required for the MergeAnnotation endpoint.
+type MergeAnnotationBody struct {
+	Annotation int64 `json:"annotation" example:"1234567891"` // Annotation to merge and delete.
+}
+
+// GetVideoStreamDuplicates finds annotations on a video stream that are likely to be duplicates.
+//
+//	@Summary		Get duplicate annotations
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Finds pairs of annotations on a video stream that overlap in time and whose bounding boxes overlap by at least the threshold,
+//	@Description	measured as the mean intersection over union (IoU) of their interpolated bounding boxes while both are present.
+//	@Description	Duplicates can be combined using the merge annotation endpoint.
+//	@Tags			Video Streams
+//	@Produce		json
+//	@Param			id			path		int		true	"Video Stream ID"	example(1234567890)
+//	@Param			threshold	query		number	false	"Minimum IoU of duplicates."	minimum(0)	maximum(1)	default(0.5)
+//	@Success		200			{array}		services.Duplicate
+//	@Failure		400			{object}	api.Failure
+//	@Failure		401			{object}	api.Failure
+//	@Failure		403			{object}	api.Failure
+//	@Failure		404			{object}	api.Failure
+//	@Router			/api/v1/videostreams/{id}/duplicates [get]
+func GetVideoStreamDuplicates(ctx *fiber.Ctx) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+	qry := new(GetDuplicatesQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+	threshold := float32(services.DefaultDuplicateThreshold)
+	if qry.Threshold != nil {
+		threshold = *qry.Threshold
+	}
+	if !(threshold > 0 && threshold <= 1) {
+		return api.InvalidRequestURL(fmt.Errorf("invalid threshold %g, must be greater than 0 and at most 1", threshold))
+	}
+
+	if !services.VideoStreamExists(id) {
+		return api.NotFound(fmt.Errorf("video stream %d does not exist", id))
+	}
+
+	// Fetch data from the datastore.
+	duplicates, err := services.FindDuplicates(id, threshold)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	return ctx.JSON(duplicates)
+}
+
+// MergeAnnotation merges a duplicate annotation into this annotation.
+//
+//	@Summary		Merge annotation
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Merges a duplicate annotation into this annotation, combining their identifications, then deletes the duplicate.
+//	@Description	This annotation's keypoints are kept, and attributes it does not have are copied from the duplicate.
+//	@Tags			Annotations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int					true	"Annotation ID"	example(1234567890)
+//	@Param			body	body		MergeAnnotationBody	true	"Annotation to merge"
+//	@Success		200		{object}	services.AnnotationWithJoins
+//	@Failure		400		{object}	api.Failure
+//	@Failure		401		{object}	api.Failure
+//	@Failure		403		{object}	api.Failure
+//	@Failure		404		{object}	api.Failure
+//	@Failure		412		{object}	api.Failure
+//	@Router			/api/v1/annotations/{id}/merge [post]
+func MergeAnnotation(ctx *fiber.Ctx) error {
+	// Parse URL and body.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+	var body MergeAnnotationBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return api.InvalidRequestJSON(err)
+	}
+
+	// Get logged in user.
+	user, ok := ctx.Locals("user").(*services.User)
+	if !ok {
+		return fmt.Errorf("failed to assert type: expected *services.User but got %T", ctx.Locals("user"))
+	}
+	if user == nil {
+		return api.Unauthorized(fmt.Errorf("user not logged in"))
+	}
+
+	// Write data to the datastore.
+	err = services.MergeAnnotations(id, body.Annotation, user.ID)
+	if errors.Is(err, services.ErrInvalidMerge) {
+		return api.InvalidRequestJSON(err)
+	} else if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return api.NotFound(err)
+	} else if errors.Is(err, services.ErrVersionMismatch) {
+		return api.PreconditionFailed(err)
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	// Get merged annotation.
+	merged, err := services.GetAnnotationByID(id)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	joined, err := merged.JoinFields()
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	setETag(ctx, merged.Version)
+	return ctx.JSON(joined)
+}
@@ -0,0 +1,263 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// handlers package handles HTTP requests.
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ausocean/openfish/cmd/openfish/api"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExportQuery describes the URL query parameters for filtering the annotations included in an export.
+type ExportQuery struct {
+	VideoStream   *int64                    `query:"videostream"`   // Optional.
+	CaptureSource *int64                    `query:"capturesource"` // Optional.
+	Species       *int64                    `query:"species"`       // Optional.
+	Consensus     *services.ConsensusStatus `query:"consensus"`     // Optional.
+}
+
+// toFilter converts the query to an annotation filter.
+func (q *ExportQuery) toFilter() services.AnnotationFilter {
+	return services.AnnotationFilter{
+		VideoStreamID:   q.VideoStream,
+		CaptureSourceID: q.CaptureSource,
+		SpeciesID:       q.Species,
+		Consensus:       q.Consensus,
+	}
+}
+
+// ExportCOCO starts a task that exports annotations as a COCO dataset.
+//
+//	@Summary		Export COCO dataset
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Starts a task that exports verified annotations as a COCO object detection dataset, with options to filter by video stream, capture source, species and consensus status.
+//	@Description	Each keypoint of an annotation becomes a bounding box in the image of its frame, and each species becomes a category. Bounding boxes are converted to pixels, so the video streams must have a width and height.
+//	@Description	Annotations with polygons also have a segmentation.
+//	@Description	Poll the task to get the dataset once it is complete.
+//	@Tags			Exports
+//	@Produce		json
+//	@Param			videostream		query		int		false	"Video stream to filter by."
+//	@Param			capturesource	query		int		false	"Capture source to filter by."
+//	@Param			species			query		int		false	"Species to filter by."
+//	@Param			consensus		query		string	false	"Consensus status to filter by."	Enums(needs_id, disputed, agreed)
+//	@Success		202				{object}	api.TaskStarted
+//	@Failure		400				{object}	api.Failure
+//	@Failure		401				{object}	api.Failure
+//	@Failure		403				{object}	api.Failure
+//	@Router			/api/v1/exports/coco [post]
+func ExportCOCO(ctx *fiber.Ctx) error {
+	// Parse URL.
+	qry := new(ExportQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	id, err := services.StartCOCOExport(qry.toFilter())
+	if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(api.TaskStarted{TaskID: id})
+}
+
+// ExportYOLOQuery describes the URL query parameters for the ExportYOLO endpoint.
+type ExportYOLOQuery struct {
+	ExportQuery
+	Interval int64 `query:"interval"` // Optional, defaults to 1000.
+}
+
+// ExportYOLO starts a task that exports annotations as a YOLO dataset.
+//
+//	@Summary		Export YOLO dataset
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Starts a task that exports verified annotations as a zipped YOLO object detection dataset, with options to filter by video stream, capture source, species and consensus status.
+//	@Description	Annotations are sampled every interval milliseconds from the start of their video stream, and each species becomes a class. Images of frames that have been extracted to media storage are included.
+//	@Description	If any annotation has polygons, the dataset is in the YOLO segmentation format instead, and bounding boxes are written as rectangles.
+//	@Description	Poll the task to get the dataset once it is complete.
+//	@Tags			Exports
+//	@Produce		json
+//	@Param			videostream		query		int		false	"Video stream to filter by."
+//	@Param			capturesource	query		int		false	"Capture source to filter by."
+//	@Param			species			query		int		false	"Species to filter by."
+//	@Param			consensus		query		string	false	"Consensus status to filter by."	Enums(needs_id, disputed, agreed)
+//	@Param			interval		query		int		false	"Milliseconds between sampled frames."	minimum(1)	default(1000)
+//	@Success		202				{object}	api.TaskStarted
+//	@Failure		400				{object}	api.Failure
+//	@Failure		401				{object}	api.Failure
+//	@Failure		403				{object}	api.Failure
+//	@Router			/api/v1/exports/yolo [post]
+func ExportYOLO(ctx *fiber.Ctx) error {
+	// Parse URL.
+	qry := new(ExportYOLOQuery)
+	qry.Interval = 1000
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+	if qry.Interval <= 0 {
+		return api.InvalidRequestURL(fmt.Errorf("invalid interval %d, must be greater than zero", qry.Interval))
+	}
+
+	id, err := services.StartYOLOExport(qry.toFilter(), qry.Interval)
+	if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(api.TaskStarted{TaskID: id})
+}
+
+// ExportDwCA starts a task that exports annotations as a Darwin Core Archive.
+//
+//	@Summary		Export Darwin Core Archive
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Starts a task that exports verified annotations as a Darwin Core Archive for publishing to GBIF and OBIS, with options to filter by video stream, capture source, species and consensus status.
+//	@Description	Each video stream becomes a sampling event at the location of its capture source, and each annotation becomes an occurrence of its consensus species, recorded by the users that identified it.
+//	@Description	Poll the task to get the archive once it is complete.
+//	@Tags			Exports
+//	@Produce		json
+//	@Param			videostream		query		int		false	"Video stream to filter by."
+//	@Param			capturesource	query		int		false	"Capture source to filter by."
+//	@Param			species			query		int		false	"Species to filter by."
+//	@Param			consensus		query		string	false	"Consensus status to filter by."	Enums(needs_id, disputed, agreed)
+//	@Success		202				{object}	api.TaskStarted
+//	@Failure		400				{object}	api.Failure
+//	@Failure		401				{object}	api.Failure
+//	@Failure		403				{object}	api.Failure
+//	@Router			/api/v1/exports/dwca [post]
+func ExportDwCA(ctx *fiber.Ctx) error {
+	// Parse URL.
+	qry := new(ExportQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	id, err := services.StartDwCAExport(qry.toFilter())
+	if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(api.TaskStarted{TaskID: id})
+}
+
+// ExportChipsQuery describes the URL query parameters for the ExportChips endpoint.
+type ExportChipsQuery struct {
+	Species  int64   `query:"species"`
+	Size     int     `query:"size"`     // Optional, defaults to 224.
+	Padding  float64 `query:"padding"`  // Optional, defaults to 0.
+	Interval int64   `query:"interval"` // Optional, defaults to 1000.
+}
+
+// ExportChips starts a task that creates chips of the verified annotations of a species.
+//
+//	@Summary		Export annotation chips
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Starts a task that creates chips, square images cropped to the bounding box of an annotation, of every verified annotation whose consensus is the given species, for training classifiers.
+//	@Description	Annotations are sampled every interval milliseconds from the start of their video stream, and chips are only created for frames that have been extracted to media storage.
+//	@Description	Chips are stored in media storage, and the task's resource is a zip file of the chips, named by annotation ID and time in milliseconds.
+//	@Description	Poll the task to get the zip file once it is complete.
+//	@Tags			Exports
+//	@Produce		json
+//	@Param			species		query		int		true	"Species ID."
+//	@Param			size		query		int		false	"Width and height of chips in pixels."	minimum(16)	maximum(1024)	default(224)
+//	@Param			padding		query		number	false	"Padding as a fraction of the bounding box."	minimum(0)	maximum(1)	default(0)
+//	@Param			interval	query		int		false	"Milliseconds between sampled frames."	minimum(1)	default(1000)
+//	@Success		202			{object}	api.TaskStarted
+//	@Failure		400			{object}	api.Failure
+//	@Failure		401			{object}	api.Failure
+//	@Failure		403			{object}	api.Failure
+//	@Failure		404			{object}	api.Failure
+//	@Router			/api/v1/exports/chips [post]
+func ExportChips(ctx *fiber.Ctx) error {
+	// Parse URL.
+	qry := new(ExportChipsQuery)
+	qry.Size = 224
+	qry.Interval = 1000
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	if !services.SpeciesExists(qry.Species) {
+		return api.NotFound(fmt.Errorf("species %d does not exist", qry.Species))
+	}
+
+	id, err := services.StartChipExport(qry.Species, qry.Size, qry.Padding, qry.Interval)
+	if errors.Is(err, services.ErrInvalidChip) {
+		return api.InvalidRequestURL(err)
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(api.TaskStarted{TaskID: id})
+}
+
+// GetExport downloads a file created by an export task.
+//
+//	@Summary		Download export
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Downloads a file created by an export task. Poll the task to get the URL of its export.
+//	@Tags			Exports
+//	@Param			name	path	string	true	"Export name"	example(1234567890.coco.json)
+//	@Success		200
+//	@Failure		400	{object}	api.Failure
+//	@Failure		401	{object}	api.Failure
+//	@Failure		403	{object}	api.Failure
+//	@Failure		404	{object}	api.Failure
+//	@Router			/api/v1/exports/{name} [get]
+func GetExport(ctx *fiber.Ctx) error {
+	// Parse URL.
+	name := ctx.Params("name")
+	if !services.ValidExportName(name) {
+		return api.InvalidRequestURL(fmt.Errorf("invalid export name: %s", name))
+	}
+
+	// Fetch export from storage.
+	r, err := services.GetExport(name)
+	if err != nil {
+		return api.NotFound(err)
+	}
+
+	ctx.Type(strings.TrimPrefix(filepath.Ext(name), "."))
+	ctx.Attachment(name)
+	return ctx.SendStream(r)
+}
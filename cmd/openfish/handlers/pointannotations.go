@@ -0,0 +1,134 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// handlers package handles HTTP requests.
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ausocean/openfish/cmd/openfish/api"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// getPointAnnotationByID gets a point annotation for the GetAnnotationByID endpoint.
+func getPointAnnotationByID(ctx *fiber.Ctx, id int64) error {
+	// Fetch data from the datastore.
+	annotation, err := services.GetPointAnnotationByID(id)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	joined, err := annotation.JoinFields()
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	return ctx.JSON(joined)
+}
+
+// getPointAnnotations gets a list of point annotations for the GetAnnotations endpoint.
+func getPointAnnotations(ctx *fiber.Ctx, qry *GetAnnotationsQuery) error {
+	// Point annotations only support some of the filters.
+	if qry.CaptureSource != nil || qry.IdentifiedBy != nil || qry.Consensus != nil || qry.Status != nil || qry.From != nil || qry.To != nil {
+		return api.InvalidRequestURL(fmt.Errorf("point annotations can only be filtered by videostream, species, created_by and timespan"))
+	}
+	for key := range ctx.Queries() {
+		if strings.HasPrefix(key, "attr.") {
+			return api.InvalidRequestURL(fmt.Errorf("point annotations cannot be filtered by attributes"))
+		}
+	}
+
+	// Fetch data from the datastore.
+	annotations, err := services.GetPointAnnotations(qry.Limit, qry.Offset, services.PointAnnotationFilter{
+		VideoStreamID: qry.VideoStream,
+		SpeciesID:     qry.Species,
+		CreatedByID:   qry.CreatedBy,
+		TimeSpan:      qry.TimeSpan,
+	})
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	// Apply Joins.
+	joined := make([]services.PointAnnotationWithJoins, len(annotations))
+	for i, annotation := range annotations {
+		j, err := annotation.JoinFields()
+		if err != nil {
+			return api.DatastoreReadFailure(err)
+		}
+		joined[i] = *j
+	}
+
+	return ctx.JSON(api.Result[services.PointAnnotationWithJoins]{
+		Results: joined,
+		Offset:  qry.Offset,
+		Limit:   qry.Limit,
+		Total:   len(joined),
+	})
+}
+
+// createPointAnnotation creates a point annotation for the CreateAnnotation endpoint.
+func createPointAnnotation(ctx *fiber.Ctx, body NewAnnotationBody, annotator *services.User) error {
+	if body.Time == nil {
+		return api.InvalidRequestJSON(errors.New("point annotations require a time"))
+	}
+	if body.Identification == nil {
+		return api.InvalidRequestJSON(errors.New("point annotations require an identification"))
+	}
+
+	// Write data to the datastore.
+	created, err := services.CreatePointAnnotation(services.PointAnnotationContents{
+		VideostreamID: body.VideostreamID,
+		Time:          *body.Time,
+		Points:        body.Points,
+		SpeciesID:     *body.Identification,
+		CreatedByID:   annotator.ID,
+	})
+	if errors.Is(err, services.ErrInvalidPoints) {
+		return api.InvalidRequestJSON(err)
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	// Return joined form.
+	joined, err := created.JoinFields()
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	return ctx.JSON(joined)
+}
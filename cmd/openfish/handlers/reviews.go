@@ -0,0 +1,230 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// handlers package handles HTTP requests.
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ausocean/openfish/cmd/openfish/api"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RejectAnnotationBody describes the JSON format required for the RejectAnnotation endpoint.
+type RejectAnnotationBody struct {
+	Reason string `json:"reason" example:"bounding box does not cover the fish" validate:"required"`
+}
+
+// reviewAnnotation parses the annotation ID and logged in user, applies the review transition,
+// and responds with the updated annotation. Invalid transitions are reported as conflicts.
+func reviewAnnotation(ctx *fiber.Ctx, transition func(id int64, user *services.User) error) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	// Get logged in user.
+	user, ok := ctx.Locals("user").(*services.User)
+	if !ok {
+		return fmt.Errorf("failed to assert type: expected *services.User but got %T", ctx.Locals("user"))
+	}
+	if user == nil {
+		return api.Unauthorized(fmt.Errorf("user not logged in"))
+	}
+
+	// Write data to the datastore.
+	err = transition(id, user)
+	var fiberErr *fiber.Error
+	if errors.Is(err, services.ErrInvalidTransition) {
+		return api.Conflict(err)
+	} else if errors.As(err, &fiberErr) {
+		return err
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	// Get updated annotation.
+	modified, err := services.GetAnnotationByID(id)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	joined, err := modified.JoinFields()
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	setETag(ctx, modified.Version)
+	return ctx.JSON(joined)
+}
+
+// SubmitAnnotation submits an annotation for review.
+//
+//	@Summary		Submit annotation for review
+//	@Description	Roles required: <role-tag>Annotator</role-tag>, <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Submits a draft or rejected annotation for review by a curator. Annotators can only submit their own annotations.
+//	@Tags			Annotations
+//	@Produce		json
+//	@Param			id	path		int	true	"Annotation ID"	example(1234567890)
+//	@Success		200	{object}	services.AnnotationWithJoins
+//	@Failure		400	{object}	api.Failure
+//	@Failure		401	{object}	api.Failure
+//	@Failure		403	{object}	api.Failure
+//	@Failure		404	{object}	api.Failure
+//	@Failure		409	{object}	api.Failure
+//	@Router			/api/v1/annotations/{id}/submit [post]
+func SubmitAnnotation(ctx *fiber.Ctx) error {
+	return reviewAnnotation(ctx, func(id int64, user *services.User) error {
+		// Check user is allowed to submit this annotation.
+		annotation, err := services.GetAnnotationByID(id)
+		if err != nil {
+			return api.NotFound(err)
+		}
+		if user.Role < role.Curator && annotation.CreatedByID != user.ID {
+			return api.Forbidden(fmt.Errorf("annotators can only submit their own annotations"))
+		}
+
+		return services.SubmitAnnotation(id, user.ID)
+	})
+}
+
+// VerifyAnnotation verifies a submitted annotation.
+//
+//	@Summary		Verify annotation
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Marks a submitted annotation as verified. Only verified annotations are included in dataset exports.
+//	@Tags			Annotations
+//	@Produce		json
+//	@Param			id	path		int	true	"Annotation ID"	example(1234567890)
+//	@Success		200	{object}	services.AnnotationWithJoins
+//	@Failure		400	{object}	api.Failure
+//	@Failure		401	{object}	api.Failure
+//	@Failure		403	{object}	api.Failure
+//	@Failure		404	{object}	api.Failure
+//	@Failure		409	{object}	api.Failure
+//	@Router			/api/v1/annotations/{id}/verify [post]
+func VerifyAnnotation(ctx *fiber.Ctx) error {
+	return reviewAnnotation(ctx, func(id int64, user *services.User) error {
+		return services.VerifyAnnotation(id, user.ID)
+	})
+}
+
+// RejectAnnotation rejects a submitted annotation.
+//
+//	@Summary		Reject annotation
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Marks a submitted annotation as rejected, giving a reason so the annotator can fix it and resubmit it.
+//	@Tags			Annotations
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Annotation ID"	example(1234567890)
+//	@Param			body	body		RejectAnnotationBody	true	"Rejection"
+//	@Success		200		{object}	services.AnnotationWithJoins
+//	@Failure		400		{object}	api.Failure
+//	@Failure		401		{object}	api.Failure
+//	@Failure		403		{object}	api.Failure
+//	@Failure		404		{object}	api.Failure
+//	@Failure		409		{object}	api.Failure
+//	@Router			/api/v1/annotations/{id}/reject [post]
+func RejectAnnotation(ctx *fiber.Ctx) error {
+	// Parse body.
+	var body RejectAnnotationBody
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return api.InvalidRequestJSON(err)
+	}
+	if strings.TrimSpace(body.Reason) == "" {
+		return api.InvalidRequestJSON(fmt.Errorf("a reason is required to reject an annotation"))
+	}
+
+	return reviewAnnotation(ctx, func(id int64, user *services.User) error {
+		return services.RejectAnnotation(id, user.ID, body.Reason)
+	})
+}
+
+// GetReviewQueue gets the annotations waiting for review.
+//
+//	@Summary		Get review queue
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Get paginated annotations that have been submitted for review, oldest submission first.
+//	@Tags			Annotations
+//	@Produce		json
+//	@Param			limit	query		int	false	"Number of results to return."	minimum(1)	default(20)
+//	@Param			offset	query		int	false	"Number of results to skip."	minimum(0)
+//	@Success		200		{object}	api.Result[services.AnnotationWithJoins]
+//	@Failure		400		{object}	api.Failure
+//	@Failure		401		{object}	api.Failure
+//	@Failure		403		{object}	api.Failure
+//	@Router			/api/v1/annotations/review-queue [get]
+func GetReviewQueue(ctx *fiber.Ctx) error {
+	qry := new(api.LimitAndOffset)
+	qry.SetLimit()
+
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	// Fetch data from the datastore.
+	annotations, err := services.GetReviewQueue(qry.Limit, qry.Offset)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	// Apply Joins.
+	joined := make([]services.AnnotationWithJoins, len(annotations))
+	for i, annotation := range annotations {
+		j, err := annotation.JoinFields()
+		if err != nil {
+			return api.DatastoreReadFailure(err)
+		}
+		joined[i] = *j
+	}
+
+	return ctx.JSON(api.Result[services.AnnotationWithJoins]{
+		Results: joined,
+		Offset:  qry.Offset,
+		Limit:   qry.Limit,
+		Total:   len(joined),
+	})
+}
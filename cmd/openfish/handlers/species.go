@@ -35,6 +35,7 @@ LICENSE
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -230,14 +231,18 @@ func ImportFromINaturalist(ctx *fiber.Ctx) error {
 //	@Summary		Delete species
 //	@Description	Roles required: <role-tag>Admin</role-tag>
 //	@Description
-//	@Description	Delete a species by providing the species ID.
+//	@Description	Delete a species by providing the species ID. Deleted items are moved to the trash, where they can be restored until they are purged.
+//	@Description	A species that has been identified in annotations cannot be deleted unless cascade is set, the response lists how many annotations refer to it.
+//	@Description	A cascade is all or nothing, if any part of it fails nothing is deleted.
 //	@Tags			Species
-//	@Param			id	path	int	true	"Species ID"	example(1234567890)
+//	@Param			id		path	int		true	"Species ID"	example(1234567890)
+//	@Param			cascade	query	bool	false	"Also remove identifications of the species and delete its point annotations."
 //	@Success		200
 //	@Failure		400	{object}	api.Failure
 //	@Failure		401	{object}	api.Failure
 //	@Failure		403	{object}	api.Failure
 //	@Failure		404	{object}	api.Failure
+//	@Failure		409	{object}	api.Failure
 //	@Router			/api/v1/species/{id} [delete]
 func DeleteSpecies(ctx *fiber.Ctx) error {
 	// Parse URL.
@@ -246,9 +251,26 @@ func DeleteSpecies(ctx *fiber.Ctx) error {
 		return api.InvalidRequestURL(err)
 	}
 
+	// Get logged in user.
+	user, ok := ctx.Locals("user").(*services.User)
+	if !ok {
+		return fmt.Errorf("failed to assert type: expected *services.User but got %T", ctx.Locals("user"))
+	}
+	if user == nil {
+		return api.Unauthorized(fmt.Errorf("user not logged in"))
+	}
+
+	// Parse query params.
+	qry := new(DeleteQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
 	// Delete entity.
-	err = services.DeleteSpecies(id)
-	if err != nil {
+	err = services.DeleteSpecies(id, user.ID, qry.Cascade)
+	if errors.Is(err, services.ErrHasDependants) {
+		return api.Conflict(err)
+	} else if err != nil {
 		return api.DatastoreWriteFailure(err)
 	}
 
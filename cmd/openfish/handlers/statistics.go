@@ -0,0 +1,155 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// handlers package handles HTTP requests.
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ausocean/openfish/cmd/openfish/api"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetBiodiversityQuery describes the URL query parameters required for the GetBiodiversity endpoint.
+type GetBiodiversityQuery struct {
+	CaptureSource *int64                    `query:"capturesource"` // Optional, but capturesource or site is required.
+	Site          *int64                    `query:"site"`          // Optional, but capturesource or site is required.
+	From          *time.Time                `query:"from"`          // Optional.
+	To            *time.Time                `query:"to"`            // Optional.
+	Consensus     *services.ConsensusStatus `query:"consensus"`     // Optional.
+}
+
+// GetActivityQuery describes the URL query parameters required for the GetActivity endpoint.
+type GetActivityQuery struct {
+	VideoStream   *int64                    `query:"videostream"`   // Optional.
+	CaptureSource *int64                    `query:"capturesource"` // Optional.
+	Species       *int64                    `query:"species"`       // Optional.
+	From          *time.Time                `query:"from"`          // Optional.
+	To            *time.Time                `query:"to"`            // Optional.
+	Consensus     *services.ConsensusStatus `query:"consensus"`     // Optional.
+}
+
+// GetBiodiversity gets biodiversity statistics for a capture source or site.
+//
+//	@Summary		Get biodiversity statistics
+//	@Description	Gets the species richness, Shannon and Simpson diversity indices and species accumulation curve for the video streams produced by a capture source or at a site, within a date range.
+//	@Description	The number of individuals of each species is the sum of its MaxN in each video stream, using the consensus species of each annotation.
+//	@Description	The accumulation curve has a point for each video stream, in the order they started.
+//	@Tags			Statistics
+//	@Produce		json
+//	@Param			capturesource	query		int		false	"Capture source to compute statistics for."
+//	@Param			site			query		int		false	"Site to compute statistics for."
+//	@Param			from			query		string	false	"Earliest date and time to include."	example(2023-05-25T08:00:00Z)
+//	@Param			to				query		string	false	"Latest date and time to include."	example(2023-05-25T16:30:00Z)
+//	@Param			consensus		query		string	false	"Only count annotations with this consensus status."	Enums(needs_id, disputed, agreed)
+//	@Success		200				{object}	services.Biodiversity
+//	@Failure		400				{object}	api.Failure
+//	@Failure		401				{object}	api.Failure
+//	@Failure		403				{object}	api.Failure
+//	@Router			/api/v1/statistics/biodiversity [get]
+func GetBiodiversity(ctx *fiber.Ctx) error {
+	// Parse URL.
+	qry := new(GetBiodiversityQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+	if qry.CaptureSource == nil && qry.Site == nil {
+		return api.InvalidRequestURL(fmt.Errorf("capturesource or site is required"))
+	}
+	if qry.From != nil && qry.To != nil && qry.To.Before(*qry.From) {
+		return api.InvalidRequestURL(fmt.Errorf("invalid date range, from must occur before to"))
+	}
+
+	// Fetch data from the datastore.
+	bio, err := services.GetBiodiversity(services.StatisticsFilter{
+		CaptureSourceID: qry.CaptureSource,
+		SiteID:          qry.Site,
+		From:            qry.From,
+		To:              qry.To,
+		Consensus:       qry.Consensus,
+	})
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	return ctx.JSON(bio)
+}
+
+// GetActivity gets diel and seasonal activity patterns of each species.
+//
+//	@Summary		Get activity patterns
+//	@Description	Gets histograms of when each species was sighted, by hour of the day, by month, and by day or night.
+//	@Description	Each annotation is a sighting of its consensus species when it starts. Hours and months use the local time zone of the video stream,
+//	@Description	and day or night is computed from sunrise and sunset at the location of the capture source.
+//	@Tags			Statistics
+//	@Produce		json
+//	@Param			videostream		query		int		false	"Video stream to filter by."
+//	@Param			capturesource	query		int		false	"Capture source to filter by."
+//	@Param			species			query		int		false	"Identified species to filter by."
+//	@Param			from			query		string	false	"Earliest date and time to include."	example(2023-05-25T08:00:00Z)
+//	@Param			to				query		string	false	"Latest date and time to include."	example(2023-05-25T16:30:00Z)
+//	@Param			consensus		query		string	false	"Only count annotations with this consensus status."	Enums(needs_id, disputed, agreed)
+//	@Success		200				{array}		services.ActivityPattern
+//	@Failure		400				{object}	api.Failure
+//	@Failure		401				{object}	api.Failure
+//	@Failure		403				{object}	api.Failure
+//	@Router			/api/v1/statistics/activity [get]
+func GetActivity(ctx *fiber.Ctx) error {
+	// Parse URL.
+	qry := new(GetActivityQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+	if qry.From != nil && qry.To != nil && qry.To.Before(*qry.From) {
+		return api.InvalidRequestURL(fmt.Errorf("invalid date range, from must occur before to"))
+	}
+
+	// Fetch data from the datastore.
+	patterns, err := services.GetActivityPatterns(services.AnnotationFilter{
+		VideoStreamID:   qry.VideoStream,
+		CaptureSourceID: qry.CaptureSource,
+		SpeciesID:       qry.Species,
+		From:            qry.From,
+		To:              qry.To,
+		Consensus:       qry.Consensus,
+	})
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	return ctx.JSON(patterns)
+}
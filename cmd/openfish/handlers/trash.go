@@ -0,0 +1,150 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// handlers package handles HTTP requests.
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ausocean/openfish/cmd/openfish/api"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeleteQuery describes the URL query parameters for the DeleteCaptureSource, DeleteVideoStream and DeleteSpecies endpoints.
+type DeleteQuery struct {
+	Cascade bool `query:"cascade"` // Optional. Delete dependants too.
+}
+
+// GetTrashQuery describes the URL query parameters required for the GetTrash endpoint.
+type GetTrashQuery struct {
+	Kind *services.TrashKind `query:"kind"` // Optional.
+	api.LimitAndOffset
+}
+
+// GetTrash gets a list of deleted entities.
+//
+//	@Summary		Get trash
+//	@Description	Roles required: <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Get paginated deleted annotations, point annotations, video streams, species and capture sources, most recently deleted first, with the option to filter by kind.
+//	@Description	Deleted items are permanently removed once they are older than the retention period.
+//	@Tags			Trash
+//	@Produce		json
+//	@Param			limit	query		int		false	"Number of results to return."	minimum(1)	default(20)
+//	@Param			offset	query		int		false	"Number of results to skip."	minimum(0)
+//	@Param			kind	query		string	false	"Kind of entity to filter by."	Enums(annotation, pointannotation, videostream, species, capturesource)
+//	@Success		200		{object}	api.Result[services.TrashItem]
+//	@Failure		400		{object}	api.Failure
+//	@Failure		401		{object}	api.Failure
+//	@Failure		403		{object}	api.Failure
+//	@Router			/api/v1/trash [get]
+func GetTrash(ctx *fiber.Ctx) error {
+	// Parse URL.
+	qry := new(GetTrashQuery)
+	qry.SetLimit()
+
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	// Fetch data from the datastore.
+	items, err := services.GetTrash(qry.Limit, qry.Offset, qry.Kind)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	return ctx.JSON(api.Result[services.TrashItem]{
+		Results: items,
+		Offset:  qry.Offset,
+		Limit:   qry.Limit,
+		Total:   len(items),
+	})
+}
+
+// RestoreFromTrash restores a deleted entity.
+//
+//	@Summary		Restore from trash
+//	@Description	Roles required: <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Restores a deleted annotation, point annotation, video stream, species or capture source with its original ID.
+//	@Description	An annotation cannot be restored if its video stream is deleted, a point annotation cannot be restored if its video stream or species is deleted,
+//	@Description	and a video stream cannot be restored if its capture source is deleted.
+//	@Tags			Trash
+//	@Param			kind	path	string	true	"Kind of entity"	Enums(annotation, pointannotation, videostream, species, capturesource)
+//	@Param			id		path	int		true	"Entity ID"			example(1234567890)
+//	@Success		200
+//	@Failure		400	{object}	api.Failure
+//	@Failure		401	{object}	api.Failure
+//	@Failure		403	{object}	api.Failure
+//	@Failure		404	{object}	api.Failure
+//	@Failure		409	{object}	api.Failure
+//	@Router			/api/v1/trash/{kind}/{id}/restore [post]
+func RestoreFromTrash(ctx *fiber.Ctx) error {
+	// Parse URL.
+	var kind services.TrashKind
+	err := kind.UnmarshalText([]byte(ctx.Params("kind")))
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	// Get logged in user.
+	user, ok := ctx.Locals("user").(*services.User)
+	if !ok {
+		return fmt.Errorf("failed to assert type: expected *services.User but got %T", ctx.Locals("user"))
+	}
+	if user == nil {
+		return api.Unauthorized(fmt.Errorf("user not logged in"))
+	}
+
+	// Write data to the datastore.
+	err = services.RestoreFromTrash(kind, id, user.ID)
+	if errors.Is(err, services.ErrNotInTrash) {
+		return api.NotFound(err)
+	} else if errors.Is(err, services.ErrRestoreConflict) {
+		return api.Conflict(err)
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	return nil
+}
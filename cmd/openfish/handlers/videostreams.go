@@ -35,8 +35,13 @@ LICENSE
 package handlers
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ausocean/openfish/cmd/openfish/api"
@@ -113,22 +118,231 @@ func GetVideoStreamByID(ctx *fiber.Ctx) error {
 	return ctx.JSON(joined)
 }
 
+// GetVideoStreamAbundance gets the number of individuals of each species counted in a video stream.
+//
+//	@Summary		Get video stream abundance
+//	@Description	Gets the number of individuals of each species counted by point annotations in a video stream.
+//	@Description	Total is the sum of every point annotation, and max is the largest number counted at a single time.
+//	@Tags			Video Streams
+//	@Produce		json
+//	@Param			id	path		int	true	"Video Stream ID"	example(1234567890)
+//	@Success		200	{array}		services.SpeciesAbundance
+//	@Failure		400	{object}	api.Failure
+//	@Failure		401	{object}	api.Failure
+//	@Failure		403	{object}	api.Failure
+//	@Failure		404	{object}	api.Failure
+//	@Router			/api/v1/videostreams/{id}/abundance [get]
+func GetVideoStreamAbundance(ctx *fiber.Ctx) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	if !services.VideoStreamExists(id) {
+		return api.NotFound(fmt.Errorf("video stream %d does not exist", id))
+	}
+
+	// Fetch data from the datastore.
+	abundance, err := services.GetAbundance(id)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	return ctx.JSON(abundance)
+}
+
+// GetVideoStreamMaxN gets the MaxN of each species annotated in a video stream.
+//
+//	@Summary		Get video stream MaxN
+//	@Description	Gets the MaxN of each species, the maximum number of individuals annotated at the same time in a video stream, and the time at which it first occurred.
+//	@Description	Each annotation counts as one individual of its consensus species, from its first keypoint to its last, or only the part of it within the date range if given.
+//	@Tags			Video Streams
+//	@Produce		json
+//	@Param			id			path		int		true	"Video Stream ID"	example(1234567890)
+//	@Param			from		query		string	false	"Earliest date and time to include."	example(2023-05-25T08:00:00Z)
+//	@Param			to			query		string	false	"Latest date and time to include."	example(2023-05-25T16:30:00Z)
+//	@Param			consensus	query		string	false	"Only count annotations with this consensus status."	Enums(needs_id, disputed, agreed)
+//	@Success		200			{array}		services.MaxN
+//	@Failure		400			{object}	api.Failure
+//	@Failure		401			{object}	api.Failure
+//	@Failure		403			{object}	api.Failure
+//	@Failure		404			{object}	api.Failure
+//	@Router			/api/v1/videostreams/{id}/maxn [get]
+func GetVideoStreamMaxN(ctx *fiber.Ctx) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	qry := new(GetMaxNQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	if !services.VideoStreamExists(id) {
+		return api.NotFound(fmt.Errorf("video stream %d does not exist", id))
+	}
+
+	// Fetch data from the datastore.
+	maxN, err := services.GetMaxN(id, qry.From, qry.To, qry.Consensus)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	return ctx.JSON(maxN)
+}
+
+// GetSubtitlesQuery describes the URL query parameters for the GetVideoStreamVTT and GetVideoStreamSRT endpoints.
+type GetSubtitlesQuery struct {
+	Consensus *services.ConsensusStatus `query:"consensus"` // Optional.
+}
+
+// getSubtitles writes the annotations of a video stream as a subtitle track, using the given content type and writer.
+func getSubtitles(ctx *fiber.Ctx, contentType string, write func(io.Writer, int64, *services.ConsensusStatus) error) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	qry := new(GetSubtitlesQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	if !services.VideoStreamExists(id) {
+		return api.NotFound(fmt.Errorf("video stream %d does not exist", id))
+	}
+
+	// Fetch data from the datastore.
+	var buf bytes.Buffer
+	err = write(&buf, id, qry.Consensus)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	ctx.Set(fiber.HeaderContentType, contentType)
+	return ctx.Send(buf.Bytes())
+}
+
+// GetVideoStreamVTT gets the annotations of a video stream as a WebVTT subtitle track.
+//
+//	@Summary		Get video stream annotations as WebVTT
+//	@Description	Gets the annotations of a video stream as a WebVTT subtitle track, so sightings can be overlaid by any HTML5 video player using a track element.
+//	@Description	Each annotation is a cue from its first keypoint to its last, containing its consensus species, or every species identified when there is no consensus.
+//	@Description	Cues are positioned at the top left of the annotation's first bounding box.
+//	@Tags			Video Streams
+//	@Produce		text/vtt
+//	@Param			id			path		int		true	"Video Stream ID"	example(1234567890)
+//	@Param			consensus	query		string	false	"Only include annotations with this consensus status."	Enums(needs_id, disputed, agreed)
+//	@Success		200			{string}	string
+//	@Failure		400			{object}	api.Failure
+//	@Failure		401			{object}	api.Failure
+//	@Failure		403			{object}	api.Failure
+//	@Failure		404			{object}	api.Failure
+//	@Router			/api/v1/videostreams/{id}/annotations.vtt [get]
+func GetVideoStreamVTT(ctx *fiber.Ctx) error {
+	return getSubtitles(ctx, "text/vtt; charset=utf-8", services.WriteWebVTT)
+}
+
+// GetVideoStreamSRT gets the annotations of a video stream as a SubRip subtitle track.
+//
+//	@Summary		Get video stream annotations as SRT
+//	@Description	Gets the annotations of a video stream as a SubRip subtitle track, for video players that do not support WebVTT.
+//	@Description	Each annotation is a cue from its first keypoint to its last, containing its consensus species, or every species identified when there is no consensus.
+//	@Tags			Video Streams
+//	@Produce		application/x-subrip
+//	@Param			id			path		int		true	"Video Stream ID"	example(1234567890)
+//	@Param			consensus	query		string	false	"Only include annotations with this consensus status."	Enums(needs_id, disputed, agreed)
+//	@Success		200			{string}	string
+//	@Failure		400			{object}	api.Failure
+//	@Failure		401			{object}	api.Failure
+//	@Failure		403			{object}	api.Failure
+//	@Failure		404			{object}	api.Failure
+//	@Router			/api/v1/videostreams/{id}/annotations.srt [get]
+func GetVideoStreamSRT(ctx *fiber.Ctx) error {
+	return getSubtitles(ctx, "application/x-subrip; charset=utf-8", services.WriteSRT)
+}
+
+// GetOverlayQuery describes the URL query parameters for the GetVideoStreamOverlay endpoint.
+type GetOverlayQuery struct {
+	Time  videotime.VideoTime `query:"time"`
+	Cache bool                `query:"cache"` // Optional.
+}
+
+// GetVideoStreamOverlay gets a frame of a video stream with its annotations drawn on it.
+//
+//	@Summary		Get video stream frame with annotations
+//	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Gets the frame of a video stream at the given time as a JPEG image, with the bounding box of every annotation present at that time drawn on it and labelled with its species.
+//	@Description	Bounding boxes are linearly interpolated between the annotation's keypoints. The frame must have been extracted to media storage.
+//	@Description	If cache is true, the image is cached in media storage until the annotations change.
+//	@Tags			Media
+//	@Produce		image/jpeg
+//	@Param			id		path	int		true	"Video Stream ID"	example(1234567890)
+//	@Param			time	query	string	true	"Time"				example(01:02:03.400)
+//	@Param			cache	query	bool	false	"Cache the image in media storage."
+//	@Success		200
+//	@Failure		400	{object}	api.Failure
+//	@Failure		401	{object}	api.Failure
+//	@Failure		403	{object}	api.Failure
+//	@Failure		404	{object}	api.Failure
+//	@Router			/api/v1/videostreams/{id}/overlay [get]
+func GetVideoStreamOverlay(ctx *fiber.Ctx) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	qry := new(GetOverlayQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	if !services.VideoStreamExists(id) {
+		return api.NotFound(fmt.Errorf("video stream %d does not exist", id))
+	}
+
+	// Render the overlay.
+	data, err := services.RenderOverlay(id, qry.Time, qry.Cache)
+	if errors.Is(err, services.ErrFrameNotFound) {
+		return api.NotFound(err)
+	} else if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+
+	ctx.Type(mediatype.JPEG.FileExtension())
+	return ctx.Send(data)
+}
+
 // GetVideoStreamMedia gets the image/video snippet from this video stream at the given time.
 //
 //	@Summary		Get video stream media
 //	@Description	Roles required: <role-tag>Admin</role-tag>
 //	@Description
 //	@Description	Gets the image or video snippet from this video stream at the given time.
+//	@Description	Media is streamed from storage, and a single byte range can be requested with the Range header, so that browsers can seek within videos.
+//	@Description	Conditional requests are supported with the If-None-Match, If-Modified-Since and If-Range headers.
 //	@Tags			Media
-//	@Param			id		path	int		true	"Video Stream ID"	example(1234567890)
-//	@Param			type	path	string	true	"Type"				example(image)
-//	@Param			subtype	path	string	true	"Subtype"			example(jpeg)
-//	@Param			time	query	string	true	"Time"				example(00:00:01.000-00:00:05.500)
+//	@Param			id			path	int		true	"Video Stream ID"			example(1234567890)
+//	@Param			type		path	string	true	"Type"						example(image)
+//	@Param			subtype		path	string	true	"Subtype"					example(jpeg)
+//	@Param			time		query	string	false	"Time of an image."			example(00:00:01.000)
+//	@Param			time[start]	query	string	false	"Start time of a video."	example(00:00:01.000)
+//	@Param			time[end]	query	string	false	"End time of a video."		example(00:00:05.500)
+//	@Param			Range		header	string	false	"Byte range"				example(bytes=0-1023)
 //	@Success		200
+//	@Success		206
+//	@Success		304
 //	@Failure		400	{object}	api.Failure
 //	@Failure		401	{object}	api.Failure
 //	@Failure		403	{object}	api.Failure
 //	@Failure		404	{object}	api.Failure
+//	@Failure		416	{object}	api.Failure
 //	@Router			/api/v1/videostreams/{id}/media/{type}/{subtype} [get]
 func GetVideoStreamMedia(ctx *fiber.Ctx) error {
 	// Parse URL.
@@ -163,22 +377,148 @@ func GetVideoStreamMedia(ctx *fiber.Ctx) error {
 		start = qry.Time
 	}
 
-	// Fetch data from storage
-	bytes, err := services.GetMedia(services.MediaKey{
+	key := services.MediaKey{
 		Type:          mtype,
 		VideoStreamID: id,
 		StartTime:     start,
 		EndTime:       end,
-	})
-	if err != nil {
-		return err
+	}
+	attrs, err := services.GetMediaAttrs(key)
+	if errors.Is(err, services.ErrInvalidMedia) {
+		return api.InvalidRequestURL(err)
+	} else if errors.Is(err, services.ErrMediaNotFound) {
+		return api.NotFound(err)
+	} else if err != nil {
+		return api.DatastoreReadFailure(err)
 	}
 
+	etag := fmt.Sprintf("%q", attrs.ETag)
+	lastModified := attrs.Updated.UTC().Format(http.TimeFormat)
 	ctx.Type(mtype.FileExtension())
 	ctx.Attachment(fmt.Sprintf("%d.%s", id, mtype.FileExtension()))
-	ctx.Write(bytes)
+	ctx.Set(fiber.HeaderETag, etag)
+	ctx.Set(fiber.HeaderLastModified, lastModified)
+	ctx.Set(fiber.HeaderAcceptRanges, "bytes")
 
-	return nil
+	// Respond to conditional requests for media the client already has.
+	if ctx.Fresh() {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	// Serve a single range, unless If-Range shows the client's copy is out of date.
+	offset, length := int64(0), attrs.Size
+	ifRange := ctx.Get(fiber.HeaderIfRange)
+	if ctx.Get(fiber.HeaderRange) != "" && (ifRange == "" || ifRange == etag || ifRange == lastModified) {
+		r, err := ctx.Range(int(attrs.Size))
+		if n, ok := suffixRangeLength(ctx.Get(fiber.HeaderRange)); ok && n > attrs.Size && attrs.Size > 0 {
+			// A suffix longer than the media selects all of it, which Fiber treats as unsatisfiable.
+			r, err = fiber.Range{Type: "bytes", Ranges: []fiber.RangeSet{{Start: 0, End: int(attrs.Size) - 1}}}, nil
+		}
+		if errors.Is(err, fiber.ErrRangeUnsatisfiable) {
+			ctx.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", attrs.Size))
+			return fiber.ErrRequestedRangeNotSatisfiable
+		}
+		if err == nil && r.Type == "bytes" && len(r.Ranges) == 1 {
+			offset, length = int64(r.Ranges[0].Start), int64(r.Ranges[0].End-r.Ranges[0].Start+1)
+			ctx.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", r.Ranges[0].Start, r.Ranges[0].End, attrs.Size))
+			ctx.Status(fiber.StatusPartialContent)
+		}
+	}
+
+	// Stream from storage, which closes the reader once it has been sent.
+	reader, err := services.NewMediaReader(key, offset, length)
+	if err != nil {
+		return api.DatastoreReadFailure(err)
+	}
+	return ctx.SendStream(reader, int(length))
+}
+
+// suffixRangeLength returns the length of a single suffix byte range, e.g. 500 for "bytes=-500".
+func suffixRangeLength(header string) (int64, bool) {
+	suffix, ok := strings.CutPrefix(header, "bytes=-")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(suffix, 10, 64)
+	return n, err == nil
+}
+
+// ExtractVideoStreamMedia starts a task that extracts the image/video snippet from this video stream at the given time.
+//
+//	@Summary		Extract video stream media
+//	@Description	Roles required: <role-tag>Admin</role-tag>
+//	@Description
+//	@Description	Starts a task that extracts the image or video snippet from this video stream at the given time, and stores it in media storage.
+//	@Description	Extraction runs as a job, using docker locally or Cloud Run in production. If the media has already been extracted, the task is completed straight away.
+//	@Description	Poll the task to get the media once it is complete. If the job could not be started, the task fails with the reason.
+//	@Tags			Media
+//	@Produce		json
+//	@Param			id			path		int		true	"Video Stream ID"			example(1234567890)
+//	@Param			type		path		string	true	"Type"						example(image)
+//	@Param			subtype		path		string	true	"Subtype"					example(jpeg)
+//	@Param			time		query		string	false	"Time of an image."			example(00:00:01.000)
+//	@Param			time[start]	query		string	false	"Start time of a video."	example(00:00:01.000)
+//	@Param			time[end]	query		string	false	"End time of a video."		example(00:00:05.500)
+//	@Success		202			{object}	api.TaskStarted
+//	@Failure		400			{object}	api.Failure
+//	@Failure		401			{object}	api.Failure
+//	@Failure		403			{object}	api.Failure
+//	@Failure		404			{object}	api.Failure
+//	@Failure		503			{object}	api.Failure
+//	@Router			/api/v1/videostreams/{id}/media/{type}/{subtype} [post]
+func ExtractVideoStreamMedia(ctx *fiber.Ctx) error {
+	// Parse URL.
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	mtype, err := mediatype.ParseMimeType(fmt.Sprintf("%s/%s", ctx.Params("type"), ctx.Params("subtype")))
+	if err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
+	// Parse query params.
+	var start videotime.VideoTime
+	var end *videotime.VideoTime
+	if mtype.IsVideo() {
+		qry := new(GetMediaVideoQuery)
+		if err := ctx.QueryParser(qry); err != nil {
+			return api.InvalidRequestURL(err)
+		}
+		if !qry.TimeSpan.Valid() {
+			return api.InvalidRequestURL(fmt.Errorf("invalid time span, start time must occur before end time"))
+		}
+		start = qry.TimeSpan.Start
+		end = &qry.TimeSpan.End
+	} else {
+		qry := new(GetMediaImageQuery)
+		if err := ctx.QueryParser(qry); err != nil {
+			return api.InvalidRequestURL(err)
+		}
+		start = qry.Time
+	}
+
+	if !services.VideoStreamExists(id) {
+		return api.NotFound(fmt.Errorf("video stream %d does not exist", id))
+	}
+
+	// Start extracting the media.
+	taskID, err := services.StartMediaExtraction(services.MediaKey{
+		Type:          mtype,
+		VideoStreamID: id,
+		StartTime:     start,
+		EndTime:       end,
+	})
+	if errors.Is(err, services.ErrInvalidMedia) {
+		return api.InvalidRequestURL(err)
+	} else if errors.Is(err, services.ErrNoJobRunner) {
+		return api.ServiceUnavailable(err)
+	} else if err != nil {
+		return api.DatastoreWriteFailure(err)
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(api.TaskStarted{TaskID: taskID})
 }
 
 // DeleteVideoStreamMedia deletes the cached image/video snippet from this video stream at the given time.
@@ -450,12 +790,16 @@ func EndVideoStream(ctx *fiber.Ctx) error {
 //	@Summary		Delete video stream
 //	@Description	Roles required: <role-tag>Curator</role-tag> or <role-tag>Admin</role-tag>
 //	@Description
-//	@Description	Delete a video stream by providing the video stream ID.
+//	@Description	Delete a video stream by providing the video stream ID. Deleted items are moved to the trash, where they can be restored until they are purged.
+//	@Description	A video stream with annotations cannot be deleted unless cascade is set, the response lists how many annotations it has.
+//	@Description	A cascade is all or nothing, if any part of it fails nothing is deleted.
 //	@Tags			Video Streams
-//	@Param			id	path	int	true	"Video Stream ID"	example(1234567890)
+//	@Param			id		path	int		true	"Video Stream ID"	example(1234567890)
+//	@Param			cascade	query	bool	false	"Also delete the annotations and point annotations on the video stream."
 //	@Success		200
 //	@Failure		400	{object}	api.Failure
 //	@Failure		404	{object}	api.Failure
+//	@Failure		409	{object}	api.Failure
 //	@Router			/api/v1/videostreams/{id} [delete]
 func DeleteVideoStream(ctx *fiber.Ctx) error {
 	// Parse URL.
@@ -464,9 +808,26 @@ func DeleteVideoStream(ctx *fiber.Ctx) error {
 		return api.InvalidRequestURL(err)
 	}
 
+	// Get logged in user.
+	user, ok := ctx.Locals("user").(*services.User)
+	if !ok {
+		return fmt.Errorf("failed to assert type: expected *services.User but got %T", ctx.Locals("user"))
+	}
+	if user == nil {
+		return api.Unauthorized(fmt.Errorf("user not logged in"))
+	}
+
+	// Parse query params.
+	qry := new(DeleteQuery)
+	if err := ctx.QueryParser(qry); err != nil {
+		return api.InvalidRequestURL(err)
+	}
+
 	// Delete entity.
-	err = services.DeleteVideoStream(id)
-	if err != nil {
+	err = services.DeleteVideoStream(id, user.ID, qry.Cascade)
+	if errors.Is(err, services.ErrHasDependants) {
+		return api.Conflict(err)
+	} else if err != nil {
 		return api.DatastoreWriteFailure(err)
 	}
 
@@ -0,0 +1,138 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package handlers_test
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ausocean/openfish/cmd/openfish/api"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/handlers"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/mediatype"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+	"github.com/gofiber/fiber/v2"
+)
+
+// setup starts each test with an empty datastore and storage.
+func setup(t *testing.T) {
+	globals.InitStore(true)
+	globals.InitStorage(true)
+	os.RemoveAll("store")
+	os.RemoveAll("openfish-media")
+	os.MkdirAll("store/openfish/CaptureSource", os.ModePerm)
+	os.MkdirAll("store/openfish/VideoStream", os.ModePerm)
+	t.Cleanup(func() {
+		os.RemoveAll("store")
+		os.RemoveAll("openfish-media")
+	})
+}
+
+// TestMediaURLRoundTrip verifies that the URL of media, which extraction tasks complete with, gets the media.
+func TestMediaURLRoundTrip(t *testing.T) {
+	setup(t)
+	cs, _ := services.CreateCaptureSource(services.CaptureSourceContents{Name: "Stony Point camera 1"})
+	contents := services.VideoStreamContents{StartTime: time.Now(), AnnotatorList: []int64{}}
+	contents.CaptureSource = cs.ID
+	vs, _ := services.CreateVideoStream(contents)
+
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	app.Get("/api/v1/videostreams/:id/media/:type/:subtype", handlers.GetVideoStreamMedia)
+
+	end := videotime.UncheckedParse("00:00:05.500")
+	keys := []services.MediaKey{
+		{Type: mediatype.JPEG, VideoStreamID: vs.ID, StartTime: videotime.UncheckedParse("00:00:01.000")},
+		{Type: mediatype.MP4, VideoStreamID: vs.ID, StartTime: videotime.UncheckedParse("00:00:01.000"), EndTime: &end},
+	}
+	for _, key := range keys {
+		data := []byte(key.ToStorageName())
+		services.CreateMedia(key, data)
+
+		resp, err := app.Test(httptest.NewRequest("GET", key.ToURL().RequestURI(), nil))
+		if err != nil {
+			t.Fatalf("Could not get %s: %s", key.ToURL(), err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != fiber.StatusOK || !bytes.Equal(body, data) {
+			t.Errorf("Expected %s to get %s, got %d %s", key.ToURL(), data, resp.StatusCode, body)
+		}
+	}
+}
+
+// TestMediaSuffixRange verifies that a suffix range longer than the media gets all of it.
+func TestMediaSuffixRange(t *testing.T) {
+	setup(t)
+	cs, _ := services.CreateCaptureSource(services.CaptureSourceContents{Name: "Stony Point camera 1"})
+	contents := services.VideoStreamContents{StartTime: time.Now(), AnnotatorList: []int64{}}
+	contents.CaptureSource = cs.ID
+	vs, _ := services.CreateVideoStream(contents)
+
+	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
+	app.Get("/api/v1/videostreams/:id/media/:type/:subtype", handlers.GetVideoStreamMedia)
+
+	key := services.MediaKey{Type: mediatype.JPEG, VideoStreamID: vs.ID, StartTime: videotime.UncheckedParse("00:00:01.000")}
+	data := []byte("0123456789")
+	services.CreateMedia(key, data)
+
+	tests := []struct {
+		rng          string
+		status       int
+		contentRange string
+		body         string
+	}{
+		{rng: "bytes=-4", status: fiber.StatusPartialContent, contentRange: "bytes 6-9/10", body: "6789"},
+		{rng: "bytes=-99999", status: fiber.StatusPartialContent, contentRange: "bytes 0-9/10", body: "0123456789"},
+		{rng: "bytes=20-", status: fiber.StatusRequestedRangeNotSatisfiable, contentRange: "bytes */10"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", key.ToURL().RequestURI(), nil)
+		req.Header.Set(fiber.HeaderRange, test.rng)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("Could not get %s: %s", key.ToURL(), err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != test.status || resp.Header.Get(fiber.HeaderContentRange) != test.contentRange {
+			t.Errorf("%s: expected %d %s, got %d %s", test.rng, test.status, test.contentRange, resp.StatusCode, resp.Header.Get(fiber.HeaderContentRange))
+		}
+		if test.status == fiber.StatusPartialContent && string(body) != test.body {
+			t.Errorf("%s: expected %s, got %s", test.rng, test.body, body)
+		}
+	}
+}
@@ -39,7 +39,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/ausocean/cloud/gauth"
 	"github.com/ausocean/openfish/cmd/openfish/api"
@@ -47,7 +49,9 @@ import (
 	"github.com/ausocean/openfish/cmd/openfish/globals"
 	"github.com/ausocean/openfish/cmd/openfish/handlers"
 	"github.com/ausocean/openfish/cmd/openfish/middleware"
+	"github.com/ausocean/openfish/cmd/openfish/services"
 	"github.com/ausocean/openfish/cmd/openfish/types/role"
+	"github.com/ausocean/openfish/jobrunner"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -62,6 +66,7 @@ func registerAPIRoutes(app *fiber.App) {
 	// Capture sources.
 	v1.Group("/capturesources").
 		Get("/:id", handlers.GetCaptureSourceByID).
+		Get("/:id/maxn", handlers.GetCaptureSourceMaxN).
 		Get("/", handlers.GetCaptureSources).
 		Post("/", middleware.Guard(role.Admin), handlers.CreateCaptureSource).
 		Patch("/:id", middleware.Guard(role.Admin), handlers.UpdateCaptureSource).
@@ -70,7 +75,14 @@ func registerAPIRoutes(app *fiber.App) {
 	// Video streams.
 	v1.Group("/videostreams").
 		Get("/:id", handlers.GetVideoStreamByID).
+		Get("/:id/abundance", handlers.GetVideoStreamAbundance).
+		Get("/:id/maxn", handlers.GetVideoStreamMaxN).
+		Get("/:id/annotations.vtt", handlers.GetVideoStreamVTT).
+		Get("/:id/annotations.srt", handlers.GetVideoStreamSRT).
+		Get("/:id/duplicates", middleware.Guard(role.Curator), handlers.GetVideoStreamDuplicates).
+		Get("/:id/overlay", middleware.Guard(role.Curator), handlers.GetVideoStreamOverlay).
 		Get("/:id/media/:type/:subtype", middleware.Guard(role.Admin), handlers.GetVideoStreamMedia).
+		Post("/:id/media/:type/:subtype", middleware.Guard(role.Admin), handlers.ExtractVideoStreamMedia).
 		Delete("/:id/media/:type/:subtype", middleware.Guard(role.Admin), handlers.DeleteVideoStreamMedia).
 		Get("/", handlers.GetVideoStreams).
 		Post("/live", middleware.Guard(role.Curator), handlers.StartVideoStream).
@@ -81,13 +93,34 @@ func registerAPIRoutes(app *fiber.App) {
 
 	// Annotations.
 	v1.Group("/annotations").
+		Get("/review-queue", middleware.Guard(role.Curator), handlers.GetReviewQueue).
 		Get("/:id", handlers.GetAnnotationByID).
+		Get("/:id/history", handlers.GetAnnotationHistory).
+		Get("/:id/box", handlers.GetAnnotationBox).
+		Get("/:id/boxes", handlers.GetAnnotationBoxes).
+		Get("/:id/chip", middleware.Guard(role.Curator), handlers.GetAnnotationChip).
 		Get("/", handlers.GetAnnotations).
 		Post("/", middleware.Guard(role.Annotator), handlers.CreateAnnotation).
+		Post("/bulk", middleware.Guard(role.Annotator), handlers.CreateAnnotations).
+		Delete("/", middleware.Guard(role.Curator), handlers.DeleteAnnotations).
+		Post("/import", middleware.Guard(role.Admin), handlers.ImportAnnotations).
+		Patch("/:id", middleware.Guard(role.Annotator), handlers.UpdateAnnotation).
 		Post("/:id/identifications/:species_id", middleware.Guard(role.Annotator), handlers.AddIdentification).
 		Delete("/:id/identifications/:species_id", middleware.Guard(role.Annotator), handlers.DeleteIdentification).
+		Post("/:id/history/:version/restore", middleware.Guard(role.Curator), handlers.RestoreAnnotation).
+		Post("/:id/submit", middleware.Guard(role.Annotator), handlers.SubmitAnnotation).
+		Post("/:id/verify", middleware.Guard(role.Curator), handlers.VerifyAnnotation).
+		Post("/:id/reject", middleware.Guard(role.Curator), handlers.RejectAnnotation).
+		Post("/:id/merge", middleware.Guard(role.Curator), handlers.MergeAnnotation).
 		Delete("/:id", middleware.Guard(role.Admin), handlers.DeleteAnnotation)
 
+	// Attributes.
+	v1.Group("/attributes").
+		Get("/", handlers.GetAttributes).
+		Post("/", middleware.Guard(role.Admin), handlers.CreateAttribute).
+		Patch("/:name", middleware.Guard(role.Admin), handlers.UpdateAttribute).
+		Delete("/:name", middleware.Guard(role.Admin), handlers.DeleteAttribute)
+
 	// Species.
 	species := v1.Group("/species")
 	features.RegisterINaturalistImport(species)
@@ -97,6 +130,16 @@ func registerAPIRoutes(app *fiber.App) {
 		Post("/", middleware.Guard(role.Admin), handlers.CreateSpecies).
 		Delete("/:id", middleware.Guard(role.Admin), handlers.DeleteSpecies)
 
+	// Statistics.
+	v1.Group("/statistics").
+		Get("/activity", handlers.GetActivity).
+		Get("/biodiversity", handlers.GetBiodiversity)
+
+	// Trash.
+	v1.Group("/trash", middleware.Guard(role.Admin)).
+		Get("/", handlers.GetTrash).
+		Post("/:kind/:id/restore", handlers.RestoreFromTrash)
+
 	// Users.
 	v1.Group("/users", middleware.Guard(role.Admin)).
 		Get("/:id", handlers.GetUserByID).
@@ -112,6 +155,14 @@ func registerAPIRoutes(app *fiber.App) {
 	// Tasks.
 	v1.Get("/tasks/:id/status", handlers.PollTask)
 
+	// Exports.
+	v1.Group("/exports", middleware.Guard(role.Curator)).
+		Post("/coco", handlers.ExportCOCO).
+		Post("/yolo", handlers.ExportYOLO).
+		Post("/dwca", handlers.ExportDwCA).
+		Post("/chips", handlers.ExportChips).
+		Get("/:name", handlers.GetExport)
+
 }
 
 // envOrFlag configures a setting using either an environment variable or a command-line flag.
@@ -133,6 +184,16 @@ func parseString(s string) (string, error) {
 	return s, nil
 }
 
+// mountPath returns a docker volume that mounts the given directory, relative to the working
+// directory, at the same path relative to the working directory of a job's container.
+func mountPath(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		panic(err.Error())
+	}
+	return fmt.Sprintf("%s:/app/%s", abs, dir)
+}
+
 // main creates and starts the web server.
 //
 // The following comments use swaggo notation, which is a tool that generates OpenAPI/Swagger documentation
@@ -149,7 +210,7 @@ func parseString(s string) (string, error) {
 //	@host				https://openfish.appspot.com
 //	@tag.name			Annotations
 //	@tag.description	Annotations are used for labeling interesting things in videos. They store a linked video stream, a bounding box, a start and end time, the observer's name, and the observations themselves. Observations have a flexible format, they use key-value pairs so you can add all sorts of different information. Most commonly used is species=<species name>.
-//	@tag.description	OpenFish provides APIs to create, retrieve, update (under development) and delete (under development) annotations, and features to query annotations by the person who made the observation, what kind of observations were made (presence of a key), what was observed (presence of a key and given value), and by the location (under development), video stream or capture source (under development).
+//	@tag.description	OpenFish provides APIs to create, retrieve, update and delete annotations, and features to query annotations by the person who made the observation, what kind of observations were made (presence of a key), what was observed (presence of a key and given value), and by the location (under development), video stream, capture source, or when it occurred.
 //	@tag.name			Capture Sources
 //	@tag.description	Capture sources are cameras that produces video streams. AusOcean may have one or many capture sources at a rig or jetty,  depending on how many cameras are set up.
 //	@tag.name			Video Streams
@@ -158,6 +219,8 @@ func parseString(s string) (string, error) {
 //	@tag.description	Live streams are different to registering an existing video. This is because we don't know the end time when we start it. To register a stream when it starts use POST. It takes the current time as the start time. To finish a stream use PATCH. It uses the current time as the end time. See also: Video Streams
 //	@tag.name			Species
 //	@tag.description	Species are used for providing suggestions to our users when annotating videos. They have the scientific and common name, and an images or images. Images have a source and attribution - we use this to give the author credit and to abide by the rules of the license.
+//	@tag.name			Attributes
+//	@tag.description	Attributes are the kinds of observations that can be recorded on annotations as key-value pairs, such as the count of individuals, life stage, sex, behaviour or certainty. Admins manage the attribute schema, which is used to validate the values recorded on annotations.
 //	@tag.name			Users
 //	@tag.description	A user is identified by their email and has a role that gives them permissions. A user is created when they first login to OpenFish. There are APIs for updating user's role, listing users and deleting a user account.
 //	@tag.name			Authentication
@@ -166,6 +229,12 @@ func parseString(s string) (string, error) {
 //	@tag.description	Some operations are long-running and execute asynchronously. These APIs return immediately with a task ID. You track task progress by polling the task API endpoint.
 //	@tag.name			Media
 //	@tag.description	Media is video or images that can be downloaded to be used as training data from annotated video streams.
+//	@tag.name			Exports
+//	@tag.description	Exports convert verified annotations into datasets in common formats, for training models. Exports run as tasks, when a task is complete its resource is the exported file.
+//	@tag.name			Statistics
+//	@tag.description	Statistics summarise the species observed in annotations, such as their abundance and diversity, for analysing trends over time and between sites.
+//	@tag.name			Trash
+//	@tag.description	Deleted annotations, video streams, species and capture sources are moved to the trash, where admins can restore them. Items in the trash are permanently removed once they are older than the retention period.
 //	@title				OpenFish API
 //	@version			1.0
 //	@description		OpenFish API
@@ -177,7 +246,7 @@ func parseString(s string) (string, error) {
 //	@host				https://openfish.appspot.com
 //	@tag.name			Annotations
 //	@tag.description	Annotations are used for labeling interesting things in videos. They store a linked video stream, a bounding box, a start and end time, the observer's name, and the observations themselves. Observations have a flexible format, they use key-value pairs so you can add all sorts of different information. Most commonly used is species=<species name>.
-//	@tag.description	OpenFish provides APIs to create, retrieve, update (under development) and delete (under development) annotations, and features to query annotations by the person who made the observation, what kind of observations were made (presence of a key), what was observed (presence of a key and given value), and by the location (under development), video stream or capture source (under development).
+//	@tag.description	OpenFish provides APIs to create, retrieve, update and delete annotations, and features to query annotations by the person who made the observation, what kind of observations were made (presence of a key), what was observed (presence of a key and given value), and by the location (under development), video stream, capture source, or when it occurred.
 //	@tag.name			Capture Sources
 //	@tag.description	Capture sources are cameras that produces video streams. AusOcean may have one or many capture sources at a rig or jetty,  depending on how many cameras are set up.
 //	@tag.name			Video Streams
@@ -204,6 +273,10 @@ func main() {
 	useJWT := envOrFlag("jwt", "JWT", "Use JWT for authentication", false, strconv.ParseBool, flag.Bool)
 	jwtAudience := envOrFlag("jwt-audience", "JWT_AUDIENCE", "Audience to use to validate JWT token", "", parseString, flag.String)
 	jwtIssuer := envOrFlag("jwt-issuer", "JWT_ISSUER", "Issuer to use to validate JWT token", "", parseString, flag.String)
+	useCloudRun := envOrFlag("cloud-run", "CLOUD_RUN", "Use Cloud Run jobs instead of local docker containers to extract media", false, strconv.ParseBool, flag.Bool)
+	project := envOrFlag("project", "PROJECT", "Google Cloud project to run Cloud Run jobs in", "openfish", parseString, flag.String)
+	location := envOrFlag("location", "LOCATION", "Google Cloud location to run Cloud Run jobs in", "australia-southeast1", parseString, flag.String)
+	trashRetention := envOrFlag("trash-retention", "TRASH_RETENTION", "How long deleted items are kept before they are purged", 30*24*time.Hour, time.ParseDuration, flag.Duration)
 
 	flag.Parse()
 
@@ -216,6 +289,23 @@ func main() {
 		panic(err.Error())
 	}
 
+	// Job runner setup.
+	if *useCloudRun {
+		globals.InitJobRunner(jobrunner.NewCloudRunJobRunner(*project, *location))
+	} else {
+		// Jobs run in containers, so local datastore and storage directories are mounted for them to use.
+		var volumes, args []string
+		if *useFilestore {
+			volumes = append(volumes, mountPath("store"))
+			args = append(args, "--filestore")
+		}
+		if !*useCloudStorage {
+			volumes = append(volumes, mountPath("openfish-media"))
+			args = append(args, "--local-storage")
+		}
+		globals.InitJobRunner(jobrunner.NewDockerJobRunner(volumes, args...))
+	}
+
 	// Create app.
 	app := fiber.New(fiber.Config{ErrorHandler: api.ErrorHandler})
 
@@ -224,7 +314,8 @@ func main() {
 
 	// CORS middleware.
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
+		AllowOrigins:  "*",
+		ExposeHeaders: fiber.HeaderETag,
 	}))
 
 	ctx := context.Background()
@@ -246,6 +337,20 @@ func main() {
 	// Register routes.
 	registerAPIRoutes(app)
 
+	// Purge old items from the trash.
+	go func() {
+		for range time.Tick(time.Hour) {
+			n, err := services.PurgeTrash(time.Now().Add(-*trashRetention))
+			if err != nil {
+				log.Printf("failed to purge trash: %v", err)
+				continue
+			}
+			if n > 0 {
+				fmt.Printf("purged %d items from trash\n", n)
+			}
+		}
+	}()
+
 	// Start web server.
 	listenOn := fmt.Sprintf(":%d", *port)
 	fmt.Printf("starting web server on %s\n", listenOn)
@@ -0,0 +1,122 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+
+	"github.com/ausocean/cloud/datastore"
+)
+
+// ActivityPattern is a histogram of when a species was sighted, in the local time of the video stream.
+// Hours has a count for each hour of the day from midnight, Months has a count for each month from
+// January, and Day and Night count sightings while the sun was above and below the horizon.
+type ActivityPattern struct {
+	Species SpeciesSummary `json:"species"`
+	Total   int            `json:"total" example:"42"`
+	Hours   [24]int        `json:"hours"`
+	Months  [12]int        `json:"months"`
+	Day     int            `json:"day" example:"30"`
+	Night   int            `json:"night" example:"12"`
+}
+
+// GetActivityPatterns computes when each species was sighted in the annotations matching the filter,
+// sorted by species ID. Each annotation is a sighting of its consensus species when it starts.
+// Local time uses the time zone of the video stream, and day or night uses the location of its capture source.
+func GetActivityPatterns(filter AnnotationFilter) ([]ActivityPattern, error) {
+	annotations, err := GetAnnotations(0, 0, nil, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	streams := make(map[int64]*VideoStream)
+	sources := make(map[int64]*CaptureSource)
+	patterns := make(map[int64]*ActivityPattern)
+	for _, a := range annotations {
+		if a.Consensus.SpeciesID == nil || len(a.KeyPoints) == 0 {
+			continue
+		}
+
+		// Get the video stream and capture source, caching them as they are shared by many annotations.
+		vs, ok := streams[a.VideostreamID]
+		if !ok {
+			vs, err = GetVideoStreamByID(a.VideostreamID)
+			if err != nil {
+				return nil, err
+			}
+			streams[a.VideostreamID] = vs
+		}
+		cs, ok := sources[vs.CaptureSource]
+		if !ok {
+			cs, err = GetCaptureSourceByID(vs.CaptureSource)
+			if err != nil {
+				return nil, err
+			}
+			sources[vs.CaptureSource] = cs
+		}
+
+		p, ok := patterns[*a.Consensus.SpeciesID]
+		if !ok {
+			species, err := GetSpeciesByID(*a.Consensus.SpeciesID)
+			if errors.Is(err, datastore.ErrNoSuchEntity) {
+				continue // Skip species that have been deleted.
+			} else if err != nil {
+				return nil, err
+			}
+			p = &ActivityPattern{Species: species.ToSummary()}
+			patterns[*a.Consensus.SpeciesID] = p
+		}
+
+		t := vs.TimeAt(a.KeyPoints[0].Time)
+		local := t.In(&vs.TimeZone.Location)
+		p.Total++
+		p.Hours[local.Hour()]++
+		p.Months[local.Month()-1]++
+		if cs.Location.IsDaytime(t) {
+			p.Day++
+		} else {
+			p.Night++
+		}
+	}
+
+	results := make([]ActivityPattern, 0, len(patterns))
+	for _, p := range patterns {
+		results = append(results, *p)
+	}
+	slices.SortFunc(results, func(a, b ActivityPattern) int { return cmp.Compare(a.Species.ID, b.Species.ID) })
+
+	return results, nil
+}
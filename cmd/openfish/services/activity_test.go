@@ -0,0 +1,77 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+)
+
+func TestGetActivityPatterns(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	squid := createTestSpecies()
+	user := createTestUserWithRole("coral.fischer@example.com", role.Annotator)
+
+	// The video stream starts at 6:30pm in Adelaide, before sunset at the capture source. The
+	// second sighting is at 9pm, after sunset.
+	box := keypoint.BoundingBox{X1: 10, Y1: 10, X2: 20, Y2: 20}
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "00:00:01.000", "00:00:02.000")
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "02:30:00.000", "02:30:01.000")
+
+	patterns, err := services.GetActivityPatterns(services.AnnotationFilter{VideoStreamID: &vs.ID})
+	if err != nil {
+		t.Fatalf("Could not get activity patterns %s", err)
+	}
+	if len(patterns) != 1 {
+		t.Fatalf("Expected activity pattern for 1 species, got %d", len(patterns))
+	}
+
+	p := patterns[0]
+	if p.Species.ID != squid.ID || p.Total != 2 {
+		t.Errorf("Expected 2 sightings of squid, got %d", p.Total)
+	}
+	if p.Hours[18] != 1 || p.Hours[21] != 1 {
+		t.Errorf("Expected sightings at 6pm and 9pm local time, got %v", p.Hours)
+	}
+	if p.Months[0] != 2 {
+		t.Errorf("Expected sightings in January, got %v", p.Months)
+	}
+	if p.Day != 1 || p.Night != 1 {
+		t.Errorf("Expected 1 sighting during the day and 1 at night, got %d and %d", p.Day, p.Night)
+	}
+}
@@ -0,0 +1,330 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ausocean/cloud/datastore"
+	"github.com/ausocean/openfish/cmd/openfish/entities"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+)
+
+// ErrRevisionNotFound is returned when restoring a revision that does not exist.
+var ErrRevisionNotFound = errors.New("annotation revision not found")
+
+// AnnotationChange is the kind of change made to an annotation.
+type AnnotationChange string
+
+const (
+	ChangeCreated               AnnotationChange = "created"
+	ChangeKeyPoints             AnnotationChange = "keypoints_updated"
+	ChangeAttributes            AnnotationChange = "attributes_updated"
+	ChangeIdentificationAdded   AnnotationChange = "identification_added"
+	ChangeIdentificationRemoved AnnotationChange = "identification_removed"
+	ChangeDeleted               AnnotationChange = "deleted"
+	ChangeRestored              AnnotationChange = "restored"
+	ChangeSubmitted             AnnotationChange = "submitted"
+	ChangeVerified              AnnotationChange = "verified"
+	ChangeRejected              AnnotationChange = "rejected"
+	ChangeMerged                AnnotationChange = "merged"
+)
+
+// AnnotationRevision is a change made to an annotation, along with the contents
+// of the annotation after the change was made.
+type AnnotationRevision struct {
+	AnnotationID int64
+	Version      int64
+	Change       AnnotationChange
+	SpeciesID    *int64
+	ChangedByID  int64
+	ChangedAt    time.Time
+	AnnotationContents
+}
+
+// AnnotationRevisionWithJoins is an annotation revision with its foreign key fields joined with
+// their respective entities.
+type AnnotationRevisionWithJoins struct {
+	Version         int64               `json:"version" example:"2"`
+	Change          AnnotationChange    `json:"change" swaggertype:"string" enums:"created,keypoints_updated,attributes_updated,identification_added,identification_removed,deleted,restored,submitted,verified,rejected,merged" example:"identification_added"`
+	SpeciesID       *int64              `json:"species_id,omitempty" example:"1234567890"`
+	ChangedBy       PublicUser          `json:"changed_by"`
+	ChangedAt       time.Time           `json:"changed_at" example:"2023-05-25T08:00:00Z"`
+	KeyPoints       []keypoint.KeyPoint `json:"keypoints"`
+	Identifications []Identification    `json:"identifications"`
+}
+
+// revisionInfo describes who made a change to an annotation and what kind of change it was.
+type revisionInfo struct {
+	change    AnnotationChange
+	userID    int64
+	speciesID *int64
+}
+
+// JoinFields joins the foreign key fields of an annotation revision with their respective entities.
+func (r *AnnotationRevision) JoinFields() (*AnnotationRevisionWithJoins, error) {
+	// Users who have since been deleted are shown by their ID only.
+	changedBy := PublicUser{ID: r.ChangedByID}
+	user, err := GetUserByID(r.ChangedByID)
+	if err == nil {
+		changedBy = user.ToPublicUser()
+	} else if !errors.Is(err, datastore.ErrNoSuchEntity) {
+		return nil, err
+	}
+
+	identifications, err := joinIdentifications(r.Identifications)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AnnotationRevisionWithJoins{
+		Version:         r.Version,
+		Change:          r.Change,
+		SpeciesID:       r.SpeciesID,
+		ChangedBy:       changedBy,
+		ChangedAt:       r.ChangedAt,
+		KeyPoints:       r.KeyPoints,
+		Identifications: identifications,
+	}, nil
+}
+
+// AnnotationRevisionFromEntity converts an entities.AnnotationRevision to an AnnotationRevision.
+func AnnotationRevisionFromEntity(e entities.AnnotationRevision) (*AnnotationRevision, error) {
+	var snapshot entities.Annotation
+	err := json.Unmarshal(e.Snapshot, &snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode snapshot of annotation %d version %d: %w", e.AnnotationID, e.Version, err)
+	}
+
+	return &AnnotationRevision{
+		AnnotationID:       e.AnnotationID,
+		Version:            e.Version,
+		Change:             AnnotationChange(e.Change),
+		SpeciesID:          e.SpeciesID,
+		ChangedByID:        e.ChangedBy,
+		ChangedAt:          e.ChangedAt,
+		AnnotationContents: AnnotationContentsFromEntity(snapshot),
+	}, nil
+}
+
+// setLastChange sets the last change of an annotation entity, to be written with the annotation.
+func setLastChange(e *entities.Annotation, rev revisionInfo) {
+	e.LastChange = string(rev.change)
+	e.LastChangeSpeciesID = rev.speciesID
+	e.LastChangedBy = rev.userID
+	e.LastChangedAt = time.Now()
+}
+
+// revisionKey returns the key of the revision of an annotation at a version.
+func revisionKey(id int64, version int64) *datastore.Key {
+	return globals.GetStore().NameKey(entities.ANNOTATION_REVISION_KIND, fmt.Sprintf("%d.%d", id, version))
+}
+
+// newRevisionEntity returns a revision of the last change of an annotation entity, with a snapshot of the entity.
+func newRevisionEntity(id int64, e entities.Annotation) (*entities.AnnotationRevision, error) {
+	snapshot, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.AnnotationRevision{
+		AnnotationID: id,
+		Version:      e.Version,
+		Change:       e.LastChange,
+		SpeciesID:    e.LastChangeSpeciesID,
+		ChangedBy:    e.LastChangedBy,
+		ChangedAt:    e.LastChangedAt,
+		Snapshot:     snapshot,
+	}, nil
+}
+
+// recordRevision records the last change of an annotation, storing a snapshot of the annotation entity after the
+// change. The change is set on the entity with setLastChange, and is written with the annotation. If recording the
+// revision fails, it is added to the annotation's history from the annotation when the history is read.
+func recordRevision(id int64, e entities.Annotation) error {
+	r, err := newRevisionEntity(id, e)
+	if err != nil {
+		return err
+	}
+
+	store := globals.GetStore()
+	_, err = store.Put(context.Background(), revisionKey(id, e.Version), r)
+	return err
+}
+
+// getRevisionEntities gets the revision entities of an annotation, ordered by version.
+func getRevisionEntities(id int64) ([]entities.AnnotationRevision, error) {
+	store := globals.GetStore()
+	query := store.NewQuery(entities.ANNOTATION_REVISION_KIND, false)
+	query.FilterField("AnnotationID", "=", id)
+
+	var ents []entities.AnnotationRevision
+	_, err := store.GetAll(context.Background(), query, &ents)
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(ents, func(a, b entities.AnnotationRevision) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	// Add the last change of the annotation if its revision was not recorded.
+	var current entities.Annotation
+	err = store.Get(context.Background(), store.IDKey(entities.ANNOTATION_KIND, id), &current)
+	if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return ents, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if current.LastChange != "" && (len(ents) == 0 || ents[len(ents)-1].Version < current.Version) {
+		r, err := newRevisionEntity(id, current)
+		if err != nil {
+			return nil, err
+		}
+		ents = append(ents, *r)
+	}
+
+	return ents, nil
+}
+
+// deleteRevisions deletes the revisions of an annotation, so that an annotation that was purged from the
+// trash cannot be restored from its history.
+func deleteRevisions(id int64) error {
+	store := globals.GetStore()
+	query := store.NewQuery(entities.ANNOTATION_REVISION_KIND, true)
+	query.FilterField("AnnotationID", "=", id)
+	keys, err := store.GetAll(context.Background(), query, nil)
+	if err != nil {
+		return err
+	}
+	_, err = datastore.DeleteMulti(context.Background(), store, keys)
+	return err
+}
+
+// GetAnnotationHistory gets the revisions of an annotation, oldest first.
+func GetAnnotationHistory(id int64) ([]AnnotationRevision, error) {
+	ents, err := getRevisionEntities(id)
+	if err != nil {
+		return []AnnotationRevision{}, err
+	}
+
+	revisions := make([]AnnotationRevision, len(ents))
+	for i := range ents {
+		r, err := AnnotationRevisionFromEntity(ents[i])
+		if err != nil {
+			return []AnnotationRevision{}, err
+		}
+		revisions[i] = *r
+	}
+
+	return revisions, nil
+}
+
+// RestoreAnnotation restores an annotation to the contents it had at the given version.
+// The annotation keeps its current review status, so restoring a verified revision does not verify the annotation,
+// and a verified annotation goes back for review if its keypoints or consensus species change.
+// If the annotation has since been deleted, it is recreated with the same ID. Returns ErrRestoreConflict
+// if a deleted annotation's video stream no longer exists. An annotation that has been purged from the trash
+// has no revisions, so it cannot be restored.
+func RestoreAnnotation(id int64, version int64, userID int64) error {
+	ents, err := getRevisionEntities(id)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(ents, func(e entities.AnnotationRevision) bool { return e.Version == version })
+	if i == -1 {
+		return fmt.Errorf("%w: annotation %d has no version %d", ErrRevisionNotFound, id, version)
+	}
+
+	var snapshot entities.Annotation
+	err = json.Unmarshal(ents[i].Snapshot, &snapshot)
+	if err != nil {
+		return err
+	}
+	rev := revisionInfo{change: ChangeRestored, userID: userID}
+
+	// Restore the contents of an existing annotation.
+	err = modifyAnnotation(id, nil, rev, func(a *AnnotationContents) error {
+		review := a.Review
+		*a = AnnotationContentsFromEntity(snapshot)
+		a.Review = review
+		return nil
+	})
+	if !errors.Is(err, datastore.ErrNoSuchEntity) {
+		return err
+	}
+
+	// The annotation has been deleted, so recreate it with the review status of its last revision.
+	if !VideoStreamExists(snapshot.VideoStreamID) {
+		return fmt.Errorf("%w: video stream %d does not exist", ErrRestoreConflict, snapshot.VideoStreamID)
+	}
+	var latest entities.Annotation
+	err = json.Unmarshal(ents[len(ents)-1].Snapshot, &latest)
+	if err != nil {
+		return err
+	}
+	before := AnnotationContentsFromEntity(latest)
+	after := AnnotationContentsFromEntity(snapshot)
+	after.Review = before.Review
+	unverify(&before, &after)
+	restored := after.ToEntity()
+	restored.Version = ents[len(ents)-1].Version + 1
+	setLastChange(&restored, rev)
+	store := globals.GetStore()
+	key := store.IDKey(entities.ANNOTATION_KIND, id)
+	_, err = store.Put(context.Background(), key, &restored)
+	if err != nil {
+		return err
+	}
+
+	// Remove the annotation from the trash, if it is still there.
+	tkey := trashKey(entities.ANNOTATION_KIND, id)
+	err = store.Get(context.Background(), tkey, &entities.TrashItem{})
+	if err == nil {
+		err = store.Delete(context.Background(), tkey)
+	}
+	if err != nil && !errors.Is(err, datastore.ErrNoSuchEntity) {
+		return err
+	}
+
+	return recordRevision(id, restored)
+}
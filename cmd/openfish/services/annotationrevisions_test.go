@@ -0,0 +1,241 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/entities"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+func TestGetAnnotationHistory(t *testing.T) {
+	setup()
+	annotation := createTestAnnotation()
+	uid, _ := services.CreateUser(services.UserContents{
+		Email:       "sandy.whiting@example.com",
+		DisplayName: "Sandy Whiting",
+		Role:        role.Annotator,
+	})
+	sp := createTestSpecies()
+
+	services.AddIdentification(annotation.ID, uid, sp.ID)
+	services.DeleteIdentification(annotation.ID, uid, sp.ID)
+
+	history, err := services.GetAnnotationHistory(annotation.ID)
+	if err != nil {
+		t.Errorf("Could not get annotation history %s", err)
+	}
+
+	expected := []services.AnnotationChange{
+		services.ChangeCreated,
+		services.ChangeIdentificationAdded,
+		services.ChangeIdentificationRemoved,
+	}
+	if len(history) != len(expected) {
+		t.Fatalf("Expected %d revisions, got %d", len(expected), len(history))
+	}
+	for i, rev := range history {
+		if rev.Change != expected[i] {
+			t.Errorf("Expected revision %d to be %s, got %s", i, expected[i], rev.Change)
+		}
+		if rev.Version != int64(i+1) {
+			t.Errorf("Expected revision %d to have version %d, got %d", i, i+1, rev.Version)
+		}
+	}
+	if history[1].ChangedByID != uid || *history[1].SpeciesID != sp.ID {
+		t.Errorf("Expected revision to record who added the identification and which species")
+	}
+	if len(history[1].Identifications) != 2 {
+		t.Errorf("Expected snapshot to contain the added identification")
+	}
+}
+
+func TestJoinRevisionWithDeletedUser(t *testing.T) {
+	setup()
+	annotation := createTestAnnotation()
+	uid := createTestUserWithRole("deleted.annotator", role.Annotator)
+	sp := createTestSpecies()
+	services.AddIdentification(annotation.ID, uid, sp.ID)
+	services.DeleteUser(uid)
+
+	history, err := services.GetAnnotationHistory(annotation.ID)
+	if err != nil {
+		t.Fatalf("Could not get annotation history %s", err)
+	}
+	joined, err := history[len(history)-1].JoinFields()
+	if err != nil {
+		t.Fatalf("Could not join revision changed by deleted user %s", err)
+	}
+	if joined.ChangedBy.ID != uid || joined.ChangedBy.DisplayName != "" {
+		t.Errorf("Expected deleted user to be shown by ID only, got %+v", joined.ChangedBy)
+	}
+}
+
+func TestGetAnnotationHistoryWithUnrecordedRevision(t *testing.T) {
+	setup()
+	annotation := createTestAnnotation()
+	sp := createTestSpecies()
+	services.AddIdentification(annotation.ID, annotation.CreatedByID, sp.ID)
+
+	// Remove the revision, as if writing it had failed after the annotation was updated.
+	store := globals.GetStore()
+	key := store.NameKey(entities.ANNOTATION_REVISION_KIND, fmt.Sprintf("%d.%d", annotation.ID, 2))
+	err := store.Delete(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Could not delete revision %s", err)
+	}
+
+	history, err := services.GetAnnotationHistory(annotation.ID)
+	if err != nil {
+		t.Fatalf("Could not get annotation history %s", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 revisions, got %d", len(history))
+	}
+	last := history[1]
+	if last.Version != 2 || last.Change != services.ChangeIdentificationAdded || *last.SpeciesID != sp.ID || last.ChangedByID != annotation.CreatedByID {
+		t.Errorf("Expected last revision to be recovered from the annotation, got %+v", last)
+	}
+}
+
+func TestRestoreAnnotation(t *testing.T) {
+	setup()
+	original := createTestAnnotation()
+
+	keypoints := []keypoint.KeyPoint{
+		{
+			BoundingBox: keypoint.BoundingBox{X1: 0, X2: 10, Y1: 0, Y2: 10},
+			Time:        videotime.UncheckedParse("00:00:05.000"),
+		},
+	}
+	services.UpdateAnnotation(original.ID, original.Version, original.CreatedByID, services.PartialAnnotationContents{KeyPoints: &keypoints})
+
+	err := services.RestoreAnnotation(original.ID, original.Version, original.CreatedByID)
+	if err != nil {
+		t.Errorf("Could not restore annotation %s", err)
+	}
+
+	restored, _ := services.GetAnnotationByID(original.ID)
+	if !reflect.DeepEqual(original.KeyPoints, restored.KeyPoints) {
+		t.Errorf("Expected keypoints to be restored, expected %v, got %v", original.KeyPoints, restored.KeyPoints)
+	}
+	if restored.Version != original.Version+2 {
+		t.Errorf("Expected restore to create a new version, expected %d, got %d", original.Version+2, restored.Version)
+	}
+}
+
+func TestRestoreDeletedAnnotation(t *testing.T) {
+	setup()
+	original := createTestAnnotation()
+	services.DeleteAnnotation(original.ID, original.CreatedByID)
+
+	err := services.RestoreAnnotation(original.ID, original.Version, original.CreatedByID)
+	if err != nil {
+		t.Errorf("Could not restore annotation %s", err)
+	}
+
+	restored, err := services.GetAnnotationByID(original.ID)
+	if err != nil {
+		t.Fatalf("Expected deleted annotation to be recreated %s", err)
+	}
+	if !reflect.DeepEqual(original.AnnotationContents, restored.AnnotationContents) {
+		t.Errorf("Annotation does not match original, expected %v, got %v", original.AnnotationContents, restored.AnnotationContents)
+	}
+
+	history, _ := services.GetAnnotationHistory(original.ID)
+	last := history[len(history)-1]
+	if last.Change != services.ChangeRestored || last.Version != restored.Version {
+		t.Errorf("Expected restore to be recorded in history")
+	}
+}
+
+func TestRestoreVerifiedRevisionKeepsReview(t *testing.T) {
+	setup()
+	a := createTestVerifiedAnnotation()
+	verified, _ := services.GetAnnotationByID(a.ID)
+
+	// Move the annotation and move it back, so it needs review again.
+	keypoints := []keypoint.KeyPoint{
+		{
+			BoundingBox: keypoint.BoundingBox{X1: 0, X2: 10, Y1: 0, Y2: 10},
+			Time:        videotime.UncheckedParse("00:00:05.000"),
+		},
+	}
+	services.UpdateAnnotation(a.ID, verified.Version, a.CreatedByID, services.PartialAnnotationContents{KeyPoints: &keypoints})
+	services.UpdateAnnotation(a.ID, verified.Version+1, a.CreatedByID, services.PartialAnnotationContents{KeyPoints: &verified.KeyPoints})
+
+	err := services.RestoreAnnotation(a.ID, verified.Version, a.CreatedByID)
+	if err != nil {
+		t.Fatalf("Could not restore annotation %s", err)
+	}
+
+	restored, _ := services.GetAnnotationByID(a.ID)
+	if restored.Review.Status != services.Submitted {
+		t.Errorf("Expected restored annotation to still need review, got %s", restored.Review.Status)
+	}
+}
+
+func TestRestoreDeletedAnnotationWithoutVideoStream(t *testing.T) {
+	setup()
+	original := createTestAnnotation()
+	services.DeleteAnnotation(original.ID, original.CreatedByID)
+	services.DeleteVideoStream(original.VideostreamID, original.CreatedByID, false)
+
+	err := services.RestoreAnnotation(original.ID, original.Version, original.CreatedByID)
+	if !errors.Is(err, services.ErrRestoreConflict) {
+		t.Errorf("Expected %v, got %v", services.ErrRestoreConflict, err)
+	}
+	if _, err := services.GetAnnotationByID(original.ID); err == nil {
+		t.Errorf("Expected annotation to stay deleted")
+	}
+}
+
+func TestRestoreNonexistentRevision(t *testing.T) {
+	setup()
+	annotation := createTestAnnotation()
+
+	err := services.RestoreAnnotation(annotation.ID, 100, annotation.CreatedByID)
+	if err == nil {
+		t.Errorf("Did not receive expected error when restoring non-existent revision")
+	}
+}
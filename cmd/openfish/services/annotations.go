@@ -38,14 +38,26 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ausocean/cloud/datastore"
 	"github.com/ausocean/openfish/cmd/openfish/entities"
 	"github.com/ausocean/openfish/cmd/openfish/globals"
 	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/timespan"
 	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
 )
 
+// ErrVersionMismatch is returned when an annotation is updated using a version
+// that is not the latest, i.e. someone else has modified it in the meantime.
+var ErrVersionMismatch = errors.New("annotation has been modified since it was last fetched")
+
+// ErrInvalidKeyPoints is returned when an annotation would be left with an invalid set of keypoints.
+var ErrInvalidKeyPoints = errors.New("invalid keypoints")
+
 // Identification is a species suggestion made by users.
 type Identification struct {
 	Species      SpeciesSummary `json:"species"`
@@ -55,7 +67,8 @@ type Identification struct {
 // Annotation is a bounding box added to a video with one or many identifications.
 // Users can suggest additional identifications for this annotation.
 type Annotation struct {
-	ID int64
+	ID      int64
+	Version int64
 	AnnotationContents
 }
 
@@ -63,16 +76,50 @@ type Annotation struct {
 type AnnotationContents struct {
 	KeyPoints       []keypoint.KeyPoint
 	Identifications map[int64][]int64
+	Consensus       Consensus
+	Review          Review
+	Attributes      map[string]string
 	VideostreamID   int64
 	CreatedByID     int64
 }
 
+// PartialAnnotationContents is for updating an annotation with a partial update (such as a PATCH request).
+// KeyPoints replaces all of the keypoints, AddKeyPoints adds keypoints (replacing any existing keypoint with the same time),
+// and RemoveKeyPoints removes the keypoints at the given times. Attributes sets the value of each attribute, or removes it if null.
+type PartialAnnotationContents struct {
+	KeyPoints       *[]keypoint.KeyPoint  `json:"keypoints,omitempty" validate:"optional"`
+	AddKeyPoints    []keypoint.KeyPoint   `json:"add_keypoints,omitempty" validate:"optional"`
+	RemoveKeyPoints []videotime.VideoTime `json:"remove_keypoints,omitempty" validate:"optional" swaggertype:"array,string" example:"00:00:01.000"`
+	Attributes      map[string]*string    `json:"attributes,omitempty" validate:"optional" swaggertype:"object,string" example:"behaviour:feeding"`
+}
+
+// AnnotationFilter describes the optional filters that can be applied when getting annotations.
+// Nil fields are not filtered on.
+type AnnotationFilter struct {
+	VideoStreamID   *int64             // Annotations on this video stream.
+	CaptureSourceID *int64             // Annotations on video streams produced by this capture source.
+	SpeciesID       *int64             // Annotations with an identification of this species.
+	IdentifiedByID  *int64             // Annotations with an identification made by this user.
+	CreatedByID     *int64             // Annotations created by this user.
+	Consensus       *ConsensusStatus   // Annotations with this consensus status.
+	Review          *ReviewStatus      // Annotations with this review status.
+	Attributes      map[string]string  // Annotations with all of these attribute values.
+	TimeSpan        *timespan.TimeSpan // Annotations starting within this time span of the video.
+	From            *time.Time         // Annotations starting at or after this date and time.
+	To              *time.Time         // Annotations starting at or before this date and time.
+}
+
 // AnnotationWithJoins is an annotation with its foreign key fields joined with
 // their respective entities.
 type AnnotationWithJoins struct {
 	ID              int64               `json:"id" example:"1234567890"`
+	Type            AnnotationType      `json:"type" example:"box"`
+	Version         int64               `json:"version" example:"1"`
 	KeyPoints       []keypoint.KeyPoint `json:"keypoints"`
 	Identifications []Identification    `json:"identifications"`
+	Consensus       ConsensusWithJoins  `json:"consensus"`
+	Review          ReviewWithJoins     `json:"review"`
+	Attributes      map[string]string   `json:"attributes" example:"behaviour:feeding"`
 	Videostream     VideoStreamSummary  `json:"videostream"`
 	CreatedBy       PublicUser          `json:"created_by"`
 	Start           videotime.VideoTime `json:"start" swaggertype:"string" example:"01:56:05.500"`
@@ -96,17 +143,58 @@ func (a *Annotation) JoinFields() (*AnnotationWithJoins, error) {
 	}
 
 	// Get identifications.
-	identifications := make([]Identification, 0, len(a.Identifications))
-	for speciesID, userIDs := range a.Identifications {
+	identifications, err := joinIdentifications(a.Identifications)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get consensus.
+	consensus, err := a.Consensus.JoinFields()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get review.
+	review, err := a.Review.JoinFields()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AnnotationWithJoins{
+		ID:              a.ID,
+		Type:            BoxAnnotationType,
+		Version:         a.Version,
+		KeyPoints:       a.KeyPoints,
+		Videostream:     videostream.ToSummary(),
+		Identifications: identifications,
+		Consensus:       *consensus,
+		Review:          *review,
+		Attributes:      a.Attributes,
+		CreatedBy:       user.ToPublicUser(),
+		Start:           a.KeyPoints[0].Time,
+		End:             a.KeyPoints[len(a.KeyPoints)-1].Time,
+		Duration:        a.KeyPoints[len(a.KeyPoints)-1].Time.Int() - a.KeyPoints[0].Time.Int(),
+	}, nil
+}
+
+// joinIdentifications joins the species and user IDs of identifications with their respective entities.
+func joinIdentifications(ids map[int64][]int64) ([]Identification, error) {
+	identifications := make([]Identification, 0, len(ids))
+	for speciesID, userIDs := range ids {
 		species, err := GetSpeciesByID(speciesID)
-		if err != nil {
+		if errors.Is(err, datastore.ErrNoSuchEntity) {
+			continue // Identifications of deleted species are skipped.
+		} else if err != nil {
 			return nil, err
 		}
 
 		users := make([]PublicUser, 0, len(userIDs))
 		for _, userID := range userIDs {
 			user, err := GetUserByID(userID)
-			if err != nil {
+			if errors.Is(err, datastore.ErrNoSuchEntity) {
+				users = append(users, PublicUser{ID: userID}) // Deleted users are shown by their ID only.
+				continue
+			} else if err != nil {
 				return nil, err
 			}
 			users = append(users, user.ToPublicUser())
@@ -116,17 +204,7 @@ func (a *Annotation) JoinFields() (*AnnotationWithJoins, error) {
 			IdentifiedBy: users,
 		})
 	}
-
-	return &AnnotationWithJoins{
-		ID:              a.ID,
-		KeyPoints:       a.KeyPoints,
-		Videostream:     videostream.ToSummary(),
-		Identifications: identifications,
-		CreatedBy:       user.ToPublicUser(),
-		Start:           a.KeyPoints[0].Time,
-		End:             a.KeyPoints[len(a.KeyPoints)-1].Time,
-		Duration:        a.KeyPoints[len(a.KeyPoints)-1].Time.Int() - a.KeyPoints[0].Time.Int(),
-	}, nil
+	return identifications, nil
 }
 
 // ToEntity converts an AnnotationContents struct to an entities.Annotation struct.
@@ -136,14 +214,17 @@ func (a *AnnotationContents) ToEntity() entities.Annotation {
 	kp := make([]struct {
 		Time string
 		keypoint.BoundingBox
+		Polygon string `datastore:",noindex"`
 	}, len(a.KeyPoints))
 	for i := range a.KeyPoints {
 		kp[i] = struct {
 			Time string
 			keypoint.BoundingBox
+			Polygon string `datastore:",noindex"`
 		}{
 			Time:        a.KeyPoints[i].Time.String(),
 			BoundingBox: a.KeyPoints[i].BoundingBox,
+			Polygon:     pointsToEntity(a.KeyPoints[i].Polygon),
 		}
 	}
 
@@ -157,6 +238,22 @@ func (a *AnnotationContents) ToEntity() entities.Annotation {
 		}
 	}
 
+	// Convert consensus into storable format.
+	var consensusSpecies int64
+	if a.Consensus.SpeciesID != nil {
+		consensusSpecies = *a.Consensus.SpeciesID
+	}
+
+	// Convert review into storable format.
+	var submittedAt time.Time
+	if a.Review.SubmittedAt != nil {
+		submittedAt = *a.Review.SubmittedAt
+	}
+	var reviewedBy int64
+	if a.Review.ReviewedByID != nil {
+		reviewedBy = *a.Review.ReviewedByID
+	}
+
 	return entities.Annotation{
 		VideoStreamID:           a.VideostreamID,
 		Start:                   a.KeyPoints[0].Time.Int(),
@@ -164,6 +261,14 @@ func (a *AnnotationContents) ToEntity() entities.Annotation {
 		Keypoints:               kp,
 		IdentificationUserID:    users,
 		IdentificationSpeciesID: species,
+		ConsensusSpeciesID:      consensusSpecies,
+		ConsensusStatus:         string(a.Consensus.Status),
+		ConsensusAgreement:      a.Consensus.Agreement,
+		ReviewStatus:            string(a.Review.Status),
+		SubmittedAt:             submittedAt,
+		ReviewedBy:              reviewedBy,
+		ReviewReason:            a.Review.Reason,
+		Attributes:              attributesToEntity(a.Attributes),
 	}
 }
 
@@ -185,18 +290,72 @@ func AnnotationContentsFromEntity(e entities.Annotation) AnnotationContents {
 	for i, k := range e.Keypoints {
 		keypoints[i] = keypoint.KeyPoint{
 			BoundingBox: k.BoundingBox,
+			Polygon:     pointsFromEntity(k.Polygon),
 			Time:        videotime.UncheckedParse(k.Time),
 		}
 	}
 
+	consensus := Consensus{
+		Agreement: e.ConsensusAgreement,
+		Status:    ConsensusStatus(e.ConsensusStatus),
+	}
+	if e.ConsensusSpeciesID != 0 {
+		consensus.SpeciesID = &e.ConsensusSpeciesID
+	}
+	if consensus.Status == "" {
+		consensus.Status = NeedsID
+	}
+
+	review := Review{
+		Status: ReviewStatus(e.ReviewStatus),
+		Reason: e.ReviewReason,
+	}
+	if !e.SubmittedAt.IsZero() {
+		review.SubmittedAt = &e.SubmittedAt
+	}
+	if e.ReviewedBy != 0 {
+		review.ReviewedByID = &e.ReviewedBy
+	}
+	if review.Status == "" {
+		review.Status = Draft
+	}
+
 	return AnnotationContents{
 		KeyPoints:       keypoints,
 		Identifications: identifications,
+		Consensus:       consensus,
+		Review:          review,
+		Attributes:      attributesFromEntity(e.Attributes),
 		VideostreamID:   e.VideoStreamID,
 		CreatedByID:     e.CreatedBy,
 	}
 }
 
+// pointsToEntity converts points to space separated "x,y" pairs for storage in the datastore.
+func pointsToEntity(points []keypoint.Point) string {
+	vertices := make([]string, len(points))
+	for i, v := range points {
+		vertices[i] = strconv.FormatFloat(float64(v.X), 'f', -1, 32) + "," + strconv.FormatFloat(float64(v.Y), 'f', -1, 32)
+	}
+	return strings.Join(vertices, " ")
+}
+
+// pointsFromEntity converts space separated "x,y" pairs from the datastore to points.
+func pointsFromEntity(s string) []keypoint.Point {
+	if s == "" {
+		return nil
+	}
+	fields := strings.Fields(s)
+	points := make([]keypoint.Point, len(fields))
+	for i, f := range fields {
+		x, y, _ := strings.Cut(f, ",")
+		px, _ := strconv.ParseFloat(x, 32)
+		py, _ := strconv.ParseFloat(y, 32)
+		points[i] = keypoint.Point{X: float32(px), Y: float32(py)}
+	}
+	return points
+}
+
 // GetAnnotationByID returns an annotation by ID.
 func GetAnnotationByID(id int64) (*Annotation, error) {
 
@@ -210,6 +369,7 @@ func GetAnnotationByID(id int64) (*Annotation, error) {
 
 	annotation := Annotation{
 		ID:                 id,
+		Version:            e.Version,
 		AnnotationContents: AnnotationContentsFromEntity(e),
 	}
 
@@ -225,37 +385,164 @@ func AnnotationExists(id int64) bool {
 	return err == nil
 }
 
-// GetAnnotations gets a list of annotations, filtering by videostream if specified.
-func GetAnnotations(limit int, offset int, order *string, videostream *int64) ([]Annotation, error) {
+// GetAnnotations gets a list of annotations, applying the given filters.
+func GetAnnotations(limit int, offset int, order *string, filter AnnotationFilter) ([]Annotation, error) {
+	// Attribute values are normalised so they match the stored values.
+	if len(filter.Attributes) != 0 {
+		schema, err := GetAttributeSchema()
+		if err != nil {
+			return []Annotation{}, err
+		}
+		filter.Attributes, err = schema.Normalise(filter.Attributes)
+		if err != nil {
+			return []Annotation{}, err
+		}
+	}
+
+	// Capture source and date filters need to be joined through video streams.
+	if filter.CaptureSourceID != nil || filter.From != nil || filter.To != nil {
+		return getAnnotationsByVideoStreams(limit, offset, order, filter)
+	}
+	return queryAnnotations(limit, offset, order, filter)
+}
+
+// queryAnnotations queries the datastore for annotations, applying all filters
+// except the ones on capture source and date. A limit of zero returns all annotations.
+func queryAnnotations(limit int, offset int, order *string, filter AnnotationFilter) ([]Annotation, error) {
 	store := globals.GetStore()
 	query := store.NewQuery(entities.ANNOTATION_KIND, false)
 
 	// Apply filters.
-	if videostream != nil {
-		query.FilterField("VideoStreamID", "=", *videostream)
+	if filter.VideoStreamID != nil {
+		query.FilterField("VideoStreamID", "=", *filter.VideoStreamID)
+	}
+	if filter.SpeciesID != nil {
+		query.FilterField("IdentificationSpeciesID", "=", *filter.SpeciesID)
+	}
+	if filter.IdentifiedByID != nil {
+		query.FilterField("IdentificationUserID", "=", *filter.IdentifiedByID)
+	}
+	if filter.CreatedByID != nil {
+		query.FilterField("CreatedBy", "=", *filter.CreatedByID)
+	}
+	// Annotations stored without a consensus or review status are read as needing an
+	// ID or as drafts, so they are filtered after being read instead.
+	filterRead := (filter.Consensus != nil && *filter.Consensus == NeedsID) || (filter.Review != nil && *filter.Review == Draft)
+	if filter.Consensus != nil && *filter.Consensus != NeedsID {
+		query.FilterField("ConsensusStatus", "=", string(*filter.Consensus))
+	}
+	if filter.Review != nil && *filter.Review != Draft {
+		query.FilterField("ReviewStatus", "=", string(*filter.Review))
+	}
+	for name, value := range filter.Attributes {
+		query.FilterField("Attributes", "=", attributeFilterValue(name, value))
+	}
+	if filter.TimeSpan != nil {
+		query.FilterField("StartTime", ">=", filter.TimeSpan.Start.Int())
+		query.FilterField("StartTime", "<=", filter.TimeSpan.End.Int())
 	}
 
 	// Apply pagination and ordering.
-	query.Limit(limit)
-	query.Offset(offset)
+	if limit > 0 && !filterRead {
+		query.Limit(limit)
+	}
+	if !filterRead {
+		query.Offset(offset)
+	}
 	if order != nil {
 		query.Order(*order)
 	}
 
 	// Fetch entities from the datastore.
+	// The IDs are taken from the entities' keys, as the returned keys do not
+	// line up with the entities when filtering a file store.
 	var ents []entities.Annotation
-	keys, err := store.GetAll(context.Background(), query, &ents)
+	_, err := store.GetAll(context.Background(), query, &ents)
 	if err != nil {
 		return []Annotation{}, err
 	}
 
 	// Convert entities.
-	annotations := make([]Annotation, len(ents))
+	annotations := make([]Annotation, 0, len(ents))
 	for i := range ents {
-		annotations[i] = Annotation{
-			ID:                 keys[i].ID,
+		a := Annotation{
+			ID:                 ents[i].Key.ID,
+			Version:            ents[i].Version,
 			AnnotationContents: AnnotationContentsFromEntity(ents[i]),
 		}
+		if filter.Consensus != nil && a.Consensus.Status != *filter.Consensus {
+			continue
+		}
+		if filter.Review != nil && a.Review.Status != *filter.Review {
+			continue
+		}
+		annotations = append(annotations, a)
+	}
+
+	if filterRead {
+		annotations = annotations[min(offset, len(annotations)):]
+		if limit > 0 {
+			annotations = annotations[:min(limit, len(annotations))]
+		}
+	}
+
+	return annotations, nil
+}
+
+// getAnnotationsByVideoStreams gets annotations by first finding the video streams that match
+// the capture source and date filters, then fetching the annotations on each video stream.
+// Results are ordered by the date and time the annotation starts, or in reverse if order
+// is prefixed by a minus sign.
+func getAnnotationsByVideoStreams(limit int, offset int, order *string, filter AnnotationFilter) ([]Annotation, error) {
+	streams, err := findVideoStreams(filter.CaptureSourceID, filter.From, filter.To)
+	if err != nil {
+		return []Annotation{}, err
+	}
+
+	type result struct {
+		annotation Annotation
+		start      time.Time
+	}
+	var results []result
+	for _, vs := range streams {
+		if filter.VideoStreamID != nil && *filter.VideoStreamID != vs.ID {
+			continue
+		}
+
+		f := filter
+		f.VideoStreamID = &vs.ID
+		annotations, err := queryAnnotations(0, 0, nil, f)
+		if err != nil {
+			return []Annotation{}, err
+		}
+
+		for _, a := range annotations {
+			start := vs.TimeAt(a.KeyPoints[0].Time)
+			if filter.From != nil && start.Before(*filter.From) {
+				continue
+			}
+			if filter.To != nil && start.After(*filter.To) {
+				continue
+			}
+			results = append(results, result{a, start})
+		}
+	}
+
+	// Apply ordering.
+	sort.SliceStable(results, func(i, j int) bool { return results[i].start.Before(results[j].start) })
+	if order != nil && strings.HasPrefix(*order, "-") {
+		slices.Reverse(results)
+	}
+
+	// Apply pagination.
+	offset = min(offset, len(results))
+	end := len(results)
+	if limit > 0 {
+		end = min(offset+limit, len(results))
+	}
+	annotations := make([]Annotation, 0, end-offset)
+	for _, r := range results[offset:end] {
+		annotations = append(annotations, r.annotation)
 	}
 
 	return annotations, nil
@@ -269,82 +556,270 @@ func CreateAnnotation(contents AnnotationContents) (*Annotation, error) {
 		return nil, errors.New("VideoStream does not exist")
 	}
 
+	// Verify attributes match the schema.
+	var schema AttributeSchema
+	if len(contents.Attributes) != 0 {
+		var err error
+		schema, err = GetAttributeSchema()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := prepareAnnotation(&contents, schema)
+	if err != nil {
+		return nil, err
+	}
+	return putAnnotation(contents)
+}
+
+// prepareAnnotation validates and normalises the keypoints and attributes of a new annotation,
+// and sets its consensus and initial review status.
+func prepareAnnotation(contents *AnnotationContents, schema AttributeSchema) error {
+	// Verify keypoints are valid.
+	contents.KeyPoints = keypoint.Normalise(contents.KeyPoints)
+	if err := keypoint.Validate(contents.KeyPoints); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidKeyPoints, err)
+	}
+
+	// Verify attributes match the schema.
+	if len(contents.Attributes) != 0 {
+		var err error
+		contents.Attributes, err = schema.Normalise(contents.Attributes)
+		if err != nil {
+			return err
+		}
+	} else {
+		contents.Attributes = map[string]string{}
+	}
+
+	// Compute the consensus of the initial identifications.
+	consensus, err := ComputeConsensus(contents.Identifications)
+	if err != nil {
+		return err
+	}
+	contents.Consensus = consensus
+
+	// New annotations are drafts until they are submitted for review.
+	contents.Review = Review{Status: Draft}
+	return nil
+}
+
+// putAnnotation adds a prepared annotation to the datastore and records its first revision.
+func putAnnotation(contents AnnotationContents) (*Annotation, error) {
 	// Get a unique ID for the new annotation.
 	store := globals.GetStore()
 	key := store.IncompleteKey(entities.ANNOTATION_KIND)
 	ent := contents.ToEntity()
+	ent.Version = 1
+	setLastChange(&ent, revisionInfo{change: ChangeCreated, userID: contents.CreatedByID})
 	key, err := store.Put(context.Background(), key, &ent)
 	if err != nil {
 		return nil, err
 	}
 
+	// Record the first revision.
+	err = recordRevision(key.ID, ent)
+	if err != nil {
+		return nil, err
+	}
+
 	// Return newly created annotation.
 	created := Annotation{
 		ID:                 key.ID,
+		Version:            ent.Version,
 		AnnotationContents: contents,
 	}
 	return &created, nil
 }
 
-// AddIdentification adds a new species identification to an annotation.
-func AddIdentification(id int64, userID int64, speciesID int64) error {
-	// Update data in the datastore.
+// modifyAnnotation atomically applies fn to the contents of an annotation, recomputes its consensus,
+// increments its version and records the change in the annotation's history. A verified annotation is
+// sent back for review if its keypoints or consensus species change.
+// If version is not nil, the annotation is only modified if its current version matches, otherwise
+// ErrVersionMismatch is returned. If fn returns an error, the annotation is left unchanged.
+func modifyAnnotation(id int64, version *int64, rev revisionInfo, fn func(a *AnnotationContents) error) error {
 	store := globals.GetStore()
+	key := store.IDKey(entities.ANNOTATION_KIND, id)
+	var annotation entities.Annotation
+
+	var fnErr error
+	err := store.Update(context.Background(), key, func(e datastore.Entity) {
+		ent, ok := e.(*entities.Annotation)
+		if !ok {
+			return
+		}
+		if version != nil && ent.Version != *version {
+			fnErr = ErrVersionMismatch
+			return
+		}
+		before := AnnotationContentsFromEntity(*ent)
+		a := AnnotationContentsFromEntity(*ent)
+		fnErr = fn(&a)
+		if fnErr != nil {
+			return
+		}
+		a.Consensus, fnErr = ComputeConsensus(a.Identifications)
+		if fnErr != nil {
+			return
+		}
+		unverify(&before, &a)
+		v := ent.Version
+		*ent = a.ToEntity()
+		ent.Version = v + 1
+		setLastChange(ent, rev)
+	}, &annotation)
+	if err != nil {
+		return err
+	}
+	if fnErr != nil {
+		return fnErr
+	}
 
+	return recordRevision(id, annotation)
+}
+
+// AddIdentification adds a new species identification to an annotation.
+func AddIdentification(id int64, userID int64, speciesID int64) error {
 	// Check if speciesID exists.
 	if !SpeciesExists(speciesID) {
 		return fmt.Errorf("species ID %d does not exist", speciesID)
 	}
 
 	// Proceed with adding identification.
-	key := store.IDKey(entities.ANNOTATION_KIND, id)
-	var annotation entities.Annotation
-
-	return store.Update(context.Background(), key, func(e datastore.Entity) {
-		ent, ok := e.(*entities.Annotation)
-		if ok {
-			a := AnnotationContentsFromEntity(*ent)
-			ids := a.Identifications[speciesID]
-			// Add an identification only if the user hasn't already identified the species.
-			if !slices.Contains(ids, userID) {
-				a.Identifications[speciesID] = append(ids, userID)
-			}
-			*ent = a.ToEntity()
+	rev := revisionInfo{change: ChangeIdentificationAdded, userID: userID, speciesID: &speciesID}
+	return modifyAnnotation(id, nil, rev, func(a *AnnotationContents) error {
+		ids := a.Identifications[speciesID]
+		// Add an identification only if the user hasn't already identified the species.
+		if !slices.Contains(ids, userID) {
+			a.Identifications[speciesID] = append(ids, userID)
 		}
-	}, &annotation)
+		return nil
+	})
 }
 
 // DeleteIdentification removes a species identification from an annotation.
 func DeleteIdentification(id int64, userID int64, speciesID int64) error {
-	// Update data in the datastore.
-	store := globals.GetStore()
-	key := store.IDKey(entities.ANNOTATION_KIND, id)
-	var annotation entities.Annotation
+	rev := revisionInfo{change: ChangeIdentificationRemoved, userID: userID, speciesID: &speciesID}
+	return modifyAnnotation(id, nil, rev, func(a *AnnotationContents) error {
+		// If there is only one identification and it is by the calling user, remove the species identification from the map.
+		if len(a.Identifications[speciesID]) == 1 && a.Identifications[speciesID][0] == userID {
+			delete(a.Identifications, speciesID)
+		} else {
+			for i, id := range a.Identifications[speciesID] {
+				if id == userID {
+					a.Identifications[speciesID] = append(a.Identifications[speciesID][:i], a.Identifications[speciesID][i+1:]...)
+					break
+				}
+			}
+		}
+		return nil
+	})
+}
 
-	return store.Update(context.Background(), key, func(e datastore.Entity) {
-		ent, ok := e.(*entities.Annotation)
-		if ok {
-			a := AnnotationContentsFromEntity(*ent)
-			// If there is only one identification and it is by the calling user, remove the species identification from the map.
-			if len(a.Identifications[speciesID]) == 1 && a.Identifications[speciesID][0] == userID {
-				delete(a.Identifications, speciesID)
+// UpdateAnnotation updates the keypoints and attributes of an annotation, leaving its identifications untouched.
+// The update is only made if version matches the current version of the annotation, otherwise
+// ErrVersionMismatch is returned.
+func UpdateAnnotation(id int64, version int64, userID int64, updates PartialAnnotationContents) error {
+	// Verify attributes match the schema.
+	set := make(map[string]string)
+	for name, value := range updates.Attributes {
+		if value != nil {
+			set[name] = *value
+		}
+	}
+	if len(set) != 0 {
+		schema, err := GetAttributeSchema()
+		if err != nil {
+			return err
+		}
+		set, err = schema.Normalise(set)
+		if err != nil {
+			return err
+		}
+	}
+
+	rev := revisionInfo{change: ChangeKeyPoints, userID: userID}
+	if updates.KeyPoints == nil && len(updates.AddKeyPoints) == 0 && len(updates.RemoveKeyPoints) == 0 {
+		rev.change = ChangeAttributes
+	}
+	return modifyAnnotation(id, &version, rev, func(a *AnnotationContents) error {
+		keypoints, err := updates.apply(a.KeyPoints)
+		if err != nil {
+			return err
+		}
+		a.KeyPoints = keypoints
+		for name, value := range updates.Attributes {
+			if value == nil {
+				delete(a.Attributes, name)
 			} else {
-				for i, id := range a.Identifications[speciesID] {
-					if id == userID {
-						a.Identifications[speciesID] = append(a.Identifications[speciesID][:i], a.Identifications[speciesID][i+1:]...)
-						break
-					}
-				}
+				a.Attributes[name] = set[name]
 			}
-			*ent = a.ToEntity()
 		}
-	}, &annotation)
+		return nil
+	})
 }
 
-// DeleteAnnotation deletes an annotation.
-func DeleteAnnotation(id int64) error {
-	// Delete entity.
+// apply applies the keypoint changes to a list of keypoints and returns the resulting keypoints, ordered by time.
+func (p *PartialAnnotationContents) apply(keypoints []keypoint.KeyPoint) ([]keypoint.KeyPoint, error) {
+	byTime := make(map[int64]keypoint.KeyPoint, len(keypoints))
+	if p.KeyPoints != nil {
+		keypoints = *p.KeyPoints
+	}
+	for _, k := range keypoints {
+		byTime[k.Time.Int()] = k
+	}
+	for _, k := range p.AddKeyPoints {
+		byTime[k.Time.Int()] = k
+	}
+	for _, t := range p.RemoveKeyPoints {
+		if _, exists := byTime[t.Int()]; !exists {
+			return nil, fmt.Errorf("%w: no keypoint exists at %s", ErrInvalidKeyPoints, t.String())
+		}
+		delete(byTime, t.Int())
+	}
+
+	if len(byTime) == 0 {
+		return nil, fmt.Errorf("%w: an annotation must have at least one keypoint", ErrInvalidKeyPoints)
+	}
+
+	result := make([]keypoint.KeyPoint, 0, len(byTime))
+	for _, k := range byTime {
+		result = append(result, k)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Time.Int() < result[j].Time.Int() })
+
+	result = keypoint.Normalise(result)
+	if err := keypoint.Validate(result); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKeyPoints, err)
+	}
+
+	return result, nil
+}
+
+// DeleteAnnotation deletes an annotation. The annotation's history is kept, so it can be restored later.
+func DeleteAnnotation(id int64, userID int64) error {
 	store := globals.GetStore()
 	key := store.IDKey(entities.ANNOTATION_KIND, id)
-	return store.Delete(context.Background(), key)
+
+	// Get the final state of the annotation for its history.
+	var e entities.Annotation
+	err := store.Get(context.Background(), key, &e)
+	if err != nil {
+		return err
+	}
+
+	// Move entity to the trash.
+	err = putTrash(TrashAnnotation, id, &e, userID)
+	if err != nil {
+		return err
+	}
+	err = store.Delete(context.Background(), key)
+	if err != nil {
+		return errors.Join(err, discardTrash(TrashAnnotation, id))
+	}
+
+	e.Version++
+	setLastChange(&e, revisionInfo{change: ChangeDeleted, userID: userID})
+	return recordRevision(id, e)
 }
@@ -34,8 +34,10 @@ LICENSE
 package services_test
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ausocean/openfish/cmd/openfish/services"
 	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
@@ -153,7 +155,113 @@ func TestGetAnnotationByIDForNonexistentEntity(t *testing.T) {
 	}
 }
 
-// TODO: Write tests for GetAnnotations. Test limit, offset and filtering.
+// TODO: Write tests for GetAnnotations limit and offset.
+
+func TestGetAnnotationsByCreator(t *testing.T) {
+	setup()
+	created := createTestAnnotation()
+	uid, _ := services.CreateUser(services.UserContents{
+		Email:       "sandy.whiting@example.com",
+		DisplayName: "Sandy Whiting",
+		Role:        role.Annotator,
+	})
+	contents := created.AnnotationContents
+	contents.CreatedByID = uid
+	services.CreateAnnotation(contents)
+
+	annotations, err := services.GetAnnotations(0, 0, nil, services.AnnotationFilter{CreatedByID: &uid})
+	if err != nil {
+		t.Errorf("Could not get annotations %s", err)
+	}
+	if len(annotations) != 1 || annotations[0].CreatedByID != uid {
+		t.Errorf("Expected only the annotation created by %d, got %v", uid, annotations)
+	}
+}
+
+func TestGetAnnotationsBySpecies(t *testing.T) {
+	setup()
+	created := createTestAnnotation()
+	sp := createTestSpecies()
+	contents := created.AnnotationContents
+	contents.Identifications = map[int64][]int64{sp.ID: {created.CreatedByID}}
+	other, _ := services.CreateAnnotation(contents)
+
+	annotations, err := services.GetAnnotations(0, 0, nil, services.AnnotationFilter{SpeciesID: &sp.ID})
+	if err != nil {
+		t.Errorf("Could not get annotations %s", err)
+	}
+	if len(annotations) != 1 || annotations[0].ID != other.ID {
+		t.Errorf("Expected only the annotation identified as %d, got %v", sp.ID, annotations)
+	}
+}
+
+func TestGetAnnotationsByIdentifier(t *testing.T) {
+	setup()
+	created := createTestAnnotation()
+	createTestAnnotation()
+	uid := createTestUserWithRole("sandy.whiting", role.Annotator)
+	for speciesID := range created.Identifications {
+		services.AddIdentification(created.ID, uid, speciesID)
+	}
+
+	annotations, err := services.GetAnnotations(0, 0, nil, services.AnnotationFilter{IdentifiedByID: &uid})
+	if err != nil {
+		t.Errorf("Could not get annotations %s", err)
+	}
+	if len(annotations) != 1 || annotations[0].ID != created.ID {
+		t.Errorf("Expected only the annotation identified by %d, got %v", uid, annotations)
+	}
+}
+
+func TestGetAnnotationsByCaptureSource(t *testing.T) {
+	setup()
+	created := createTestAnnotation()
+	vs, _ := services.GetVideoStreamByID(created.VideostreamID)
+
+	annotations, err := services.GetAnnotations(20, 0, nil, services.AnnotationFilter{CaptureSourceID: &vs.CaptureSource})
+	if err != nil {
+		t.Errorf("Could not get annotations %s", err)
+	}
+	if len(annotations) != 1 || annotations[0].ID != created.ID {
+		t.Errorf("Expected annotation %d for capture source, got %v", created.ID, annotations)
+	}
+
+	other := int64(123456789)
+	annotations, _ = services.GetAnnotations(20, 0, nil, services.AnnotationFilter{CaptureSourceID: &other})
+	if len(annotations) != 0 {
+		t.Errorf("Expected no annotations for non-existent capture source, got %v", annotations)
+	}
+}
+
+func TestGetAnnotationsByDate(t *testing.T) {
+	setup()
+	created := createTestAnnotation()
+
+	// The test annotation starts one second into a video stream that starts at 8am.
+	tests := []struct {
+		from     time.Time
+		to       time.Time
+		expected int
+	}{
+		{from: _8am, to: _9am, expected: 1},
+		{from: _8am, to: _8am, expected: 0},
+		{from: _9am, to: _4pm, expected: 0},
+		{from: _8am.Add(time.Second), to: _8am.Add(time.Second), expected: 1},
+	}
+	for _, test := range tests {
+		filter := services.AnnotationFilter{VideoStreamID: &created.VideostreamID, From: &test.from, To: &test.to}
+		annotations, err := services.GetAnnotations(20, 0, nil, filter)
+		if err != nil {
+			t.Errorf("Could not get annotations %s", err)
+		}
+		if len(annotations) != test.expected {
+			t.Errorf("Expected %d annotations between %v and %v, got %d", test.expected, test.from, test.to, len(annotations))
+		}
+		if len(annotations) == 1 && annotations[0].ID != created.ID {
+			t.Errorf("Expected annotation %d, got %d", created.ID, annotations[0].ID)
+		}
+	}
+}
 
 func TestAnnotationApplyJoin(t *testing.T) {
 	setup()
@@ -274,6 +382,106 @@ func TestAnnotationRemoveOtherUserIdentifications(t *testing.T) {
 	}
 }
 
+func TestUpdateAnnotation(t *testing.T) {
+	setup()
+	original := createTestAnnotation()
+
+	// Move the second keypoint and add a third.
+	err := services.UpdateAnnotation(original.ID, original.Version, original.CreatedByID, services.PartialAnnotationContents{
+		AddKeyPoints: []keypoint.KeyPoint{
+			{
+				BoundingBox: keypoint.BoundingBox{X1: 25, X2: 35, Y1: 60, Y2: 70},
+				Time:        videotime.UncheckedParse("00:00:02.000"),
+			},
+			{
+				BoundingBox: keypoint.BoundingBox{X1: 30, X2: 40, Y1: 50, Y2: 60},
+				Time:        videotime.UncheckedParse("00:00:03.000"),
+			},
+		},
+	})
+	if err != nil {
+		t.Errorf("Could not update annotation entity %s", err)
+	}
+
+	modified, _ := services.GetAnnotationByID(original.ID)
+	if len(modified.KeyPoints) != 3 {
+		t.Errorf("Expected 3 keypoints, got %d", len(modified.KeyPoints))
+	}
+	if modified.KeyPoints[1].BoundingBox.X1 != 25 {
+		t.Errorf("Expected keypoint to be moved")
+	}
+	if !reflect.DeepEqual(original.Identifications, modified.Identifications) {
+		t.Errorf("Expected identifications to be preserved, expected %v, got %v", original.Identifications, modified.Identifications)
+	}
+	if modified.Version != original.Version+1 {
+		t.Errorf("Expected version to be incremented, expected %d, got %d", original.Version+1, modified.Version)
+	}
+}
+
+func TestUpdateAnnotationRemoveKeyPoint(t *testing.T) {
+	setup()
+	original := createTestAnnotation()
+
+	err := services.UpdateAnnotation(original.ID, original.Version, original.CreatedByID, services.PartialAnnotationContents{
+		RemoveKeyPoints: []videotime.VideoTime{videotime.UncheckedParse("00:00:01.000")},
+	})
+	if err != nil {
+		t.Errorf("Could not update annotation entity %s", err)
+	}
+
+	modified, _ := services.GetAnnotationByID(original.ID)
+	if len(modified.KeyPoints) != 1 || modified.KeyPoints[0].Time != videotime.UncheckedParse("00:00:02.000") {
+		t.Errorf("Expected only the second keypoint to remain, got %v", modified.KeyPoints)
+	}
+
+	// Removing the last keypoint should fail.
+	err = services.UpdateAnnotation(original.ID, modified.Version, original.CreatedByID, services.PartialAnnotationContents{
+		RemoveKeyPoints: []videotime.VideoTime{videotime.UncheckedParse("00:00:02.000")},
+	})
+	if !errors.Is(err, services.ErrInvalidKeyPoints) {
+		t.Errorf("Expected ErrInvalidKeyPoints when removing all keypoints, got %v", err)
+	}
+}
+
+func TestUpdateAnnotationWithOutdatedVersion(t *testing.T) {
+	setup()
+	original := createTestAnnotation()
+
+	// Someone else modifies the annotation.
+	uid, _ := services.CreateUser(services.UserContents{
+		Email:       "sandy.whiting@example.com",
+		DisplayName: "Sandy Whiting",
+		Role:        role.Annotator,
+	})
+	sp := createTestSpecies()
+	services.AddIdentification(original.ID, uid, sp.ID)
+
+	keypoints := []keypoint.KeyPoint{
+		{
+			BoundingBox: keypoint.BoundingBox{X1: 0, X2: 10, Y1: 0, Y2: 10},
+			Time:        videotime.UncheckedParse("00:00:05.000"),
+		},
+	}
+	err := services.UpdateAnnotation(original.ID, original.Version, original.CreatedByID, services.PartialAnnotationContents{KeyPoints: &keypoints})
+	if !errors.Is(err, services.ErrVersionMismatch) {
+		t.Errorf("Expected ErrVersionMismatch, got %v", err)
+	}
+
+	modified, _ := services.GetAnnotationByID(original.ID)
+	if !reflect.DeepEqual(original.KeyPoints, modified.KeyPoints) {
+		t.Errorf("Expected keypoints to be unchanged, expected %v, got %v", original.KeyPoints, modified.KeyPoints)
+	}
+}
+
+func TestUpdateAnnotationForNonexistentEntity(t *testing.T) {
+	setup()
+
+	err := services.UpdateAnnotation(int64(123456789), 1, int64(123456789), services.PartialAnnotationContents{})
+	if err == nil {
+		t.Errorf("Did not receive expected error when updating non-existent annotation")
+	}
+}
+
 func TestDeleteAnnotation(t *testing.T) {
 	setup()
 
@@ -281,7 +489,7 @@ func TestDeleteAnnotation(t *testing.T) {
 	created := createTestAnnotation()
 
 	// Delete the annotation entity.
-	err := services.DeleteAnnotation(created.ID)
+	err := services.DeleteAnnotation(created.ID, created.CreatedByID)
 	if err != nil {
 		t.Errorf("Could not delete annotation entity %d: %s", created.ID, err)
 	}
@@ -295,8 +503,79 @@ func TestDeleteAnnotation(t *testing.T) {
 func TestDeleteAnnotationForNonexistentEntity(t *testing.T) {
 	setup()
 
-	err := services.DeleteAnnotation(int64(123456789))
+	err := services.DeleteAnnotation(int64(123456789), int64(123456789))
 	if err == nil {
 		t.Errorf("Did not receive expected error when deleting non-existent annotation")
 	}
 }
+
+func TestCreateAnnotationWithPolygons(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+
+	created, err := services.CreateAnnotation(services.AnnotationContents{
+		KeyPoints: []keypoint.KeyPoint{
+			{
+				Polygon: keypoint.Polygon{{X: 10, Y: 70}, {X: 20, Y: 70}, {X: 15, Y: 80}, {X: 10, Y: 70}},
+				Time:    videotime.UncheckedParse("00:00:01.000"),
+			},
+			{
+				Polygon: keypoint.Polygon{{X: 20, Y: 60}, {X: 30, Y: 60}, {X: 25, Y: 70}},
+				Time:    videotime.UncheckedParse("00:00:02.000"),
+			},
+		},
+		VideostreamID:   a.VideostreamID,
+		Identifications: map[int64][]int64{},
+		CreatedByID:     a.CreatedByID,
+	})
+	if err != nil {
+		t.Fatalf("Could not create annotation entity %s", err)
+	}
+
+	annotation, _ := services.GetAnnotationByID(created.ID)
+	if len(annotation.KeyPoints[0].Polygon) != 3 || annotation.KeyPoints[1].Polygon[2] != (keypoint.Point{X: 25, Y: 70}) {
+		t.Errorf("Polygons do not match expected, got %v and %v", annotation.KeyPoints[0].Polygon, annotation.KeyPoints[1].Polygon)
+	}
+	expected := keypoint.BoundingBox{X1: 10, X2: 20, Y1: 70, Y2: 80}
+	if annotation.KeyPoints[0].BoundingBox != expected {
+		t.Errorf("Expected bounding box %v derived from polygon, got %v", expected, annotation.KeyPoints[0].BoundingBox)
+	}
+}
+
+func TestCreateAnnotationWithInvalidPolygon(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+
+	_, err := services.CreateAnnotation(services.AnnotationContents{
+		KeyPoints: []keypoint.KeyPoint{
+			{
+				Polygon: keypoint.Polygon{{X: 0, Y: 0}, {X: 10, Y: 10}, {X: 10, Y: 0}, {X: 0, Y: 10}},
+				Time:    videotime.UncheckedParse("00:00:01.000"),
+			},
+		},
+		VideostreamID:   a.VideostreamID,
+		Identifications: map[int64][]int64{},
+		CreatedByID:     a.CreatedByID,
+	})
+	if !errors.Is(err, services.ErrInvalidKeyPoints) {
+		t.Errorf("Expected ErrInvalidKeyPoints, got %v", err)
+	}
+}
+
+func TestUpdateAnnotationAddPolygonToBoxes(t *testing.T) {
+	setup()
+	original := createTestAnnotation()
+
+	// The annotation's other keypoints do not have polygons.
+	err := services.UpdateAnnotation(original.ID, original.Version, original.CreatedByID, services.PartialAnnotationContents{
+		AddKeyPoints: []keypoint.KeyPoint{
+			{
+				Polygon: keypoint.Polygon{{X: 20, Y: 60}, {X: 30, Y: 60}, {X: 25, Y: 70}},
+				Time:    videotime.UncheckedParse("00:00:03.000"),
+			},
+		},
+	})
+	if !errors.Is(err, services.ErrInvalidKeyPoints) {
+		t.Errorf("Expected ErrInvalidKeyPoints, got %v", err)
+	}
+}
@@ -0,0 +1,350 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ausocean/cloud/datastore"
+	"github.com/ausocean/openfish/cmd/openfish/entities"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/types/nullable"
+)
+
+// ErrInvalidAttributes is returned when an annotation's attributes do not match the attribute schema.
+var ErrInvalidAttributes = errors.New("invalid attributes")
+
+// ErrAttributeExists is returned when creating an attribute with the same name as an existing attribute.
+var ErrAttributeExists = errors.New("attribute already exists")
+
+// attributeNamePattern matches valid attribute names, e.g. life_stage.
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// maxTextAttributeLength is the maximum length of the value of a text attribute.
+const maxTextAttributeLength = 500
+
+// AttributeType is the type of the values of an attribute.
+type AttributeType string
+
+const (
+	NumberAttribute  AttributeType = "number"  // A number, optionally between a minimum and maximum, e.g. count of individuals.
+	TextAttribute    AttributeType = "text"    // Free text, e.g. notes.
+	EnumAttribute    AttributeType = "enum"    // One of a list of options, e.g. sex.
+	BooleanAttribute AttributeType = "boolean" // True or false.
+)
+
+// UnmarshalText is used for decoding query params or JSON into an AttributeType.
+func (t *AttributeType) UnmarshalText(text []byte) error {
+	switch typ := AttributeType(text); typ {
+	case NumberAttribute, TextAttribute, EnumAttribute, BooleanAttribute:
+		*t = typ
+		return nil
+	}
+	return fmt.Errorf("invalid attribute type provided: %s", text)
+}
+
+// Attribute is a kind of observation that can be recorded on annotations, such as behaviour or life stage.
+type Attribute struct {
+	Name string `json:"name" example:"behaviour"`
+	AttributeContents
+}
+
+// AttributeContents is the contents of an attribute.
+type AttributeContents struct {
+	Description string        `json:"description" example:"What the animal is doing"`
+	Type        AttributeType `json:"type" swaggertype:"string" enums:"number,text,enum,boolean" example:"enum"`
+	Options     []string      `json:"options,omitempty" example:"feeding,schooling,resting"` // Allowed values of enum attributes.
+	Min         *float64      `json:"min,omitempty" example:"1"`                             // Minimum value of number attributes.
+	Max         *float64      `json:"max,omitempty"`                                         // Maximum value of number attributes.
+}
+
+// PartialAttributeContents is for updating an attribute with a partial update (such as a PATCH request).
+// The type of an attribute cannot be changed. Min and Max are cleared by setting them to null.
+type PartialAttributeContents struct {
+	Description *string                    `json:"description,omitempty" example:"What the animal is doing"`
+	Options     *[]string                  `json:"options,omitempty" example:"feeding,schooling,resting"`
+	Min         nullable.Nullable[float64] `json:"min" swaggertype:"number" example:"1"`
+	Max         nullable.Nullable[float64] `json:"max" swaggertype:"number"`
+}
+
+// AttributeSchema is the set of attributes that can be recorded on annotations, keyed by name.
+type AttributeSchema map[string]AttributeContents
+
+// AttributeContentsFromEntity converts an entities.Attribute to an AttributeContents.
+func AttributeContentsFromEntity(e entities.Attribute) AttributeContents {
+	return AttributeContents{
+		Description: e.Description,
+		Type:        AttributeType(e.Type),
+		Options:     e.Options,
+		Min:         e.Min,
+		Max:         e.Max,
+	}
+}
+
+// ToEntity converts an AttributeContents to an entities.Attribute for storage in the datastore.
+func (a *AttributeContents) ToEntity() entities.Attribute {
+	return entities.Attribute{
+		Description: a.Description,
+		Type:        string(a.Type),
+		Options:     a.Options,
+		Min:         a.Min,
+		Max:         a.Max,
+	}
+}
+
+// validate checks that the attribute's options and bounds are consistent with its type.
+func (a *AttributeContents) validate() error {
+	if a.Type == EnumAttribute && len(a.Options) == 0 {
+		return fmt.Errorf("%w: enum attributes must have at least one option", ErrInvalidAttributes)
+	}
+	if a.Type != EnumAttribute && len(a.Options) != 0 {
+		return fmt.Errorf("%w: only enum attributes can have options", ErrInvalidAttributes)
+	}
+	if a.Type != NumberAttribute && (a.Min != nil || a.Max != nil) {
+		return fmt.Errorf("%w: only number attributes can have a minimum or maximum", ErrInvalidAttributes)
+	}
+	if a.Min != nil && a.Max != nil && *a.Min > *a.Max {
+		return fmt.Errorf("%w: minimum must not be greater than maximum", ErrInvalidAttributes)
+	}
+	return nil
+}
+
+// normalise checks that value is valid for the attribute and returns it in canonical form, so that
+// equal values are stored and queried identically.
+func (a *AttributeContents) normalise(value string) (string, error) {
+	switch a.Type {
+	case NumberAttribute:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a number", value)
+		}
+		if (a.Min != nil && f < *a.Min) || (a.Max != nil && f > *a.Max) {
+			return "", fmt.Errorf("%q is out of range", value)
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case BooleanAttribute:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("%q is not true or false", value)
+		}
+		return strconv.FormatBool(b), nil
+	case EnumAttribute:
+		if !slices.Contains(a.Options, value) {
+			return "", fmt.Errorf("%q is not one of %s", value, strings.Join(a.Options, ", "))
+		}
+		return value, nil
+	default:
+		value = strings.TrimSpace(value)
+		if value == "" || len(value) > maxTextAttributeLength {
+			return "", fmt.Errorf("text must be between 1 and %d characters", maxTextAttributeLength)
+		}
+		return value, nil
+	}
+}
+
+// Normalise checks that attributes match the schema and returns them with their values in canonical form.
+func (s AttributeSchema) Normalise(attributes map[string]string) (map[string]string, error) {
+	normalised := make(map[string]string, len(attributes))
+	for name, value := range attributes {
+		attr, ok := s[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown attribute %s", ErrInvalidAttributes, name)
+		}
+		v, err := attr.normalise(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidAttributes, name, err)
+		}
+		normalised[name] = v
+	}
+	return normalised, nil
+}
+
+// GetAttributeSchema gets all the attributes that can be recorded on annotations.
+func GetAttributeSchema() (AttributeSchema, error) {
+	attributes, err := GetAttributes()
+	if err != nil {
+		return nil, err
+	}
+	schema := make(AttributeSchema, len(attributes))
+	for _, a := range attributes {
+		schema[a.Name] = a.AttributeContents
+	}
+	return schema, nil
+}
+
+// GetAttributes gets all attributes, sorted by name.
+func GetAttributes() ([]Attribute, error) {
+	store := globals.GetStore()
+	query := store.NewQuery(entities.ATTRIBUTE_KIND, false)
+
+	var ents []entities.Attribute
+	keys, err := store.GetAll(context.Background(), query, &ents)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make([]Attribute, len(ents))
+	for i := range ents {
+		attributes[i] = Attribute{
+			Name:              keys[i].Name,
+			AttributeContents: AttributeContentsFromEntity(ents[i]),
+		}
+	}
+	slices.SortFunc(attributes, func(a, b Attribute) int { return strings.Compare(a.Name, b.Name) })
+
+	return attributes, nil
+}
+
+// GetAttributeByName gets an attribute by its name.
+func GetAttributeByName(name string) (*Attribute, error) {
+	store := globals.GetStore()
+	key := store.NameKey(entities.ATTRIBUTE_KIND, name)
+	var e entities.Attribute
+	err := store.Get(context.Background(), key, &e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Attribute{
+		Name:              name,
+		AttributeContents: AttributeContentsFromEntity(e),
+	}, nil
+}
+
+// AttributeExists checks if an attribute exists with the given name.
+func AttributeExists(name string) bool {
+	store := globals.GetStore()
+	key := store.NameKey(entities.ATTRIBUTE_KIND, name)
+	var attribute entities.Attribute
+	err := store.Get(context.Background(), key, &attribute)
+	return err == nil
+}
+
+// CreateAttribute adds an attribute to the schema.
+func CreateAttribute(attribute Attribute) (*Attribute, error) {
+	if !attributeNamePattern.MatchString(attribute.Name) {
+		return nil, fmt.Errorf("%w: name must be lowercase letters, numbers and underscores", ErrInvalidAttributes)
+	}
+	if err := attribute.validate(); err != nil {
+		return nil, err
+	}
+
+	// Check the attribute does not already exist.
+	if _, err := GetAttributeByName(attribute.Name); err == nil {
+		return nil, ErrAttributeExists
+	} else if !errors.Is(err, datastore.ErrNoSuchEntity) {
+		return nil, err
+	}
+
+	store := globals.GetStore()
+	key := store.NameKey(entities.ATTRIBUTE_KIND, attribute.Name)
+	ent := attribute.ToEntity()
+	_, err := store.Put(context.Background(), key, &ent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attribute, nil
+}
+
+// UpdateAttribute updates an attribute with the provided partial contents.
+// Existing annotations are not revalidated.
+func UpdateAttribute(name string, updates PartialAttributeContents) error {
+	store := globals.GetStore()
+	key := store.NameKey(entities.ATTRIBUTE_KIND, name)
+	var attribute entities.Attribute
+
+	var validateErr error
+	err := store.Update(context.Background(), key, func(e datastore.Entity) {
+		a, ok := e.(*entities.Attribute)
+		if !ok {
+			return
+		}
+		contents := AttributeContentsFromEntity(*a)
+		if updates.Description != nil {
+			contents.Description = *updates.Description
+		}
+		if updates.Options != nil {
+			contents.Options = *updates.Options
+		}
+		contents.Min = updates.Min.Apply(contents.Min)
+		contents.Max = updates.Max.Apply(contents.Max)
+		validateErr = contents.validate()
+		if validateErr == nil {
+			*a = contents.ToEntity()
+		}
+	}, &attribute)
+	if err != nil {
+		return err
+	}
+	return validateErr
+}
+
+// DeleteAttribute removes an attribute from the schema. Existing annotations keep their values for the attribute.
+func DeleteAttribute(name string) error {
+	store := globals.GetStore()
+	key := store.NameKey(entities.ATTRIBUTE_KIND, name)
+	return store.Delete(context.Background(), key)
+}
+
+// attributesToEntity converts attributes to "name=value" strings, sorted by name, for storage in the datastore.
+func attributesToEntity(attributes map[string]string) []string {
+	strs := make([]string, 0, len(attributes))
+	for _, name := range slices.Sorted(maps.Keys(attributes)) {
+		strs = append(strs, attributeFilterValue(name, attributes[name]))
+	}
+	return strs
+}
+
+// attributesFromEntity converts "name=value" strings from the datastore to attributes.
+func attributesFromEntity(strs []string) map[string]string {
+	attributes := make(map[string]string, len(strs))
+	for _, s := range strs {
+		name, value, _ := strings.Cut(s, "=")
+		attributes[name] = value
+	}
+	return attributes
+}
+
+// attributeFilterValue returns the stored form of an attribute, used for filtering.
+func attributeFilterValue(name string, value string) string {
+	return name + "=" + value
+}
@@ -0,0 +1,226 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func createTestAttributes() {
+	services.CreateAttribute(services.Attribute{
+		Name:              "count",
+		AttributeContents: services.AttributeContents{Type: services.NumberAttribute, Min: ptr(1.0)},
+	})
+	services.CreateAttribute(services.Attribute{
+		Name:              "behaviour",
+		AttributeContents: services.AttributeContents{Type: services.EnumAttribute, Options: []string{"feeding", "schooling"}},
+	})
+}
+
+func TestCreateAttribute(t *testing.T) {
+	setup()
+	createTestAttributes()
+
+	attributes, err := services.GetAttributes()
+	if err != nil {
+		t.Fatalf("Could not get attributes %s", err)
+	}
+	if len(attributes) != 2 || attributes[0].Name != "behaviour" || attributes[1].Name != "count" {
+		t.Errorf("Expected attributes behaviour and count, got %+v", attributes)
+	}
+}
+
+func TestCreateAttributeDuplicate(t *testing.T) {
+	setup()
+	createTestAttributes()
+
+	_, err := services.CreateAttribute(services.Attribute{
+		Name:              "count",
+		AttributeContents: services.AttributeContents{Type: services.TextAttribute},
+	})
+	if !errors.Is(err, services.ErrAttributeExists) {
+		t.Errorf("Expected ErrAttributeExists, got %v", err)
+	}
+}
+
+func TestCreateAttributeInvalid(t *testing.T) {
+	setup()
+
+	invalid := []services.Attribute{
+		{Name: "Life Stage", AttributeContents: services.AttributeContents{Type: services.TextAttribute}},
+		{Name: "life_stage", AttributeContents: services.AttributeContents{Type: services.EnumAttribute}},
+		{Name: "certain", AttributeContents: services.AttributeContents{Type: services.BooleanAttribute, Max: ptr(1.0)}},
+		{Name: "length", AttributeContents: services.AttributeContents{Type: services.NumberAttribute, Min: ptr(2.0), Max: ptr(1.0)}},
+	}
+	for _, a := range invalid {
+		_, err := services.CreateAttribute(a)
+		if !errors.Is(err, services.ErrInvalidAttributes) {
+			t.Errorf("Expected ErrInvalidAttributes for %+v, got %v", a, err)
+		}
+	}
+}
+
+func TestUpdateAttribute(t *testing.T) {
+	setup()
+	createTestAttributes()
+
+	err := services.UpdateAttribute("behaviour", services.PartialAttributeContents{Options: &[]string{"feeding", "schooling", "resting"}})
+	if err != nil {
+		t.Fatalf("Could not update attribute %s", err)
+	}
+	a, _ := services.GetAttributeByName("behaviour")
+	if len(a.Options) != 3 {
+		t.Errorf("Expected 3 options, got %v", a.Options)
+	}
+
+	err = services.UpdateAttribute("behaviour", services.PartialAttributeContents{Options: &[]string{}})
+	if !errors.Is(err, services.ErrInvalidAttributes) {
+		t.Errorf("Expected ErrInvalidAttributes, got %v", err)
+	}
+}
+
+func TestUpdateAttributeClearMinimum(t *testing.T) {
+	setup()
+	services.DeleteAttribute("length")
+	services.CreateAttribute(services.Attribute{
+		Name:              "length",
+		AttributeContents: services.AttributeContents{Type: services.NumberAttribute, Min: ptr(1.0)},
+	})
+	defer services.DeleteAttribute("length")
+
+	var updates services.PartialAttributeContents
+	err := json.Unmarshal([]byte(`{"min": null, "max": 10}`), &updates)
+	if err != nil {
+		t.Fatalf("Could not decode update %s", err)
+	}
+	err = services.UpdateAttribute("length", updates)
+	if err != nil {
+		t.Fatalf("Could not update attribute %s", err)
+	}
+	a, _ := services.GetAttributeByName("length")
+	if a.Min != nil || a.Max == nil || *a.Max != 10 {
+		t.Errorf("Expected no minimum and a maximum of 10, got %v and %v", a.Min, a.Max)
+	}
+
+	// Fields that are left out are not changed.
+	err = services.UpdateAttribute("length", services.PartialAttributeContents{})
+	if err != nil {
+		t.Fatalf("Could not update attribute %s", err)
+	}
+	a, _ = services.GetAttributeByName("length")
+	if a.Max == nil || *a.Max != 10 {
+		t.Errorf("Expected maximum to be kept, got %v", a.Max)
+	}
+}
+
+func TestAttributeExists(t *testing.T) {
+	setup()
+	createTestAttributes()
+
+	if !services.AttributeExists("count") {
+		t.Errorf("Expected attribute count to exist")
+	}
+	if services.AttributeExists("colour") {
+		t.Errorf("Expected attribute colour to not exist")
+	}
+}
+
+func TestAttributeSchemaNormalise(t *testing.T) {
+	setup()
+	createTestAttributes()
+	schema, _ := services.GetAttributeSchema()
+
+	normalised, err := schema.Normalise(map[string]string{"count": "03", "behaviour": "feeding"})
+	if err != nil {
+		t.Fatalf("Could not normalise attributes %s", err)
+	}
+	if normalised["count"] != "3" || normalised["behaviour"] != "feeding" {
+		t.Errorf("Unexpected normalised attributes %v", normalised)
+	}
+
+	invalid := []map[string]string{
+		{"count": "0"},
+		{"count": "many"},
+		{"behaviour": "sleeping"},
+		{"colour": "red"},
+	}
+	for _, attributes := range invalid {
+		_, err := schema.Normalise(attributes)
+		if !errors.Is(err, services.ErrInvalidAttributes) {
+			t.Errorf("Expected ErrInvalidAttributes for %v, got %v", attributes, err)
+		}
+	}
+}
+
+func TestUpdateAnnotationAttributes(t *testing.T) {
+	setup()
+	createTestAttributes()
+	a := createTestAnnotation()
+
+	err := services.UpdateAnnotation(a.ID, a.Version, a.CreatedByID, services.PartialAnnotationContents{
+		Attributes: map[string]*string{"count": ptr("2"), "behaviour": ptr("feeding")},
+	})
+	if err != nil {
+		t.Fatalf("Could not update annotation %s", err)
+	}
+	annotation, _ := services.GetAnnotationByID(a.ID)
+	if annotation.Attributes["count"] != "2" || annotation.Attributes["behaviour"] != "feeding" {
+		t.Errorf("Unexpected attributes %v", annotation.Attributes)
+	}
+
+	// Null removes an attribute.
+	err = services.UpdateAnnotation(a.ID, annotation.Version, a.CreatedByID, services.PartialAnnotationContents{
+		Attributes: map[string]*string{"count": nil},
+	})
+	if err != nil {
+		t.Fatalf("Could not update annotation %s", err)
+	}
+	annotation, _ = services.GetAnnotationByID(a.ID)
+	if _, ok := annotation.Attributes["count"]; ok || len(annotation.Attributes) != 1 {
+		t.Errorf("Expected count to be removed, got %v", annotation.Attributes)
+	}
+
+	err = services.UpdateAnnotation(a.ID, annotation.Version, a.CreatedByID, services.PartialAnnotationContents{
+		Attributes: map[string]*string{"colour": ptr("red")},
+	})
+	if !errors.Is(err, services.ErrInvalidAttributes) {
+		t.Errorf("Expected ErrInvalidAttributes, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ausocean/cloud/datastore"
+	"github.com/ausocean/openfish/cmd/openfish/entities"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+)
+
+// MaxBulkAnnotations is the maximum number of annotations that can be created in a single bulk request.
+const MaxBulkAnnotations = 500
+
+// putBatchSize is the number of annotations written at once when creating annotations in bulk. The datastore has no
+// multi-put, so the puts in each batch are made concurrently.
+const putBatchSize = 25
+
+// ErrInvalidBulk is returned when a bulk request is empty, too large, or would delete every annotation.
+var ErrInvalidBulk = errors.New("invalid bulk request")
+
+// BulkResult is the result of creating one annotation in a bulk request. Index is the position of the
+// annotation in the request. Either ID is set, if the annotation was created, or Error describes why it was not.
+type BulkResult struct {
+	Index int    `json:"index" example:"0"`
+	ID    *int64 `json:"id,omitempty" example:"1234567890"`
+	Error string `json:"error,omitempty" example:"species 1234567890 does not exist"`
+}
+
+// BulkCreateReport summarises the results of a bulk request.
+type BulkCreateReport struct {
+	Created int          `json:"created" example:"99"`
+	Failed  int          `json:"failed" example:"1"`
+	Results []BulkResult `json:"results"`
+}
+
+// CreateAnnotations creates many annotations at once. Every annotation is validated before any are written, looking
+// up each video stream and species only once: the video stream must exist and its annotator list must include the
+// creator, and the identified species must exist. Invalid annotations are reported and skipped, the rest are created.
+func CreateAnnotations(contents []AnnotationContents) (*BulkCreateReport, error) {
+	if len(contents) == 0 || len(contents) > MaxBulkAnnotations {
+		return nil, fmt.Errorf("%w: must create between 1 and %d annotations, got %d", ErrInvalidBulk, MaxBulkAnnotations, len(contents))
+	}
+
+	schema, err := GetAttributeSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	// Look up the video streams and species used by the annotations.
+	videoStreams := make(map[int64]*VideoStream)
+	species := make(map[int64]bool)
+	for _, c := range contents {
+		if _, ok := videoStreams[c.VideostreamID]; !ok {
+			vs, err := GetVideoStreamByID(c.VideostreamID)
+			if err != nil && !errors.Is(err, datastore.ErrNoSuchEntity) {
+				return nil, err
+			}
+			videoStreams[c.VideostreamID] = vs
+		}
+		for id := range c.Identifications {
+			if _, ok := species[id]; !ok {
+				species[id] = SpeciesExists(id)
+			}
+		}
+	}
+
+	// Validate every annotation before writing any.
+	report := BulkCreateReport{Results: make([]BulkResult, len(contents))}
+	valid := make([]bool, len(contents))
+	for i := range contents {
+		report.Results[i].Index = i
+		err := validateBulkAnnotation(&contents[i], videoStreams, species, schema)
+		if err != nil {
+			report.Results[i].Error = err.Error()
+			continue
+		}
+		valid[i] = true
+	}
+
+	// Write valid annotations in batches.
+	var pending []int
+	for i := range contents {
+		if valid[i] {
+			pending = append(pending, i)
+		}
+	}
+	for len(pending) > 0 {
+		n := min(len(pending), putBatchSize)
+		var wg sync.WaitGroup
+		for _, i := range pending[:n] {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				created, err := putAnnotation(contents[i])
+				if err != nil {
+					report.Results[i].Error = err.Error()
+					return
+				}
+				report.Results[i].ID = &created.ID
+			}()
+		}
+		wg.Wait()
+		pending = pending[n:]
+	}
+
+	for _, r := range report.Results {
+		if r.ID != nil {
+			report.Created++
+		} else {
+			report.Failed++
+		}
+	}
+	return &report, nil
+}
+
+// validateBulkAnnotation checks that an annotation in a bulk request refers to an existing video stream and species,
+// and that its creator is permitted to annotate the video stream, then prepares it for writing.
+func validateBulkAnnotation(c *AnnotationContents, videoStreams map[int64]*VideoStream, species map[int64]bool, schema AttributeSchema) error {
+	vs := videoStreams[c.VideostreamID]
+	if vs == nil {
+		return fmt.Errorf("video stream %d does not exist", c.VideostreamID)
+	}
+	if len(vs.AnnotatorList) != 0 && !slices.Contains(vs.AnnotatorList, c.CreatedByID) {
+		return fmt.Errorf("user %d is not within annotator list for video stream %d", c.CreatedByID, c.VideostreamID)
+	}
+	for id := range c.Identifications {
+		if !species[id] {
+			return fmt.Errorf("species %d does not exist", id)
+		}
+	}
+	return prepareAnnotation(c, schema)
+}
+
+// empty reports whether none of the filters are set.
+func (f *AnnotationFilter) empty() bool {
+	return f.VideoStreamID == nil && f.CaptureSourceID == nil && f.SpeciesID == nil && f.IdentifiedByID == nil &&
+		f.CreatedByID == nil && f.Consensus == nil && f.Review == nil && len(f.Attributes) == 0 &&
+		f.TimeSpan == nil && f.From == nil && f.To == nil
+}
+
+// DeleteAnnotations deletes every annotation matching the filter, returning the number deleted. At least one filter
+// must be set, so that all annotations cannot be deleted by mistake. The history of each annotation is kept.
+func DeleteAnnotations(filter AnnotationFilter, userID int64) (int, error) {
+	if filter.empty() {
+		return 0, fmt.Errorf("%w: at least one filter is required", ErrInvalidBulk)
+	}
+
+	annotations, err := GetAnnotations(0, 0, nil, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	store := globals.GetStore()
+	keys := make([]*datastore.Key, len(annotations))
+	ids := make([]int64, len(annotations))
+	for i, a := range annotations {
+		keys[i] = store.IDKey(entities.ANNOTATION_KIND, a.ID)
+		ids[i] = a.ID
+		e := a.ToEntity()
+		err := putTrash(TrashAnnotation, a.ID, &e, userID)
+		if err != nil {
+			return 0, errors.Join(err, discardTrash(TrashAnnotation, ids[:i]...))
+		}
+	}
+	_, err = datastore.DeleteMulti(context.Background(), store, keys)
+	if err != nil {
+		return 0, errors.Join(err, discardTrash(TrashAnnotation, ids...))
+	}
+
+	// Record the final state of each annotation for its history.
+	for _, a := range annotations {
+		e := a.ToEntity()
+		e.Version = a.Version + 1
+		setLastChange(&e, revisionInfo{change: ChangeDeleted, userID: userID})
+		err := recordRevision(a.ID, e)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(annotations), nil
+}
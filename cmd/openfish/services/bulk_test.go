@@ -0,0 +1,142 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// testBulkContents returns the contents of an annotation for a bulk request.
+func testBulkContents(vs int64, user int64, species int64) services.AnnotationContents {
+	return services.AnnotationContents{
+		KeyPoints: []keypoint.KeyPoint{
+			{
+				BoundingBox: keypoint.BoundingBox{X1: 10, X2: 20, Y1: 70, Y2: 80},
+				Time:        videotime.UncheckedParse("00:00:01.000"),
+			},
+		},
+		VideostreamID:   vs,
+		Identifications: map[int64][]int64{species: {user}},
+		CreatedByID:     user,
+	}
+}
+
+func TestCreateAnnotations(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	restricted := createTestVideoStream()
+	sp := createTestSpecies()
+	user := createTestUserWithRole("annotator", role.Annotator)
+	other := createTestUserWithRole("other", role.Annotator)
+	services.UpdateVideoStream(restricted.ID, services.PartialVideoStreamContents{AnnotatorList: &[]int64{other}})
+
+	invalidKeyPoints := testBulkContents(vs.ID, user, sp.ID)
+	invalidKeyPoints.KeyPoints = nil
+	contents := []services.AnnotationContents{
+		testBulkContents(vs.ID, user, sp.ID),
+		testBulkContents(int64(123456789), user, sp.ID),
+		testBulkContents(vs.ID, user, int64(123456789)),
+		testBulkContents(restricted.ID, user, sp.ID),
+		invalidKeyPoints,
+		testBulkContents(vs.ID, user, sp.ID),
+	}
+
+	report, err := services.CreateAnnotations(contents)
+	if err != nil {
+		t.Fatalf("Could not create annotations %s", err)
+	}
+	if report.Created != 2 || report.Failed != 4 {
+		t.Errorf("Expected 2 created and 4 failed, got %d and %d", report.Created, report.Failed)
+	}
+	for i, r := range report.Results {
+		created := i == 0 || i == 5
+		if r.Index != i || (r.ID != nil) != created || (r.Error == "") != created {
+			t.Errorf("Result %d does not match expected, got %+v", i, r)
+		}
+	}
+
+	if !services.AnnotationExists(*report.Results[0].ID) {
+		t.Errorf("Expected created annotation to exist")
+	}
+}
+
+func TestCreateAnnotationsEmpty(t *testing.T) {
+	setup()
+
+	_, err := services.CreateAnnotations([]services.AnnotationContents{})
+	if !errors.Is(err, services.ErrInvalidBulk) {
+		t.Errorf("Expected ErrInvalidBulk, got %v", err)
+	}
+}
+
+func TestDeleteAnnotations(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	b := createTestAnnotation()
+
+	deleted, err := services.DeleteAnnotations(services.AnnotationFilter{VideoStreamID: &a.VideostreamID}, a.CreatedByID)
+	if err != nil {
+		t.Fatalf("Could not delete annotations %s", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected 1 annotation to be deleted, got %d", deleted)
+	}
+	if services.AnnotationExists(a.ID) || !services.AnnotationExists(b.ID) {
+		t.Errorf("Expected only annotations on the video stream to be deleted")
+	}
+
+	history, _ := services.GetAnnotationHistory(a.ID)
+	if history[len(history)-1].Change != services.ChangeDeleted {
+		t.Errorf("Expected last revision to be %s, got %s", services.ChangeDeleted, history[len(history)-1].Change)
+	}
+}
+
+func TestDeleteAnnotationsWithoutFilter(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+
+	_, err := services.DeleteAnnotations(services.AnnotationFilter{}, a.CreatedByID)
+	if !errors.Is(err, services.ErrInvalidBulk) {
+		t.Errorf("Expected ErrInvalidBulk, got %v", err)
+	}
+	if !services.AnnotationExists(a.ID) {
+		t.Errorf("Expected annotation to still exist")
+	}
+}
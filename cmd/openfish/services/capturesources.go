@@ -36,6 +36,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/ausocean/cloud/datastore"
 	"github.com/ausocean/openfish/cmd/openfish/entities"
@@ -210,12 +211,56 @@ func UpdateCaptureSource(id int64, updates PartialCaptureSourceContents) error {
 	}, &captureSource)
 }
 
-// DeleteCaptureSource deletes a capture source.
-func DeleteCaptureSource(id int64) error {
-	// TODO: Check that capture source has no video streams associated with it.
+// DeleteCaptureSource deletes a capture source, moving it to the trash. If the capture source has video streams,
+// ErrHasDependants is returned unless cascade is true, in which case the video streams and their annotations
+// are moved to the trash too. The cascade is all or nothing: if it fails part way, everything it deleted is restored.
+func DeleteCaptureSource(id int64, userID int64, cascade bool) error {
+	if !cascade {
+		videoStreams, err := countDependants(entities.VIDEOSTREAM_KIND, "CaptureSource", id)
+		if err != nil {
+			return err
+		}
+		err = checkDependants(fmt.Sprintf("capture source %d", id), dependants{"video streams", videoStreams})
+		if err != nil {
+			return err
+		}
+		return moveToTrash(TrashCaptureSource, id, userID)
+	}
 
-	// Delete entity.
-	store := globals.GetStore()
-	key := store.IDKey(entities.CAPTURESOURCE_KIND, id)
-	return store.Delete(context.Background(), key)
+	c := &cascadeDelete{userID: userID}
+	err := c.deleteCaptureSource(id)
+	if err != nil {
+		return c.rollback(err)
+	}
+	return nil
+}
+
+// deleteCaptureSource deletes a capture source and its video streams as part of a cascade. Video streams
+// created while the cascade runs are deleted after the capture source.
+func (c *cascadeDelete) deleteCaptureSource(id int64) error {
+	err := c.deleteCaptureSourceDependants(id)
+	if err != nil {
+		return err
+	}
+	err = c.trash(depthParent, TrashCaptureSource, id)
+	if err != nil {
+		return err
+	}
+	return c.deleteCaptureSourceDependants(id)
+}
+
+// deleteCaptureSourceDependants deletes the video streams of a capture source and their annotations.
+func (c *cascadeDelete) deleteCaptureSourceDependants(id int64) error {
+	videoStreams, err := GetVideoStreams(0, 0, nil, &id)
+	if err != nil {
+		return err
+	}
+
+	for _, vs := range videoStreams {
+		err := ignoreDeleted(c.deleteVideoStream(vs.ID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -34,6 +34,7 @@ LICENSE
 package services_test
 
 import (
+	"errors"
 	"os"
 	"testing"
 
@@ -45,6 +46,7 @@ import (
 
 func setup() {
 	globals.InitStore(true)
+	globals.SetStore(testStore{globals.GetStore()})
 	globals.InitStorage(true)
 
 	// Create directories if they do not exist.
@@ -54,6 +56,10 @@ func setup() {
 	os.MkdirAll("store/openfish/Species_v2", os.ModePerm)
 	os.MkdirAll("store/openfish/User", os.ModePerm)
 	os.MkdirAll("store/openfish/Task", os.ModePerm)
+	os.MkdirAll("store/openfish/AnnotationRevision", os.ModePerm)
+	os.MkdirAll("store/openfish/Attribute", os.ModePerm)
+	os.MkdirAll("store/openfish/PointAnnotation", os.ModePerm)
+	os.MkdirAll("store/openfish/Trash", os.ModePerm)
 	os.MkdirAll("openfish-media/images", os.ModePerm)
 	os.MkdirAll("openfish-media/videos", os.ModePerm)
 }
@@ -240,7 +246,7 @@ func TestDeleteCaptureSource(t *testing.T) {
 	}
 
 	// Delete the capture source entity.
-	err = services.DeleteCaptureSource(cs.ID)
+	err = services.DeleteCaptureSource(cs.ID, int64(123456789), false)
 	if err != nil {
 		t.Errorf("Could not delete capture source entity %d: %s", cs.ID, err)
 	}
@@ -254,18 +260,13 @@ func TestDeleteCaptureSource(t *testing.T) {
 func TestDeleteCaptureSourceForNonexistentEntity(t *testing.T) {
 	setup()
 
-	err := services.DeleteCaptureSource(int64(123456789))
+	err := services.DeleteCaptureSource(int64(123456789), int64(123456789), false)
 	if err == nil {
 		t.Errorf("Did not receive expected error when deleting non-existent capture source")
 	}
 }
 
 func TestDeleteCaptureSourceWithAssociatedVideoStreams(t *testing.T) {
-	// TODO: Run test in CI when issue is fixed.
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
 	setup()
 
 	// Create a new capture source entity and a video stream that references it.
@@ -281,8 +282,22 @@ func TestDeleteCaptureSourceWithAssociatedVideoStreams(t *testing.T) {
 		},
 	})
 
-	err := services.DeleteCaptureSource(cs.ID)
-	if err == nil {
+	err := services.DeleteCaptureSource(cs.ID, int64(123456789), false)
+	if !errors.Is(err, services.ErrHasDependants) {
 		t.Errorf("Did not receive expected error when deleting capture source with associated video stream")
 	}
 }
+
+func TestDeleteCaptureSourceCascade(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	vs, _ := services.GetVideoStreamByID(a.VideostreamID)
+
+	err := services.DeleteCaptureSource(vs.CaptureSource, int64(123456789), true)
+	if err != nil {
+		t.Fatalf("Could not delete capture source with cascade %s", err)
+	}
+	if services.CaptureSourceExists(vs.CaptureSource) || services.VideoStreamExists(vs.ID) || services.AnnotationExists(a.ID) {
+		t.Errorf("Expected capture source, video stream and annotation to be deleted")
+	}
+}
@@ -0,0 +1,263 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"strconv"
+
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/mediatype"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+	"golang.org/x/image/draw"
+)
+
+// ErrInvalidChip is returned when a chip is requested with an invalid size, padding or time.
+var ErrInvalidChip = errors.New("invalid chip")
+
+// Limits of the size of chips, in pixels.
+const (
+	MinChipSize = 16
+	MaxChipSize = 1024
+)
+
+// chipQuality is the JPEG quality of chips.
+const chipQuality = 95
+
+// ChipKey is a composite key with all the attributes that define a chip, an image of an annotation
+// cropped from a frame. Chips are keyed by the version of the annotation, so that they are created
+// again when the annotation changes.
+type ChipKey struct {
+	AnnotationID int64
+	Version      int64
+	Time         videotime.VideoTime
+	Size         int
+	Padding      float64
+}
+
+// ToStorageName returns the name used to store the chip in a bucket.
+func (k *ChipKey) ToStorageName() string {
+	return fmt.Sprintf("chips/%d.%d[%s]-%dpx-%s.jpeg", k.AnnotationID, k.Version, k.Time.String(), k.Size, strconv.FormatFloat(k.Padding, 'f', -1, 64))
+}
+
+// validateChip checks the size and padding of a chip.
+func validateChip(size int, padding float64) error {
+	if size < MinChipSize || size > MaxChipSize {
+		return fmt.Errorf("%w: size %d must be between %d and %d", ErrInvalidChip, size, MinChipSize, MaxChipSize)
+	}
+	if !(padding >= 0 && padding <= 1) {
+		return fmt.Errorf("%w: padding %g must be between 0 and 1", ErrInvalidChip, padding)
+	}
+	return nil
+}
+
+// GetChip gets a square JPEG image of size by size pixels, cropped from the frame at time t to the interpolated
+// bounding box of an annotation. Padding is a fraction of the bounding box's width and height added to each side.
+// The frame must have been extracted to media storage as a JPEG. The chip is created and stored the first time it is
+// requested.
+func GetChip(annotationID int64, t videotime.VideoTime, size int, padding float64) ([]byte, error) {
+	if err := validateChip(size, padding); err != nil {
+		return nil, err
+	}
+	annotation, err := GetAnnotationByID(annotationID)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := keypoint.Interpolate(annotation.KeyPoints, t)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidChip, err)
+	}
+
+	key := ChipKey{AnnotationID: annotationID, Version: annotation.Version, Time: t, Size: size, Padding: padding}
+	frameKey := MediaKey{Type: mediatype.JPEG, VideoStreamID: annotation.VideostreamID, StartTime: t}
+	return getChip(key, frameKey, kp.BoundingBox)
+}
+
+// getChip gets the chip with the given key from storage, or creates and stores it by cropping box from the frame.
+func getChip(key ChipKey, frameKey MediaKey, box keypoint.BoundingBox) ([]byte, error) {
+	storage := globals.GetStorage()
+	handle := storage.Object(key.ToStorageName())
+	if exists, _ := handle.Exists(context.Background()); exists {
+		r, err := handle.NewReader(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	}
+
+	frame, err := getFrame(frameKey)
+	if err != nil {
+		return nil, err
+	}
+	data, err := cropChip(frame, box, key.Size, key.Padding)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := handle.NewWriter(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(data)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// getFrame reads and decodes the JPEG image of a frame from media storage.
+func getFrame(key MediaKey) (image.Image, error) {
+	if !MediaExists(key) {
+		return nil, fmt.Errorf("%w: video stream %d at %s", ErrFrameNotFound, key.VideoStreamID, key.StartTime.String())
+	}
+	r, err := NewMediaReader(key, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	img, err := jpeg.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode frame: %w", err)
+	}
+	return img, nil
+}
+
+// cropChip crops box from a frame, with padding added to each side as a fraction of the box's width and height.
+// The crop is widened or heightened to a square around the centre of the box, and moved to fit within the frame
+// where possible, then resized to size by size pixels.
+func cropChip(frame image.Image, box keypoint.BoundingBox, size int, padding float64) ([]byte, error) {
+	bounds := frame.Bounds()
+	fw, fh := float64(bounds.Dx()), float64(bounds.Dy())
+
+	// Convert percentages to pixels and pad.
+	x1, x2 := float64(box.X1)*fw/100, float64(box.X2)*fw/100
+	y1, y2 := float64(box.Y1)*fh/100, float64(box.Y2)*fh/100
+	w, h := (x2-x1)*(1+2*padding), (y2-y1)*(1+2*padding)
+
+	// Make the crop square, and move it within the frame.
+	side := min(max(w, h, 1), fw, fh)
+	cx, cy := (x1+x2)/2, (y1+y2)/2
+	left := min(max(cx-side/2, 0), fw-side)
+	top := min(max(cy-side/2, 0), fh-side)
+	crop := image.Rect(int(left), int(top), int(left+side), int(top+side)).Add(bounds.Min).Intersect(bounds)
+	if crop.Empty() {
+		return nil, fmt.Errorf("%w: bounding box is outside of the frame", ErrInvalidChip)
+	}
+
+	chip := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(chip, chip.Bounds(), frame, crop, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, chip, &jpeg.Options{Quality: chipQuality}); err != nil {
+		return nil, fmt.Errorf("could not encode chip: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ExportChips writes a zip file to w of chips of every verified annotation whose consensus is the given species, for
+// training classifiers. Annotations are sampled every interval milliseconds from the start of their video stream, and
+// chips are only created for frames that have been extracted to media storage. Chips are stored as they are created,
+// and are named by annotation ID and time in milliseconds.
+func ExportChips(w io.Writer, speciesID int64, size int, padding float64, interval int64) error {
+	if err := validateChip(size, padding); err != nil {
+		return err
+	}
+	if interval <= 0 {
+		return fmt.Errorf("%w: interval %d must be greater than zero", ErrInvalidChip, interval)
+	}
+
+	verified := Verified
+	annotations, err := GetAnnotations(0, 0, nil, AnnotationFilter{SpeciesID: &speciesID, Review: &verified})
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	for _, a := range annotations {
+		// Only annotations identified as the species are read, but they are matched by consensus.
+		if a.Consensus.SpeciesID == nil || *a.Consensus.SpeciesID != speciesID {
+			continue
+		}
+		samples, err := keypoint.SampleEvery(a.KeyPoints, interval)
+		if err != nil {
+			return fmt.Errorf("could not sample annotation %d: %w", a.ID, err)
+		}
+		for _, kp := range samples {
+			frameKey := MediaKey{Type: mediatype.JPEG, VideoStreamID: a.VideostreamID, StartTime: kp.Time}
+			key := ChipKey{AnnotationID: a.ID, Version: a.Version, Time: kp.Time, Size: size, Padding: padding}
+			data, err := getChip(key, frameKey, kp.BoundingBox)
+			if errors.Is(err, ErrFrameNotFound) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("could not get chip of annotation %d at %s: %w", a.ID, kp.Time.String(), err)
+			}
+			f, err := zw.Create(fmt.Sprintf("%d_%d.jpeg", a.ID, kp.Time.Int()))
+			if err != nil {
+				return err
+			}
+			if _, err := f.Write(data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return zw.Close()
+}
+
+// StartChipExport starts a task that creates chips of the verified annotations of a species.
+// Once complete, the task's resource is a zip file of the chips.
+func StartChipExport(speciesID int64, size int, padding float64, interval int64) (int64, error) {
+	if err := validateChip(size, padding); err != nil {
+		return 0, err
+	}
+	if interval <= 0 {
+		return 0, fmt.Errorf("%w: interval %d must be greater than zero", ErrInvalidChip, interval)
+	}
+	return startExport("chips.zip", func(w io.Writer) error {
+		return ExportChips(w, speciesID, size, padding, interval)
+	})
+}
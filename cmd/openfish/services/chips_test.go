@@ -0,0 +1,141 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/mediatype"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// createTestFrame extracts a blue frame of 400x200 pixels with a red fish at (40, 140) to (80, 160),
+// which is the bounding box of the test annotation at 1 second.
+func createTestFrame(videoStreamID int64, t videotime.VideoTime) {
+	frame := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	draw.Draw(frame, frame.Bounds(), image.NewUniform(color.RGBA{0, 0, 255, 255}), image.Point{}, draw.Src)
+	draw.Draw(frame, image.Rect(40, 140, 80, 160), image.NewUniform(color.RGBA{255, 0, 0, 255}), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, frame, &jpeg.Options{Quality: 100})
+	services.CreateMedia(services.MediaKey{Type: mediatype.JPEG, VideoStreamID: videoStreamID, StartTime: t}, buf.Bytes())
+}
+
+// isRed reports whether a pixel is close to red, allowing for JPEG compression.
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r>>8 > 200 && g>>8 < 60 && b>>8 < 60
+}
+
+func TestGetChip(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	at := videotime.UncheckedParse("00:00:01.000")
+	createTestFrame(a.VideostreamID, at)
+
+	data, err := services.GetChip(a.ID, at, 32, 0)
+	if err != nil {
+		t.Fatalf("Could not get chip %s", err)
+	}
+	chip, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Could not decode chip %s", err)
+	}
+	if chip.Bounds().Dx() != 32 || chip.Bounds().Dy() != 32 {
+		t.Errorf("Expected 32x32 chip, got %v", chip.Bounds())
+	}
+
+	// The box is made square, so the fish fills the middle half of the chip.
+	if !isRed(chip.At(16, 16)) {
+		t.Errorf("Expected middle of chip to be the fish")
+	}
+	if isRed(chip.At(16, 2)) || isRed(chip.At(16, 29)) {
+		t.Errorf("Expected top and bottom of chip to be background")
+	}
+}
+
+func TestGetChipInvalid(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	at := videotime.UncheckedParse("00:00:01.000")
+
+	tests := []struct {
+		time    videotime.VideoTime
+		size    int
+		padding float64
+		err     error
+	}{
+		{at, 8, 0, services.ErrInvalidChip},
+		{at, 32, -0.5, services.ErrInvalidChip},
+		{videotime.UncheckedParse("00:00:05.000"), 32, 0, services.ErrInvalidChip},
+		{at, 32, 0, services.ErrFrameNotFound},
+	}
+	for _, test := range tests {
+		_, err := services.GetChip(a.ID, test.time, test.size, test.padding)
+		if !errors.Is(err, test.err) {
+			t.Errorf("Expected %v for size %d, padding %g at %s, got %v", test.err, test.size, test.padding, test.time, err)
+		}
+	}
+}
+
+func TestExportChips(t *testing.T) {
+	setup()
+	a := createTestVerifiedAnnotation()
+	createTestFrame(a.VideostreamID, videotime.UncheckedParse("00:00:01.000"))
+
+	var speciesID int64
+	for id := range a.Identifications {
+		speciesID = id
+	}
+
+	var buf bytes.Buffer
+	err := services.ExportChips(&buf, speciesID, 32, 0.1, 1000)
+	if err != nil {
+		t.Fatalf("Could not export chips %s", err)
+	}
+
+	// Only the frame at 1 second has been extracted.
+	files := readZip(t, buf.Bytes())
+	name := fmt.Sprintf("%d_1000.jpeg", a.ID)
+	if _, ok := files[name]; !ok || len(files) != 1 {
+		t.Errorf("Expected only %s, got %d files", name, len(files))
+	}
+}
@@ -0,0 +1,194 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+)
+
+// COCODataset is an object detection dataset in the COCO format, see https://cocodataset.org/#format-data.
+type COCODataset struct {
+	Info        COCOInfo         `json:"info"`
+	Images      []COCOImage      `json:"images"`
+	Annotations []COCOAnnotation `json:"annotations"`
+	Categories  []COCOCategory   `json:"categories"`
+}
+
+// COCOInfo describes a COCO dataset.
+type COCOInfo struct {
+	Description string    `json:"description" example:"OpenFish annotations"`
+	DateCreated time.Time `json:"date_created" example:"2026-05-25T08:00:00Z"`
+}
+
+// COCOImage is a frame of a video stream. The file name is the name of the image in OpenFish's media storage.
+type COCOImage struct {
+	ID            int64  `json:"id" example:"1"`
+	Width         int    `json:"width" example:"1920"`
+	Height        int    `json:"height" example:"1080"`
+	FileName      string `json:"file_name" example:"images/1234567890[00:00:01.000].jpeg"`
+	VideoStreamID int64  `json:"videostream_id" example:"1234567890"`
+	Time          string `json:"time" example:"00:00:01.000"`
+}
+
+// COCOAnnotation is a bounding box in an image. The bounding box is [x, y, width, height] in pixels.
+// Annotations with polygons have a segmentation, which is a single polygon of [x1, y1, x2, y2, ...] in pixels.
+// The track ID is the ID of the OpenFish annotation the bounding box belongs to.
+type COCOAnnotation struct {
+	ID           int64       `json:"id" example:"1"`
+	ImageID      int64       `json:"image_id" example:"1"`
+	CategoryID   int64       `json:"category_id" example:"1"`
+	BBox         [4]float32  `json:"bbox" example:"192,270,576,540"`
+	Segmentation [][]float32 `json:"segmentation,omitempty"`
+	Area         float32     `json:"area" example:"311040"`
+	IsCrowd      int         `json:"iscrowd" example:"0"`
+	TrackID      int64       `json:"track_id" example:"1234567890"`
+}
+
+// COCOCategory is a species.
+type COCOCategory struct {
+	ID            int64  `json:"id" example:"1"`
+	Name          string `json:"name" example:"Rhincodon typus"`
+	Supercategory string `json:"supercategory" example:"species"`
+	SpeciesID     int64  `json:"species_id" example:"1234567890"`
+	CommonName    string `json:"common_name" example:"Whale Shark"`
+}
+
+// toPixels converts a bounding box with percentage coordinates to pixel coordinates, using the resolution of the video stream.
+func toPixels(box keypoint.BoundingBox, vs *VideoStream) keypoint.BoundingBox {
+	w, h := float32(vs.Width)/100, float32(vs.Height)/100
+	return keypoint.BoundingBox{
+		X1: box.X1 * w,
+		X2: box.X2 * w,
+		Y1: box.Y1 * h,
+		Y2: box.Y2 * h,
+	}
+}
+
+// polygonToPixels converts a polygon with percentage coordinates to a COCO segmentation polygon in pixels,
+// using the resolution of the video stream.
+func polygonToPixels(p keypoint.Polygon, vs *VideoStream) keypoint.Polygon {
+	w, h := float32(vs.Width)/100, float32(vs.Height)/100
+	px := make(keypoint.Polygon, len(p))
+	for i, v := range p {
+		px[i] = keypoint.Point{X: v.X * w, Y: v.Y * h}
+	}
+	return px
+}
+
+// ExportCOCO creates a COCO dataset from the annotations matching the filter.
+// Each keypoint of an annotation is a bounding box in the image of the frame it occurs in,
+// and each species is a category.
+func ExportCOCO(filter AnnotationFilter) (*COCODataset, error) {
+	species, frames, err := collectExport(filter, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	dataset := COCODataset{
+		Info: COCOInfo{
+			Description: "OpenFish annotations",
+			DateCreated: time.Now().UTC(),
+		},
+		Images:      make([]COCOImage, 0, len(frames)),
+		Annotations: make([]COCOAnnotation, 0),
+		Categories:  make([]COCOCategory, 0, len(species)),
+	}
+
+	// COCO IDs start at 1.
+	for i, s := range species {
+		dataset.Categories = append(dataset.Categories, COCOCategory{
+			ID:            int64(i + 1),
+			Name:          s.ScientificName,
+			Supercategory: "species",
+			SpeciesID:     s.ID,
+			CommonName:    s.CommonName,
+		})
+	}
+	for i, f := range frames {
+		if f.VideoStream.Width <= 0 || f.VideoStream.Height <= 0 {
+			return nil, fmt.Errorf("video stream %d has no resolution, its width and height must be set to export it", f.VideoStream.ID)
+		}
+		imageID := int64(i + 1)
+		key := f.MediaKey()
+		dataset.Images = append(dataset.Images, COCOImage{
+			ID:            imageID,
+			Width:         f.VideoStream.Width,
+			Height:        f.VideoStream.Height,
+			FileName:      key.ToStorageName(),
+			VideoStreamID: f.VideoStream.ID,
+			Time:          f.Time.String(),
+		})
+		for _, b := range f.Boxes {
+			box := toPixels(b.BoundingBox, f.VideoStream)
+			w, h := box.X2-box.X1, box.Y2-box.Y1
+			annotation := COCOAnnotation{
+				ID:         int64(len(dataset.Annotations) + 1),
+				ImageID:    imageID,
+				CategoryID: int64(b.Category + 1),
+				BBox:       [4]float32{box.X1, box.Y1, w, h},
+				Area:       w * h,
+				TrackID:    b.AnnotationID,
+			}
+			if b.Polygon != nil {
+				polygon := polygonToPixels(b.Polygon, f.VideoStream)
+				segmentation := make([]float32, 0, 2*len(polygon))
+				for _, v := range polygon {
+					segmentation = append(segmentation, v.X, v.Y)
+				}
+				annotation.Segmentation = [][]float32{segmentation}
+				annotation.Area = polygon.Area()
+			}
+			dataset.Annotations = append(dataset.Annotations, annotation)
+		}
+	}
+
+	return &dataset, nil
+}
+
+// StartCOCOExport starts a task that exports the annotations matching the filter as a COCO dataset.
+// Once complete, the task's resource is the JSON file of the dataset.
+func StartCOCOExport(filter AnnotationFilter) (int64, error) {
+	return startExport("coco.json", func(w io.Writer) error {
+		dataset, err := ExportCOCO(filter)
+		if err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(dataset)
+	})
+}
@@ -0,0 +1,185 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"encoding/json"
+	"path"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// createTestAnnotationWithResolution creates a verified annotation on a video stream that is 1000x500 pixels.
+func createTestAnnotationWithResolution() services.Annotation {
+	a := createTestVerifiedAnnotation()
+	width, height := 1000, 500
+	services.UpdateVideoStream(a.VideostreamID, services.PartialVideoStreamContents{Width: &width, Height: &height})
+	return a
+}
+
+func TestExportCOCO(t *testing.T) {
+	setup()
+	a := createTestAnnotationWithResolution()
+
+	dataset, err := services.ExportCOCO(services.AnnotationFilter{VideoStreamID: &a.VideostreamID})
+	if err != nil {
+		t.Fatalf("Could not export COCO dataset %s", err)
+	}
+
+	if len(dataset.Images) != 2 || len(dataset.Annotations) != 2 || len(dataset.Categories) != 1 {
+		t.Fatalf("Expected 2 images, 2 annotations and 1 category, got %d, %d and %d", len(dataset.Images), len(dataset.Annotations), len(dataset.Categories))
+	}
+
+	if dataset.Categories[0].SpeciesID != *a.Consensus.SpeciesID {
+		t.Errorf("Category does not match expected species")
+	}
+
+	image := dataset.Images[0]
+	if image.Width != 1000 || image.Height != 500 || image.Time != "00:00:01.000" {
+		t.Errorf("Image does not match expected, got %+v", image)
+	}
+
+	// First keypoint is x 10%-20%, y 70%-80%.
+	expected := [4]float32{100, 350, 100, 50}
+	ann := dataset.Annotations[0]
+	if ann.BBox != expected || ann.Area != 5000 || ann.ImageID != image.ID || ann.CategoryID != dataset.Categories[0].ID || ann.TrackID != a.ID {
+		t.Errorf("Annotation does not match expected, got %+v", ann)
+	}
+}
+
+func TestExportCOCOPolygons(t *testing.T) {
+	setup()
+	a := createTestAnnotationWithResolution()
+	keypoints := []keypoint.KeyPoint{
+		{
+			Polygon: keypoint.Polygon{{X: 10, Y: 70}, {X: 20, Y: 70}, {X: 20, Y: 80}},
+			Time:    videotime.UncheckedParse("00:00:01.000"),
+		},
+	}
+	current, _ := services.GetAnnotationByID(a.ID)
+	err := services.UpdateAnnotation(a.ID, current.Version, a.CreatedByID, services.PartialAnnotationContents{KeyPoints: &keypoints})
+	if err != nil {
+		t.Fatalf("Could not update annotation %s", err)
+	}
+
+	// Changing the keypoints sends the annotation back for review.
+	services.VerifyAnnotation(a.ID, createTestUserWithRole("curator", role.Curator))
+
+	dataset, err := services.ExportCOCO(services.AnnotationFilter{VideoStreamID: &a.VideostreamID})
+	if err != nil {
+		t.Fatalf("Could not export COCO dataset %s", err)
+	}
+	if len(dataset.Annotations) != 1 {
+		t.Fatalf("Expected 1 annotation, got %d", len(dataset.Annotations))
+	}
+
+	// Polygon is converted to pixels, and its area is half of its bounding box.
+	ann := dataset.Annotations[0]
+	expected := []float32{100, 350, 200, 350, 200, 400}
+	if len(ann.Segmentation) != 1 || !slices.Equal(ann.Segmentation[0], expected) {
+		t.Errorf("Expected segmentation %v, got %v", expected, ann.Segmentation)
+	}
+	if ann.BBox != [4]float32{100, 350, 100, 50} || ann.Area != 2500 {
+		t.Errorf("Annotation does not match expected, got %+v", ann)
+	}
+}
+
+func TestExportCOCOWithoutResolution(t *testing.T) {
+	setup()
+	a := createTestVerifiedAnnotation()
+
+	_, err := services.ExportCOCO(services.AnnotationFilter{VideoStreamID: &a.VideostreamID})
+	if err == nil {
+		t.Errorf("Did not receive expected error when exporting video stream without resolution")
+	}
+}
+
+func TestStartCOCOExport(t *testing.T) {
+	setup()
+	a := createTestAnnotationWithResolution()
+
+	id, err := services.StartCOCOExport(services.AnnotationFilter{VideoStreamID: &a.VideostreamID})
+	if err != nil {
+		t.Fatalf("Could not start COCO export %s", err)
+	}
+
+	// Wait for the task to complete.
+	var task *services.Task
+	for range 100 {
+		task, _ = services.GetTaskById(id)
+		if task.Status != services.Pending {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if task.Status != services.Complete || task.Resource == nil {
+		t.Fatalf("Expected task to complete with a resource, got %+v", task)
+	}
+
+	r, err := services.GetExport(path.Base(task.Resource.Path))
+	if err != nil {
+		t.Fatalf("Could not get export %s", err)
+	}
+	defer r.Close()
+
+	var dataset services.COCODataset
+	err = json.NewDecoder(r).Decode(&dataset)
+	if err != nil {
+		t.Errorf("Could not decode export %s", err)
+	}
+	if len(dataset.Annotations) != 2 {
+		t.Errorf("Expected 2 annotations, got %d", len(dataset.Annotations))
+	}
+}
+
+func TestExportCOCOOnlyVerified(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	width, height := 1000, 500
+	services.UpdateVideoStream(a.VideostreamID, services.PartialVideoStreamContents{Width: &width, Height: &height})
+
+	dataset, err := services.ExportCOCO(services.AnnotationFilter{VideoStreamID: &a.VideostreamID})
+	if err != nil {
+		t.Fatalf("Could not export COCO dataset %s", err)
+	}
+	if len(dataset.Annotations) != 0 {
+		t.Errorf("Expected draft annotation to be excluded, got %d annotations", len(dataset.Annotations))
+	}
+}
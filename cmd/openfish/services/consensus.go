@@ -0,0 +1,161 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ausocean/cloud/datastore"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+)
+
+// ConsensusStatus describes how much agreement there is between the identifications of an annotation.
+type ConsensusStatus string
+
+const (
+	NeedsID  ConsensusStatus = "needs_id" // Not enough identifications have been made to reach a consensus.
+	Disputed ConsensusStatus = "disputed" // Users disagree on the species.
+	Agreed   ConsensusStatus = "agreed"   // Users agree on the species, the annotation is research grade.
+)
+
+// Consensus thresholds. An annotation is agreed upon when the identifications have a total weight of at
+// least minConsensusWeight and the leading species has at least minAgreement of the weight.
+const (
+	minConsensusWeight = 2
+	minAgreement       = 2.0 / 3.0
+)
+
+// identificationWeight returns how much an identification made by a user with the given role
+// counts towards the consensus.
+func identificationWeight(r role.Role) float64 {
+	switch r {
+	case role.Curator, role.Admin:
+		return 2
+	}
+	return 1
+}
+
+// UnmarshalText is used for decoding query params or JSON into a ConsensusStatus.
+func (s *ConsensusStatus) UnmarshalText(text []byte) error {
+	switch status := ConsensusStatus(text); status {
+	case NeedsID, Disputed, Agreed:
+		*s = status
+		return nil
+	}
+	return fmt.Errorf("invalid consensus status provided: %s", text)
+}
+
+// Consensus is the leading species of an annotation's identifications, weighted by the role of the users who made them.
+type Consensus struct {
+	SpeciesID *int64
+	Agreement float64
+	Status    ConsensusStatus
+}
+
+// ConsensusWithJoins is a consensus with the leading species joined.
+type ConsensusWithJoins struct {
+	Species   *SpeciesSummary `json:"species"`
+	Agreement float64         `json:"agreement" example:"0.75"`
+	Status    ConsensusStatus `json:"status" swaggertype:"string" enums:"needs_id,disputed,agreed" example:"agreed"`
+}
+
+// JoinFields joins the leading species of the consensus. The species is left out if it has been deleted.
+func (c *Consensus) JoinFields() (*ConsensusWithJoins, error) {
+	var summary *SpeciesSummary
+	if c.SpeciesID != nil {
+		species, err := GetSpeciesByID(*c.SpeciesID)
+		if err != nil && !errors.Is(err, datastore.ErrNoSuchEntity) {
+			return nil, err
+		}
+		if species != nil {
+			s := species.ToSummary()
+			summary = &s
+		}
+	}
+
+	return &ConsensusWithJoins{
+		Species:   summary,
+		Agreement: c.Agreement,
+		Status:    c.Status,
+	}, nil
+}
+
+// ComputeConsensus computes the consensus of identifications, a map of species IDs to the IDs of the users that identified them.
+// Identifications made by users who have since been deleted are weighted as if made by an annotator.
+func ComputeConsensus(identifications map[int64][]int64) (Consensus, error) {
+	var total float64
+	weights := make(map[int64]float64, len(identifications))
+	for speciesID, userIDs := range identifications {
+		for _, userID := range userIDs {
+			r := role.Annotator
+			user, err := GetUserByID(userID)
+			if err == nil {
+				r = user.Role
+			} else if !errors.Is(err, datastore.ErrNoSuchEntity) {
+				return Consensus{}, fmt.Errorf("could not get user %d to weight identification: %w", userID, err)
+			}
+			w := identificationWeight(r)
+			weights[speciesID] += w
+			total += w
+		}
+	}
+
+	if total == 0 {
+		return Consensus{Status: NeedsID}, nil
+	}
+
+	// Find the leading species, breaking ties using the lowest ID so the result is deterministic.
+	var leading int64
+	for speciesID, w := range weights {
+		if w > weights[leading] || (w == weights[leading] && speciesID < leading) {
+			leading = speciesID
+		}
+	}
+
+	consensus := Consensus{
+		SpeciesID: &leading,
+		Agreement: weights[leading] / total,
+	}
+	switch {
+	case total < minConsensusWeight:
+		consensus.Status = NeedsID
+	case consensus.Agreement >= minAgreement:
+		consensus.Status = Agreed
+	default:
+		consensus.Status = Disputed
+	}
+
+	return consensus, nil
+}
@@ -0,0 +1,203 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/entities"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+)
+
+// createTestUserWithRole creates a user with the given role for use in tests.
+func createTestUserWithRole(name string, r role.Role) int64 {
+	uid, _ := services.CreateUser(services.UserContents{
+		Email:       name + "@example.com",
+		DisplayName: name,
+		Role:        r,
+	})
+	return uid
+}
+
+func TestComputeConsensus(t *testing.T) {
+	setup()
+	annotator1 := createTestUserWithRole("annotator1", role.Annotator)
+	annotator2 := createTestUserWithRole("annotator2", role.Annotator)
+	annotator3 := createTestUserWithRole("annotator3", role.Annotator)
+	curator := createTestUserWithRole("curator", role.Curator)
+	deleted := createTestUserWithRole("deleted.curator", role.Curator)
+	services.DeleteUser(deleted)
+
+	tests := []struct {
+		name            string
+		identifications map[int64][]int64
+		species         int64
+		agreement       float64
+		status          services.ConsensusStatus
+	}{
+		{
+			name:            "no identifications",
+			identifications: map[int64][]int64{},
+			status:          services.NeedsID,
+		},
+		{
+			name:            "single annotator",
+			identifications: map[int64][]int64{1: {annotator1}},
+			species:         1,
+			agreement:       1,
+			status:          services.NeedsID,
+		},
+		{
+			name:            "two annotators agree",
+			identifications: map[int64][]int64{1: {annotator1, annotator2}},
+			species:         1,
+			agreement:       1,
+			status:          services.Agreed,
+		},
+		{
+			name:            "two annotators disagree",
+			identifications: map[int64][]int64{1: {annotator1}, 2: {annotator2}},
+			species:         1,
+			agreement:       0.5,
+			status:          services.Disputed,
+		},
+		{
+			name:            "curator outweighs annotator",
+			identifications: map[int64][]int64{1: {annotator1}, 2: {curator}},
+			species:         2,
+			agreement:       2.0 / 3.0,
+			status:          services.Agreed,
+		},
+		{
+			name:            "curator and annotator outvoted",
+			identifications: map[int64][]int64{1: {annotator1, annotator2, annotator3}, 2: {curator}},
+			species:         1,
+			agreement:       0.6,
+			status:          services.Disputed,
+		},
+		{
+			name:            "deleted user counts as annotator",
+			identifications: map[int64][]int64{1: {annotator1}, 2: {deleted}},
+			species:         1,
+			agreement:       0.5,
+			status:          services.Disputed,
+		},
+	}
+
+	for _, test := range tests {
+		consensus, err := services.ComputeConsensus(test.identifications)
+		if err != nil {
+			t.Errorf("%s: could not compute consensus %s", test.name, err)
+			continue
+		}
+		if consensus.Status != test.status {
+			t.Errorf("%s: expected status %s, got %s", test.name, test.status, consensus.Status)
+		}
+		if consensus.Agreement != test.agreement {
+			t.Errorf("%s: expected agreement %f, got %f", test.name, test.agreement, consensus.Agreement)
+		}
+		if test.species != 0 && (consensus.SpeciesID == nil || *consensus.SpeciesID != test.species) {
+			t.Errorf("%s: expected leading species %d, got %v", test.name, test.species, consensus.SpeciesID)
+		}
+	}
+}
+
+func TestConsensusJoinDeletedSpecies(t *testing.T) {
+	setup()
+	missing := int64(404404404)
+	consensus := services.Consensus{SpeciesID: &missing, Agreement: 1, Status: services.Agreed}
+
+	joined, err := consensus.JoinFields()
+	if err != nil {
+		t.Fatalf("Could not join consensus %s", err)
+	}
+	if joined.Species != nil {
+		t.Errorf("Expected deleted species to be left out, got %+v", joined.Species)
+	}
+}
+
+func TestConsensusRecomputedOnIdentification(t *testing.T) {
+	setup()
+	annotation := createTestAnnotation()
+	if annotation.Consensus.Status != services.NeedsID {
+		t.Errorf("Expected new annotation with one identification to need ID, got %s", annotation.Consensus.Status)
+	}
+
+	// A second annotator agrees with the first.
+	uid := createTestUserWithRole("sandy.whiting", role.Annotator)
+	for speciesID := range annotation.Identifications {
+		services.AddIdentification(annotation.ID, uid, speciesID)
+	}
+
+	modified, _ := services.GetAnnotationByID(annotation.ID)
+	if modified.Consensus.Status != services.Agreed {
+		t.Errorf("Expected annotation to be agreed, got %s", modified.Consensus.Status)
+	}
+
+	agreed := services.Agreed
+	filter := services.AnnotationFilter{VideoStreamID: &annotation.VideostreamID, Consensus: &agreed}
+	annotations, err := services.GetAnnotations(0, 0, nil, filter)
+	if err != nil {
+		t.Errorf("Could not get annotations %s", err)
+	}
+	if len(annotations) != 1 {
+		t.Errorf("Expected 1 agreed annotation, got %d", len(annotations))
+	}
+}
+
+func TestFilterAnnotationsWithoutConsensus(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+
+	// Annotations created before consensus was computed have no stored status.
+	store := globals.GetStore()
+	key := store.IDKey(entities.ANNOTATION_KIND, a.ID)
+	var e entities.Annotation
+	store.Get(context.Background(), key, &e)
+	e.ConsensusStatus = ""
+	store.Put(context.Background(), key, &e)
+
+	needsID := services.NeedsID
+	filter := services.AnnotationFilter{VideoStreamID: &a.VideostreamID, Consensus: &needsID}
+	annotations, err := services.GetAnnotations(0, 0, nil, filter)
+	if err != nil {
+		t.Errorf("Could not get annotations %s", err)
+	}
+	if len(annotations) != 1 || annotations[0].ID != a.ID {
+		t.Errorf("Expected annotation without a consensus status to need ID, got %d annotations", len(annotations))
+	}
+}
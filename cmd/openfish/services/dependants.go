@@ -0,0 +1,142 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ausocean/cloud/datastore"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+)
+
+// ErrHasDependants is returned when deleting an entity that other entities refer to, without cascading.
+var ErrHasDependants = errors.New("entity has dependants")
+
+// dependants is the number of entities of one kind that refer to an entity.
+type dependants struct {
+	kind  string
+	count int
+}
+
+// countDependants counts the entities of a kind that refer to an entity in the given field. Only keys
+// are read, so that entities with many dependants can be checked cheaply.
+func countDependants(kind string, field string, id int64) (int, error) {
+	store := globals.GetStore()
+	query := store.NewQuery(kind, true)
+	query.FilterField(field, "=", id)
+	keys, err := store.GetAll(context.Background(), query, nil)
+	return len(keys), err
+}
+
+// checkDependants returns ErrHasDependants with the number of each kind of dependant if there are any,
+// or nil if there are none.
+func checkDependants(entity string, deps ...dependants) error {
+	var counts []string
+	for _, d := range deps {
+		if d.count > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", d.count, d.kind))
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s has %s, use cascade to delete them", ErrHasDependants, entity, strings.Join(counts, " and "))
+}
+
+// ignoreDeleted returns nil if err is because an entity no longer exists, so that a cascade skips dependants
+// that were deleted by a previous attempt or by another request.
+func ignoreDeleted(err error) error {
+	if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return nil
+	}
+	return err
+}
+
+// Depths of entities in a cascade. Entities are restored in order of depth when a cascade is rolled back,
+// so that each entity is restored before the entities that depend on it.
+const (
+	depthParent      = iota // Capture sources and species.
+	depthVideoStream        // Video streams.
+	depthAnnotation         // Annotations, point annotations and identifications.
+)
+
+// undo is a change made by a cascade, and how to undo it.
+type undo struct {
+	depth int
+	fn    func() error
+}
+
+// cascadeDelete deletes an entity and its dependants. Datastore transactions are limited to a single entity,
+// so instead the cascade records how to undo each change it makes, and is rolled back if it fails part way,
+// so that either everything or nothing is deleted.
+type cascadeDelete struct {
+	userID int64
+	undos  []undo
+}
+
+// do makes a change, recording how to undo it if it succeeds.
+func (c *cascadeDelete) do(depth int, fn func() error, undoFn func() error) error {
+	err := fn()
+	if err == nil {
+		c.undos = append(c.undos, undo{depth, undoFn})
+	}
+	return err
+}
+
+// trash moves an entity to the trash, recording that it is restored if the cascade is rolled back.
+func (c *cascadeDelete) trash(depth int, kind TrashKind, id int64) error {
+	deleteFn := func() error { return moveToTrash(kind, id, c.userID) }
+	if kind == TrashAnnotation {
+		deleteFn = func() error { return DeleteAnnotation(id, c.userID) }
+	}
+	return c.do(depth, deleteFn, func() error { return RestoreFromTrash(kind, id, c.userID) })
+}
+
+// rollback undoes the changes made by the cascade, after it failed with err. Changes are undone in order of
+// depth, and the most recent first within each depth. Any errors from undoing changes are joined with err.
+func (c *cascadeDelete) rollback(err error) error {
+	undos := slices.Clone(c.undos)
+	slices.Reverse(undos)
+	slices.SortStableFunc(undos, func(a, b undo) int { return cmp.Compare(a.depth, b.depth) })
+	for _, u := range undos {
+		err = errors.Join(err, u.fn())
+	}
+	c.undos = nil
+	return err
+}
@@ -0,0 +1,178 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// DefaultDuplicateThreshold is the IoU above which overlapping annotations are considered duplicates,
+// if no threshold is given.
+const DefaultDuplicateThreshold = 0.5
+
+// ErrInvalidMerge is returned when two annotations cannot be merged.
+var ErrInvalidMerge = errors.New("invalid merge")
+
+// Duplicate is a pair of annotations that are likely to be of the same individual, because they overlap in time
+// and their bounding boxes mostly overlap. IoU is the mean intersection over union of their bounding boxes
+// between Start and End, the time span where both annotations are present.
+type Duplicate struct {
+	Annotations [2]int64            `json:"annotations" example:"1234567890,1234567891"`
+	IoU         float32             `json:"iou" example:"0.85"`
+	Start       videotime.VideoTime `json:"start" swaggertype:"string" example:"00:01:05.000"`
+	End         videotime.VideoTime `json:"end" swaggertype:"string" example:"00:01:10.500"`
+}
+
+// meanIoU returns the mean IoU of two annotations' interpolated bounding boxes, sampled at the start and end of
+// the time span and at every keypoint of either annotation within it.
+func meanIoU(a []keypoint.KeyPoint, b []keypoint.KeyPoint, start int64, end int64) (float32, error) {
+	times := []int64{start, end}
+	for _, kp := range slices.Concat(a, b) {
+		if t := kp.Time.Int(); t > start && t < end {
+			times = append(times, t)
+		}
+	}
+	slices.Sort(times)
+	times = slices.Compact(times)
+
+	var sum float32
+	for _, t := range times {
+		ka, err := keypoint.Interpolate(a, videotime.FromInt(t))
+		if err != nil {
+			return 0, err
+		}
+		kb, err := keypoint.Interpolate(b, videotime.FromInt(t))
+		if err != nil {
+			return 0, err
+		}
+		sum += keypoint.IoU(ka.BoundingBox, kb.BoundingBox)
+	}
+	return sum / float32(len(times)), nil
+}
+
+// FindDuplicates finds pairs of annotations on a video stream whose time spans overlap and whose mean IoU is at least
+// threshold. Duplicates are sorted by the time they start to overlap.
+func FindDuplicates(videoStreamID int64, threshold float32) ([]Duplicate, error) {
+	if !(threshold > 0 && threshold <= 1) {
+		return nil, fmt.Errorf("invalid threshold %f, must be greater than 0 and at most 1", threshold)
+	}
+
+	annotations, err := GetAnnotations(0, 0, nil, AnnotationFilter{VideoStreamID: &videoStreamID})
+	if err != nil {
+		return nil, err
+	}
+	start := func(a Annotation) int64 { return a.KeyPoints[0].Time.Int() }
+	end := func(a Annotation) int64 { return a.KeyPoints[len(a.KeyPoints)-1].Time.Int() }
+	slices.SortFunc(annotations, func(a, b Annotation) int { return cmp.Compare(start(a), start(b)) })
+
+	// Sweep through the annotations in order of start time, comparing each with the later annotations that start before it ends.
+	duplicates := make([]Duplicate, 0)
+	for i, a := range annotations {
+		for _, b := range annotations[i+1:] {
+			if start(b) > end(a) {
+				break
+			}
+			overlapStart, overlapEnd := start(b), min(end(a), end(b))
+			iou, err := meanIoU(a.KeyPoints, b.KeyPoints, overlapStart, overlapEnd)
+			if err != nil {
+				return nil, fmt.Errorf("could not compare annotations %d and %d: %w", a.ID, b.ID, err)
+			}
+			if iou >= threshold {
+				duplicates = append(duplicates, Duplicate{
+					Annotations: [2]int64{a.ID, b.ID},
+					IoU:         iou,
+					Start:       videotime.FromInt(overlapStart),
+					End:         videotime.FromInt(overlapEnd),
+				})
+			}
+		}
+	}
+	slices.SortStableFunc(duplicates, func(a, b Duplicate) int { return cmp.Compare(a.Start.Int(), b.Start.Int()) })
+
+	return duplicates, nil
+}
+
+// MergeAnnotations merges the annotation with ID mergeID into the annotation with ID keepID, then deletes it.
+// The identifications of both annotations are combined, and attributes that the kept annotation does not have are copied.
+// The kept annotation's keypoints are unchanged. The merge is not transactional, so if the merged annotation cannot
+// be deleted the kept annotation is restored to its previous version. ErrVersionMismatch is returned if the kept
+// annotation is modified during the merge.
+func MergeAnnotations(keepID int64, mergeID int64, userID int64) error {
+	if keepID == mergeID {
+		return fmt.Errorf("%w: cannot merge an annotation into itself", ErrInvalidMerge)
+	}
+	keep, err := GetAnnotationByID(keepID)
+	if err != nil {
+		return err
+	}
+	merge, err := GetAnnotationByID(mergeID)
+	if err != nil {
+		return err
+	}
+	if keep.VideostreamID != merge.VideostreamID {
+		return fmt.Errorf("%w: annotations are on different video streams", ErrInvalidMerge)
+	}
+
+	rev := revisionInfo{change: ChangeMerged, userID: userID}
+	err = modifyAnnotation(keepID, &keep.Version, rev, func(a *AnnotationContents) error {
+		for speciesID, userIDs := range merge.Identifications {
+			for _, id := range userIDs {
+				if !slices.Contains(a.Identifications[speciesID], id) {
+					a.Identifications[speciesID] = append(a.Identifications[speciesID], id)
+				}
+			}
+		}
+		for name, value := range merge.Attributes {
+			if _, ok := a.Attributes[name]; !ok {
+				a.Attributes[name] = value
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = DeleteAnnotation(mergeID, userID)
+	if err != nil {
+		return errors.Join(err, RestoreAnnotation(keepID, keep.Version, userID))
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ausocean/cloud/datastore"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// createTestAnnotationWithBoxes creates an annotation on a video stream with a keypoint at each of the times.
+func createTestAnnotationWithBoxes(vs int64, user int64, species int64, box keypoint.BoundingBox, times ...string) services.Annotation {
+	keypoints := make([]keypoint.KeyPoint, len(times))
+	for i, t := range times {
+		keypoints[i] = keypoint.KeyPoint{BoundingBox: box, Time: videotime.UncheckedParse(t)}
+	}
+	created, _ := services.CreateAnnotation(services.AnnotationContents{
+		KeyPoints:       keypoints,
+		VideostreamID:   vs,
+		Identifications: map[int64][]int64{species: {user}},
+		CreatedByID:     user,
+	})
+	return *created
+}
+
+func TestFindDuplicates(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	sp := createTestSpecies()
+	alice := createTestUserWithRole("alice", role.Annotator)
+	bob := createTestUserWithRole("bob", role.Annotator)
+
+	box := keypoint.BoundingBox{X1: 10, Y1: 10, X2: 20, Y2: 20}
+	shifted := keypoint.BoundingBox{X1: 11, Y1: 10, X2: 21, Y2: 20}
+	elsewhere := keypoint.BoundingBox{X1: 50, Y1: 50, X2: 60, Y2: 60}
+	a := createTestAnnotationWithBoxes(vs.ID, alice, sp.ID, box, "00:00:01.000", "00:00:03.000")
+	b := createTestAnnotationWithBoxes(vs.ID, bob, sp.ID, shifted, "00:00:02.000", "00:00:04.000")
+	createTestAnnotationWithBoxes(vs.ID, bob, sp.ID, elsewhere, "00:00:01.000", "00:00:03.000")
+	createTestAnnotationWithBoxes(vs.ID, bob, sp.ID, box, "00:00:05.000", "00:00:06.000")
+
+	duplicates, err := services.FindDuplicates(vs.ID, services.DefaultDuplicateThreshold)
+	if err != nil {
+		t.Fatalf("Could not find duplicates %s", err)
+	}
+	if len(duplicates) != 1 {
+		t.Fatalf("Expected 1 duplicate, got %d", len(duplicates))
+	}
+	d := duplicates[0]
+	if d.Annotations != [2]int64{a.ID, b.ID} || d.Start.String() != "00:00:02.000" || d.End.String() != "00:00:03.000" {
+		t.Errorf("Duplicate does not match expected, got %+v", d)
+	}
+	if d.IoU != float32(90)/110 {
+		t.Errorf("Expected IoU of 9/11, got %f", d.IoU)
+	}
+
+	duplicates, _ = services.FindDuplicates(vs.ID, 0.9)
+	if len(duplicates) != 0 {
+		t.Errorf("Expected no duplicates above IoU 0.9, got %d", len(duplicates))
+	}
+}
+
+func TestMergeAnnotations(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	sp := createTestSpecies()
+	alice := createTestUserWithRole("alice", role.Annotator)
+	bob := createTestUserWithRole("bob", role.Annotator)
+	curator := createTestUserWithRole("curator", role.Curator)
+
+	box := keypoint.BoundingBox{X1: 10, Y1: 10, X2: 20, Y2: 20}
+	a := createTestAnnotationWithBoxes(vs.ID, alice, sp.ID, box, "00:00:01.000", "00:00:03.000")
+	b := createTestAnnotationWithBoxes(vs.ID, bob, sp.ID, box, "00:00:02.000", "00:00:04.000")
+
+	err := services.MergeAnnotations(a.ID, b.ID, curator)
+	if err != nil {
+		t.Fatalf("Could not merge annotations %s", err)
+	}
+
+	merged, _ := services.GetAnnotationByID(a.ID)
+	if len(merged.Identifications[sp.ID]) != 2 {
+		t.Errorf("Expected identifications to be combined, got %v", merged.Identifications)
+	}
+	if services.AnnotationExists(b.ID) {
+		t.Errorf("Expected merged annotation to be deleted")
+	}
+
+	history, _ := services.GetAnnotationHistory(a.ID)
+	if history[len(history)-1].Change != services.ChangeMerged {
+		t.Errorf("Expected last revision to be %s, got %s", services.ChangeMerged, history[len(history)-1].Change)
+	}
+}
+
+func TestMergeAnnotationsInvalid(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	b := createTestAnnotation()
+
+	err := services.MergeAnnotations(a.ID, a.ID, a.CreatedByID)
+	if !errors.Is(err, services.ErrInvalidMerge) {
+		t.Errorf("Expected ErrInvalidMerge when merging an annotation into itself, got %v", err)
+	}
+
+	// Test annotations are on different video streams.
+	err = services.MergeAnnotations(a.ID, b.ID, a.CreatedByID)
+	if !errors.Is(err, services.ErrInvalidMerge) {
+		t.Errorf("Expected ErrInvalidMerge when merging annotations on different video streams, got %v", err)
+	}
+
+	err = services.MergeAnnotations(a.ID, 1, a.CreatedByID)
+	if !errors.Is(err, datastore.ErrNoSuchEntity) {
+		t.Errorf("Expected ErrNoSuchEntity when merging an annotation that does not exist, got %v", err)
+	}
+}
@@ -0,0 +1,346 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"archive/zip"
+	"cmp"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Darwin Core terms and row types, see https://dwc.tdwg.org/terms/ and https://dwc.tdwg.org/text/.
+const (
+	dwcNamespace       = "http://rs.tdwg.org/dwc/terms/"
+	dwcEventRow        = "http://rs.tdwg.org/dwc/terms/Event"
+	dwcOccurrenceRow   = "http://rs.tdwg.org/dwc/terms/Occurrence"
+	dwcTextNamespace   = "http://rs.tdwg.org/dwc/text/"
+	inaturalistTaxaURL = "https://www.inaturalist.org/taxa/"
+)
+
+// Columns of the event and occurrence files. The first column of each is the event ID, which links
+// occurrences to their event.
+var (
+	dwcEventColumns      = []string{"eventID", "eventDate", "decimalLatitude", "decimalLongitude", "geodeticDatum", "locationID", "locality", "samplingProtocol"}
+	dwcOccurrenceColumns = []string{"eventID", "occurrenceID", "basisOfRecord", "occurrenceStatus", "eventDate", "scientificName", "vernacularName", "taxonID", "individualCount", "recordedBy"}
+)
+
+// dwcEventID returns the ID of the event for a video stream.
+func dwcEventID(videoStreamID int64) string {
+	return fmt.Sprintf("urn:openfish:videostream:%d", videoStreamID)
+}
+
+// dwcOccurrenceID returns the ID of the occurrence for an annotation.
+func dwcOccurrenceID(annotationID int64) string {
+	return fmt.Sprintf("urn:openfish:annotation:%d", annotationID)
+}
+
+// dwcArchive is the contents of a Darwin Core Archive, with a row for each event and occurrence.
+type dwcArchive struct {
+	events      [][]string
+	occurrences [][]string
+}
+
+// collectDwCA gets the verified annotations matching filter as occurrences, with each video stream as an event.
+// Each annotation is an occurrence of one individual of its consensus species when it starts, recorded by the
+// users that identified that species. Events are sorted by video stream and occurrences by event and date.
+func collectDwCA(filter AnnotationFilter) (*dwcArchive, error) {
+	verified := Verified
+	filter.Review = &verified
+	annotations, err := GetAnnotations(0, 0, nil, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	videoStreams := make(map[int64]*VideoStream)
+	species := make(map[int64]*Species)
+	users := make(map[int64]string)
+	archive := &dwcArchive{}
+
+	type occurrence struct {
+		date time.Time
+		row  []string
+	}
+	var occurrences []occurrence
+	for _, a := range annotations {
+		if a.Consensus.SpeciesID == nil || len(a.KeyPoints) == 0 {
+			continue
+		}
+
+		// Each video stream is an event, at the location of its capture source.
+		vs, ok := videoStreams[a.VideostreamID]
+		if !ok {
+			vs, err = GetVideoStreamByID(a.VideostreamID)
+			if err != nil {
+				return nil, fmt.Errorf("could not get video stream %d: %w", a.VideostreamID, err)
+			}
+			cs, err := GetCaptureSourceByID(vs.CaptureSource)
+			if err != nil {
+				return nil, fmt.Errorf("could not get capture source %d: %w", vs.CaptureSource, err)
+			}
+			videoStreams[vs.ID] = vs
+
+			date := vs.StartTime.UTC().Format(time.RFC3339)
+			if vs.EndTime != nil {
+				date += "/" + vs.EndTime.UTC().Format(time.RFC3339)
+			}
+			archive.events = append(archive.events, []string{
+				dwcEventID(vs.ID),
+				date,
+				strconv.FormatFloat(cs.Location.Lat, 'f', -1, 64),
+				strconv.FormatFloat(cs.Location.Lng, 'f', -1, 64),
+				"WGS84",
+				strconv.FormatInt(cs.ID, 10),
+				cs.Name,
+				"underwater video",
+			})
+		}
+
+		s, ok := species[*a.Consensus.SpeciesID]
+		if !ok {
+			s, err = GetSpeciesByID(*a.Consensus.SpeciesID)
+			if err != nil {
+				return nil, fmt.Errorf("could not get species %d: %w", *a.Consensus.SpeciesID, err)
+			}
+			species[s.ID] = s
+		}
+		var taxonID string
+		if s.INaturalistTaxonID != nil {
+			taxonID = inaturalistTaxaURL + strconv.Itoa(*s.INaturalistTaxonID)
+		}
+
+		// The occurrence is recorded by the users that identified the species.
+		var recordedBy []string
+		for _, id := range a.Identifications[s.ID] {
+			name, ok := users[id]
+			if !ok {
+				user, err := GetUserByID(id)
+				if err != nil {
+					return nil, fmt.Errorf("could not get user %d: %w", id, err)
+				}
+				name = user.DisplayName
+				users[id] = name
+			}
+			recordedBy = append(recordedBy, name)
+		}
+
+		date := vs.TimeAt(a.KeyPoints[0].Time).UTC()
+		occurrences = append(occurrences, occurrence{date, []string{
+			dwcEventID(vs.ID),
+			dwcOccurrenceID(a.ID),
+			"MachineObservation",
+			"present",
+			date.Format("2006-01-02T15:04:05.000Z"),
+			s.ScientificName,
+			s.CommonName,
+			taxonID,
+			"1",
+			strings.Join(recordedBy, " | "),
+		}})
+	}
+
+	slices.SortFunc(archive.events, func(a, b []string) int { return cmp.Compare(a[0], b[0]) })
+	slices.SortStableFunc(occurrences, func(a, b occurrence) int {
+		return cmp.Or(cmp.Compare(a.row[0], b.row[0]), a.date.Compare(b.date))
+	})
+	for _, o := range occurrences {
+		archive.occurrences = append(archive.occurrences, o.row)
+	}
+
+	return archive, nil
+}
+
+// dwcMetaField is a column of a file in a Darwin Core Archive.
+type dwcMetaField struct {
+	Index int    `xml:"index,attr"`
+	Term  string `xml:"term,attr"`
+}
+
+// dwcMetaFile describes a file in a Darwin Core Archive.
+type dwcMetaFile struct {
+	Encoding           string         `xml:"encoding,attr"`
+	FieldsTerminatedBy string         `xml:"fieldsTerminatedBy,attr"`
+	LinesTerminatedBy  string         `xml:"linesTerminatedBy,attr"`
+	FieldsEnclosedBy   string         `xml:"fieldsEnclosedBy,attr"`
+	IgnoreHeaderLines  int            `xml:"ignoreHeaderLines,attr"`
+	RowType            string         `xml:"rowType,attr"`
+	Location           string         `xml:"files>location"`
+	ID                 *dwcMetaField  `xml:"id,omitempty"`
+	CoreID             *dwcMetaField  `xml:"coreid,omitempty"`
+	Fields             []dwcMetaField `xml:"field"`
+}
+
+// dwcMeta is the meta.xml descriptor of a Darwin Core Archive.
+type dwcMeta struct {
+	XMLName   xml.Name    `xml:"archive"`
+	Namespace string      `xml:"xmlns,attr"`
+	Metadata  string      `xml:"metadata,attr"`
+	Core      dwcMetaFile `xml:"core"`
+	Extension dwcMetaFile `xml:"extension"`
+}
+
+// newDwCMetaFile describes a CSV file in the archive with the given columns, the first of which is the ID.
+func newDwCMetaFile(rowType string, location string, columns []string) dwcMetaFile {
+	f := dwcMetaFile{
+		Encoding:           "UTF-8",
+		FieldsTerminatedBy: ",",
+		LinesTerminatedBy:  `\n`,
+		FieldsEnclosedBy:   `"`,
+		IgnoreHeaderLines:  1,
+		RowType:            rowType,
+		Location:           location,
+	}
+	for i, c := range columns {
+		f.Fields = append(f.Fields, dwcMetaField{Index: i, Term: dwcNamespace + c})
+	}
+	return f
+}
+
+// dwcEML is a minimal Ecological Metadata Language document describing the dataset.
+type dwcEML struct {
+	XMLName   xml.Name `xml:"eml:eml"`
+	Namespace string   `xml:"xmlns:eml,attr"`
+	PackageID string   `xml:"packageId,attr"`
+	System    string   `xml:"system,attr"`
+	Dataset   struct {
+		Title   string `xml:"title"`
+		Creator struct {
+			Organization string `xml:"organizationName"`
+		} `xml:"creator"`
+		PubDate  string `xml:"pubDate"`
+		Language string `xml:"language"`
+		Abstract struct {
+			Para string `xml:"para"`
+		} `xml:"abstract"`
+		Contact struct {
+			Organization string `xml:"organizationName"`
+		} `xml:"contact"`
+	} `xml:"dataset"`
+}
+
+// writeXMLFile writes v as an XML file in the zip.
+func writeXMLFile(zw *zip.Writer, name string, v any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(f)
+	enc.Indent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeCSVFile writes a header and rows as a CSV file in the zip.
+func writeCSVFile(zw *zip.Writer, name string, header []string, rows [][]string) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(f)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// ExportDwCA writes a zip file of a Darwin Core Archive to w, from the verified annotations matching the filter, for
+// publishing to GBIF and OBIS. Each video stream is a sampling event in event.txt, at the location of its capture source,
+// and each annotation is an occurrence of its consensus species in occurrence.txt. The archive is described by meta.xml,
+// and the dataset by eml.xml.
+func ExportDwCA(w io.Writer, filter AnnotationFilter) error {
+	archive, err := collectDwCA(filter)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+
+	occurrences := newDwCMetaFile(dwcOccurrenceRow, "occurrence.txt", dwcOccurrenceColumns)
+	occurrences.CoreID = &dwcMetaField{Index: 0}
+	occurrences.Fields = occurrences.Fields[1:] // The event ID is the core ID.
+	events := newDwCMetaFile(dwcEventRow, "event.txt", dwcEventColumns)
+	events.ID = &dwcMetaField{Index: 0}
+	meta := dwcMeta{
+		Namespace: dwcTextNamespace,
+		Metadata:  "eml.xml",
+		Core:      events,
+		Extension: occurrences,
+	}
+	if err := writeXMLFile(zw, "meta.xml", meta); err != nil {
+		return err
+	}
+
+	eml := dwcEML{
+		Namespace: "https://eml.ecoinformatics.org/eml-2.2.0",
+		PackageID: fmt.Sprintf("openfish-%d", time.Now().Unix()),
+		System:    "OpenFish",
+	}
+	eml.Dataset.Title = "OpenFish verified sightings"
+	eml.Dataset.Creator.Organization = "AusOcean"
+	eml.Dataset.PubDate = time.Now().UTC().Format(time.DateOnly)
+	eml.Dataset.Language = "en"
+	eml.Dataset.Abstract.Para = "Occurrences of marine species annotated in underwater video and verified by curators using OpenFish."
+	eml.Dataset.Contact.Organization = "AusOcean"
+	if err := writeXMLFile(zw, "eml.xml", eml); err != nil {
+		return err
+	}
+
+	if err := writeCSVFile(zw, "event.txt", dwcEventColumns, archive.events); err != nil {
+		return err
+	}
+	if err := writeCSVFile(zw, "occurrence.txt", dwcOccurrenceColumns, archive.occurrences); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// StartDwCAExport starts a task that exports the annotations matching the filter as a Darwin Core Archive.
+// Once complete, the task's resource is the zip file of the archive.
+func StartDwCAExport(filter AnnotationFilter) (int64, error) {
+	return startExport("dwca.zip", func(w io.Writer) error {
+		return ExportDwCA(w, filter)
+	})
+}
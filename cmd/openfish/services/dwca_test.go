@@ -0,0 +1,83 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+)
+
+func TestExportDwCA(t *testing.T) {
+	setup()
+	a := createTestVerifiedAnnotation()
+
+	var buf bytes.Buffer
+	err := services.ExportDwCA(&buf, services.AnnotationFilter{VideoStreamID: &a.VideostreamID})
+	if err != nil {
+		t.Fatalf("Could not export Darwin Core Archive %s", err)
+	}
+	files := readZip(t, buf.Bytes())
+
+	for _, name := range []string{"meta.xml", "eml.xml", "event.txt", "occurrence.txt"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("Expected %s in archive, got %d files", name, len(files))
+		}
+	}
+	if !strings.Contains(files["meta.xml"], `rowType="http://rs.tdwg.org/dwc/terms/Event"`) ||
+		!strings.Contains(files["meta.xml"], `<location>occurrence.txt</location>`) {
+		t.Errorf("Unexpected meta.xml %s", files["meta.xml"])
+	}
+
+	events := strings.Split(strings.TrimSpace(files["event.txt"]), "\n")
+	if len(events) != 2 {
+		t.Fatalf("Expected 1 event, got %d lines", len(events))
+	}
+	expected := fmt.Sprintf("urn:openfish:videostream:%d,2023-01-01T08:00:00Z/2023-01-01T16:00:00Z,-37,145,WGS84,", a.VideostreamID)
+	if !strings.HasPrefix(events[1], expected) {
+		t.Errorf("Expected event %q, got %q", expected, events[1])
+	}
+
+	occurrences := strings.Split(strings.TrimSpace(files["occurrence.txt"]), "\n")
+	if len(occurrences) != 2 {
+		t.Fatalf("Expected 1 occurrence, got %d lines", len(occurrences))
+	}
+	expected = fmt.Sprintf("urn:openfish:videostream:%d,urn:openfish:annotation:%d,MachineObservation,present,2023-01-01T08:00:01.000Z,Sepioteuthis australis,Southern Reef Squid,,1,Coral Fischer", a.VideostreamID, a.ID)
+	if occurrences[1] != expected {
+		t.Errorf("Expected occurrence %q, got %q", expected, occurrences[1])
+	}
+}
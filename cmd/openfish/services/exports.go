@@ -0,0 +1,241 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"regexp"
+	"slices"
+
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/mediatype"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// exportNamePattern matches the names of files created by export tasks.
+var exportNamePattern = regexp.MustCompile(`^[0-9]+\.[a-z]+(\.[a-z]+)?$`)
+
+// exportStorageName returns the name used to store an export in a bucket.
+func exportStorageName(name string) string {
+	return "exports/" + name
+}
+
+// ValidExportName checks that name is the name of a file created by an export task.
+func ValidExportName(name string) bool {
+	return exportNamePattern.MatchString(name)
+}
+
+// GetExport returns a reader for an export created by an export task.
+// The caller is responsible for closing the reader.
+func GetExport(name string) (io.ReadCloser, error) {
+	if !ValidExportName(name) {
+		return nil, fmt.Errorf("invalid export name: %s", name)
+	}
+
+	storage := globals.GetStorage()
+	handle := storage.Object(exportStorageName(name))
+	return handle.NewReader(context.Background())
+}
+
+// startExport creates a task and asynchronously runs export, writing its output to storage.
+// The export is named using the task ID and ext, and is attached to the task as its resource once complete.
+func startExport(ext string, export func(w io.Writer) error) (int64, error) {
+	id, err := CreateTask()
+	if err != nil {
+		return 0, err
+	}
+	name := fmt.Sprintf("%d.%s", id, ext)
+
+	go func() {
+		err := writeExport(name, export)
+		if err != nil {
+			err = FailTask(id, err)
+		} else {
+			err = CompleteTask(id, &url.URL{Path: "/api/v1/exports/" + name})
+		}
+		// There is no caller to return the error to, so it is logged instead.
+		if err != nil {
+			log.Printf("could not update export task %d: %v", id, err)
+		}
+	}()
+
+	return id, nil
+}
+
+// writeExport runs export, writing its output to storage. If the export fails, anything written is deleted.
+func writeExport(name string, export func(w io.Writer) error) error {
+	storage := globals.GetStorage()
+	handle := storage.Object(exportStorageName(name))
+	w, err := handle.NewWriter(context.Background())
+	if err != nil {
+		return err
+	}
+
+	err = export(w)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		handle.Delete(context.Background())
+		return err
+	}
+
+	return nil
+}
+
+// exportFrame is a frame of a video stream, with the bounding boxes of the annotations visible in it.
+type exportFrame struct {
+	VideoStream *VideoStream
+	Time        videotime.VideoTime
+	Boxes       []exportBox
+}
+
+// exportBox is the bounding box, and polygon if it has one, of an annotation in a frame.
+type exportBox struct {
+	AnnotationID int64
+	Category     int // Index of the species in the export's categories.
+	BoundingBox  keypoint.BoundingBox
+	Polygon      keypoint.Polygon
+}
+
+// MediaKey returns the key of the image of the frame.
+func (f *exportFrame) MediaKey() MediaKey {
+	return MediaKey{
+		Type:          mediatype.JPEG,
+		VideoStreamID: f.VideoStream.ID,
+		StartTime:     f.Time,
+	}
+}
+
+// collectExport gets the annotations matching filter and groups their keypoints into frames, for exporting as a dataset.
+// If interval is zero, the keypoints of each annotation are used, otherwise annotations are sampled every interval
+// milliseconds from the start of the video stream, so that overlapping annotations share frames, as well as at their
+// first keypoint.
+// Only verified annotations are exported. Each annotation is labelled with its consensus species, annotations without
+// any identifications are skipped. Categories are sorted by species ID and frames are sorted by video stream and time.
+func collectExport(filter AnnotationFilter, interval int64) ([]Species, []exportFrame, error) {
+	verified := Verified
+	filter.Review = &verified
+	annotations, err := GetAnnotations(0, 0, nil, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type frameKey struct {
+		videoStreamID int64
+		time          int64
+	}
+	videoStreams := make(map[int64]*VideoStream)
+	categories := make(map[int64]int)
+	species := make([]Species, 0)
+	frames := make(map[frameKey]*exportFrame)
+
+	for _, a := range annotations {
+		if a.Consensus.SpeciesID == nil {
+			continue
+		}
+
+		vs, ok := videoStreams[a.VideostreamID]
+		if !ok {
+			vs, err = GetVideoStreamByID(a.VideostreamID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not get video stream %d: %w", a.VideostreamID, err)
+			}
+			videoStreams[vs.ID] = vs
+		}
+
+		// Map the species to a category.
+		category, ok := categories[*a.Consensus.SpeciesID]
+		if !ok {
+			s, err := GetSpeciesByID(*a.Consensus.SpeciesID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not get species %d: %w", *a.Consensus.SpeciesID, err)
+			}
+			category = len(species)
+			categories[s.ID] = category
+			species = append(species, *s)
+		}
+
+		keypoints := a.KeyPoints
+		if interval != 0 {
+			keypoints, err = keypoint.SampleEvery(a.KeyPoints, interval)
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not sample annotation %d: %w", a.ID, err)
+			}
+		}
+
+		for _, kp := range keypoints {
+			key := frameKey{vs.ID, kp.Time.Int()}
+			frame, ok := frames[key]
+			if !ok {
+				frame = &exportFrame{VideoStream: vs, Time: kp.Time}
+				frames[key] = frame
+			}
+			frame.Boxes = append(frame.Boxes, exportBox{
+				AnnotationID: a.ID,
+				Category:     category,
+				BoundingBox:  kp.BoundingBox,
+				Polygon:      kp.Polygon,
+			})
+		}
+	}
+
+	// Sort categories by species ID, remapping the category of each box.
+	sorted := slices.Clone(species)
+	slices.SortFunc(sorted, func(a, b Species) int { return cmp.Compare(a.ID, b.ID) })
+	remap := make([]int, len(species))
+	for i, s := range sorted {
+		remap[categories[s.ID]] = i
+	}
+
+	// Sort frames by video stream and time.
+	sortedFrames := make([]exportFrame, 0, len(frames))
+	for _, f := range frames {
+		for i := range f.Boxes {
+			f.Boxes[i].Category = remap[f.Boxes[i].Category]
+		}
+		sortedFrames = append(sortedFrames, *f)
+	}
+	slices.SortFunc(sortedFrames, func(a, b exportFrame) int {
+		return cmp.Or(cmp.Compare(a.VideoStream.ID, b.VideoStream.ID), cmp.Compare(a.Time.Int(), b.Time.Int()))
+	})
+
+	return sorted, sortedFrames, nil
+}
@@ -0,0 +1,90 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+)
+
+// ExtractJobName is the name of the job that extracts media from video streams. It is the name of the
+// docker image when running jobs locally, or the name of the Cloud Run job.
+const ExtractJobName = "openfish-extract"
+
+// ErrNoJobRunner is returned when media is extracted without a job runner.
+var ErrNoJobRunner = errors.New("no job runner")
+
+// StartMediaExtraction creates a task and launches a job that extracts the media from its video stream,
+// returning the task ID. The job is given the task ID and media key as arguments, and completes the task with
+// the URL of the media once it has been stored, see CompleteMediaExtraction. If the media already exists, the
+// task is completed straight away. If the job cannot be launched, the task is failed with the reason, and its
+// ID is still returned so that the failure can be seen by checking the task.
+func StartMediaExtraction(key MediaKey) (int64, error) {
+	if !key.Valid() {
+		return 0, fmt.Errorf("%w: video media types must be provided with an end time and image media types must not", ErrInvalidMedia)
+	}
+
+	runner := globals.GetJobRunner()
+	if runner == nil {
+		return 0, ErrNoJobRunner
+	}
+
+	id, err := CreateTask()
+	if err != nil {
+		return 0, err
+	}
+
+	if MediaExists(key) {
+		return id, CompleteTask(id, key.ToURL())
+	}
+
+	args := append([]string{fmt.Sprintf("--task=%d", id)}, key.ToArgs()...)
+	err = runner.Run(ExtractJobName, args...)
+	if err != nil {
+		return id, FailTask(id, fmt.Errorf("could not run %s job: %w", ExtractJobName, err))
+	}
+
+	return id, nil
+}
+
+// CompleteMediaExtraction stores media extracted by a job and completes its task with the URL of the media.
+func CompleteMediaExtraction(taskID int64, key MediaKey, data []byte) error {
+	_, err := CreateMedia(key, data)
+	if err != nil {
+		return errors.Join(err, FailTask(taskID, err))
+	}
+	return CompleteTask(taskID, key.ToURL())
+}
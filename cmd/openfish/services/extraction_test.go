@@ -0,0 +1,165 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/mediatype"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+	"github.com/ausocean/openfish/jobrunner"
+)
+
+// fakeJobRunner records the jobs it is asked to run, without running them. Jobs fail to launch with err if it is set.
+type fakeJobRunner struct {
+	name string
+	args []string
+	err  error
+}
+
+func (r *fakeJobRunner) Run(name string, args ...string) error {
+	r.name = name
+	r.args = args
+	return r.err
+}
+
+// useFakeJobRunner replaces the job runner for the duration of a test.
+func useFakeJobRunner(t *testing.T) *fakeJobRunner {
+	runner := &fakeJobRunner{}
+	globals.InitJobRunner(runner)
+	t.Cleanup(func() { globals.InitJobRunner(nil) })
+	return runner
+}
+
+func TestStartMediaExtraction(t *testing.T) {
+	setup()
+	runner := useFakeJobRunner(t)
+	vs := createTestVideoStream()
+	key := services.MediaKey{Type: mediatype.JPEG, VideoStreamID: vs.ID, StartTime: videotime.UncheckedParse("00:00:01.000")}
+
+	id, err := services.StartMediaExtraction(key)
+	if err != nil {
+		t.Fatalf("Could not start media extraction %s", err)
+	}
+	expected := []string{fmt.Sprintf("--task=%d", id), fmt.Sprintf("--videostream=%d", vs.ID), "--type=image/jpeg", "--time=00:00:01.000"}
+	if runner.name != services.ExtractJobName || !slices.Equal(runner.args, expected) {
+		t.Errorf("Expected job %s %v, got %s %v", services.ExtractJobName, expected, runner.name, runner.args)
+	}
+	task, _ := services.GetTaskById(id)
+	if task.Status != services.Pending {
+		t.Errorf("Expected task to be pending, got %s", task.Status)
+	}
+
+	// The job stores the media and completes the task.
+	err = services.CompleteMediaExtraction(id, key, []byte("jpeg"))
+	if err != nil {
+		t.Fatalf("Could not complete media extraction %s", err)
+	}
+	task, _ = services.GetTaskById(id)
+	url := fmt.Sprintf("/api/v1/videostreams/%d/media/image/jpeg?time=00%%3A00%%3A01.000", vs.ID)
+	if task.Status != services.Complete || task.Resource == nil || task.Resource.RequestURI() != url {
+		t.Errorf("Expected task to be complete with resource %s, got %+v", url, task)
+	}
+	if !services.MediaExists(key) {
+		t.Errorf("Expected media to exist")
+	}
+
+	// Media that already exists is not extracted again.
+	runner.name = ""
+	id, err = services.StartMediaExtraction(key)
+	if err != nil {
+		t.Fatalf("Could not start media extraction %s", err)
+	}
+	task, _ = services.GetTaskById(id)
+	if runner.name != "" || task.Status != services.Complete {
+		t.Errorf("Expected task to be completed without running a job, got %+v", task)
+	}
+}
+
+func TestStartMediaExtractionJobFails(t *testing.T) {
+	setup()
+	runner := useFakeJobRunner(t)
+	runner.err = errors.New("no capacity")
+	vs := createTestVideoStream()
+	key := services.MediaKey{Type: mediatype.JPEG, VideoStreamID: vs.ID, StartTime: videotime.UncheckedParse("00:00:02.000")}
+
+	id, err := services.StartMediaExtraction(key)
+	if err != nil {
+		t.Fatalf("Could not start media extraction %s", err)
+	}
+	task, _ := services.GetTaskById(id)
+	if task == nil || task.Status != services.Failed {
+		t.Errorf("Expected task to have failed, got %+v", task)
+	}
+}
+
+func TestStartMediaExtractionWithoutDocker(t *testing.T) {
+	setup()
+	globals.InitJobRunner(jobrunner.NewDockerJobRunner(nil))
+	t.Cleanup(func() { globals.InitJobRunner(nil) })
+	t.Setenv("PATH", t.TempDir())
+	vs := createTestVideoStream()
+	key := services.MediaKey{Type: mediatype.JPEG, VideoStreamID: vs.ID, StartTime: videotime.UncheckedParse("00:00:03.000")}
+
+	id, err := services.StartMediaExtraction(key)
+	if err != nil {
+		t.Fatalf("Could not start media extraction %s", err)
+	}
+	task, _ := services.GetTaskById(id)
+	if task == nil || task.Status != services.Failed {
+		t.Errorf("Expected task to have failed, got %+v", task)
+	}
+}
+
+func TestStartMediaExtractionInvalid(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	key := services.MediaKey{Type: mediatype.MP4, VideoStreamID: vs.ID, StartTime: videotime.UncheckedParse("00:00:01.000")}
+
+	_, err := services.StartMediaExtraction(key)
+	if !errors.Is(err, services.ErrInvalidMedia) {
+		t.Errorf("Expected ErrInvalidMedia for video without end time, got %v", err)
+	}
+
+	key.Type = mediatype.JPEG
+	_, err = services.StartMediaExtraction(key)
+	if !errors.Is(err, services.ErrNoJobRunner) {
+		t.Errorf("Expected ErrNoJobRunner, got %v", err)
+	}
+}
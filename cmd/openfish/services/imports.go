@@ -0,0 +1,403 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// ErrInvalidImport is returned when an import file cannot be parsed.
+var ErrInvalidImport = errors.New("invalid import")
+
+// ImportStatus is the outcome of importing a track.
+type ImportStatus string
+
+const (
+	Imported   ImportStatus = "imported"   // An annotation was created.
+	Skipped    ImportStatus = "skipped"    // The track has invalid data.
+	Unresolved ImportStatus = "unresolved" // The label could not be resolved to a species.
+)
+
+// ImportOptions are the options used to import annotations.
+// Labels maps label names to species IDs, for labels that are not the scientific or common name of a species.
+type ImportOptions struct {
+	VideoStreamID int64
+	FPS           float64
+	CreatedByID   int64
+	Labels        map[string]int64
+}
+
+// ImportRow is the outcome of importing a track.
+type ImportRow struct {
+	Row          int          `json:"row" example:"0"`
+	Source       string       `json:"source" example:"track 0"`
+	Label        string       `json:"label" example:"Sepioteuthis australis"`
+	Status       ImportStatus `json:"status" swaggertype:"string" enums:"imported,skipped,unresolved" example:"imported"`
+	AnnotationID *int64       `json:"annotation_id,omitempty" example:"1234567890"`
+	Message      string       `json:"message,omitempty" example:"track has no boxes"`
+}
+
+// ImportReport is a report of what was imported, skipped or unresolved.
+type ImportReport struct {
+	Imported   int         `json:"imported" example:"1"`
+	Skipped    int         `json:"skipped" example:"0"`
+	Unresolved int         `json:"unresolved" example:"0"`
+	Rows       []ImportRow `json:"rows"`
+}
+
+// importTrack is a labelled sequence of keypoints parsed from an import file.
+// Err is the reason the track cannot be imported, if any.
+type importTrack struct {
+	Source    string
+	Label     string
+	KeyPoints []keypoint.KeyPoint
+	Err       error
+}
+
+// frameTime converts a frame number to a video time.
+func frameTime(frame int, fps float64) videotime.VideoTime {
+	return videotime.FromInt(int64(math.Round(float64(frame) * 1000 / fps)))
+}
+
+// toPercentages converts a bounding box with pixel coordinates to percentages of the width and height.
+func toPercentages(box keypoint.BoundingBox, width int, height int) keypoint.BoundingBox {
+	w, h := 100/float32(width), 100/float32(height)
+	return keypoint.BoundingBox{
+		X1: box.X1 * w,
+		X2: box.X2 * w,
+		Y1: box.Y1 * h,
+		Y2: box.Y2 * h,
+	}
+}
+
+// sortKeyPoints sorts keypoints by time, removing keypoints with duplicate times.
+func sortKeyPoints(keypoints []keypoint.KeyPoint) []keypoint.KeyPoint {
+	slices.SortStableFunc(keypoints, func(a, b keypoint.KeyPoint) int { return cmp.Compare(a.Time.Int(), b.Time.Int()) })
+	return slices.CompactFunc(keypoints, func(a, b keypoint.KeyPoint) bool { return a.Time == b.Time })
+}
+
+// validateImportOptions checks that the frame rate is valid and the video stream exists, returning the video stream.
+func validateImportOptions(opts ImportOptions) (*VideoStream, error) {
+	if !(opts.FPS > 0) {
+		return nil, fmt.Errorf("%w: frame rate must be greater than zero", ErrInvalidImport)
+	}
+	vs, err := GetVideoStreamByID(opts.VideoStreamID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get video stream %d: %w", ErrInvalidImport, opts.VideoStreamID, err)
+	}
+	return vs, nil
+}
+
+// importTracks resolves the labels of tracks to species and creates an annotation for each track.
+func importTracks(tracks []importTrack, opts ImportOptions) *ImportReport {
+	report := ImportReport{Rows: make([]ImportRow, 0, len(tracks))}
+	resolved := make(map[string]*int64)
+
+	for i, t := range tracks {
+		row := ImportRow{Row: i, Source: t.Source, Label: t.Label}
+		report.Rows = append(report.Rows, row)
+		r := &report.Rows[i]
+
+		if t.Err != nil {
+			r.Status, r.Message = Skipped, t.Err.Error()
+			report.Skipped++
+			continue
+		}
+
+		// Resolve the label to a species.
+		speciesID, ok := resolved[t.Label]
+		if !ok {
+			if id, ok := opts.Labels[t.Label]; ok && SpeciesExists(id) {
+				speciesID = &id
+			} else if s, err := GetSpeciesByName(t.Label); err == nil && s != nil {
+				speciesID = &s.ID
+			}
+			resolved[t.Label] = speciesID
+		}
+		if speciesID == nil {
+			r.Status, r.Message = Unresolved, fmt.Sprintf("no species found for label %q", t.Label)
+			report.Unresolved++
+			continue
+		}
+
+		created, err := CreateAnnotation(AnnotationContents{
+			KeyPoints:       t.KeyPoints,
+			VideostreamID:   opts.VideoStreamID,
+			Identifications: map[int64][]int64{*speciesID: {opts.CreatedByID}},
+			CreatedByID:     opts.CreatedByID,
+		})
+		if err != nil {
+			r.Status, r.Message = Skipped, err.Error()
+			report.Skipped++
+			continue
+		}
+		r.Status, r.AnnotationID = Imported, &created.ID
+		report.Imported++
+	}
+
+	return &report
+}
+
+// cvatAnnotations is the root of a CVAT for video 1.1 XML file.
+type cvatAnnotations struct {
+	Meta struct {
+		Task struct {
+			OriginalSize cvatSize `xml:"original_size"`
+		} `xml:"task"`
+		Job struct {
+			OriginalSize cvatSize `xml:"original_size"`
+		} `xml:"job"`
+	} `xml:"meta"`
+	Tracks []cvatTrack `xml:"track"`
+}
+
+// cvatSize is the resolution of a CVAT video.
+type cvatSize struct {
+	Width  int `xml:"width"`
+	Height int `xml:"height"`
+}
+
+// cvatTrack is a labelled object tracked across frames.
+type cvatTrack struct {
+	ID    int       `xml:"id,attr"`
+	Label string    `xml:"label,attr"`
+	Boxes []cvatBox `xml:"box"`
+}
+
+// cvatBox is the bounding box of a track in a frame, in pixels. Outside boxes mark where the object leaves the video.
+type cvatBox struct {
+	Frame    int     `xml:"frame,attr"`
+	Outside  int     `xml:"outside,attr"`
+	KeyFrame int     `xml:"keyframe,attr"`
+	XTL      float32 `xml:"xtl,attr"`
+	YTL      float32 `xml:"ytl,attr"`
+	XBR      float32 `xml:"xbr,attr"`
+	YBR      float32 `xml:"ybr,attr"`
+}
+
+// ImportCVAT imports annotations from a CVAT for video 1.1 XML file. Frames are converted to times using the
+// frame rate, and boxes are converted to percentages using the resolution in the file, or of the video stream.
+// A track becomes an annotation for each segment where the object is inside the video, using the keyframes of the
+// segment and its last frame.
+func ImportCVAT(r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	vs, err := validateImportOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc cvatAnnotations
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+
+	size := cmp.Or(doc.Meta.Task.OriginalSize, doc.Meta.Job.OriginalSize, cvatSize{vs.Width, vs.Height})
+	if size.Width <= 0 || size.Height <= 0 {
+		return nil, fmt.Errorf("%w: resolution is not in the file or set on video stream %d", ErrInvalidImport, vs.ID)
+	}
+
+	tracks := make([]importTrack, 0, len(doc.Tracks))
+	for _, t := range doc.Tracks {
+		slices.SortStableFunc(t.Boxes, func(a, b cvatBox) int { return cmp.Compare(a.Frame, b.Frame) })
+
+		// Split the track into segments where the object is inside the video.
+		segments := make([][]keypoint.KeyPoint, 0, 1)
+		var segment []keypoint.KeyPoint
+		for i, b := range t.Boxes {
+			if b.Outside != 0 {
+				if len(segment) > 0 {
+					segments = append(segments, segment)
+				}
+				segment = nil
+				continue
+			}
+			last := i == len(t.Boxes)-1 || t.Boxes[i+1].Outside != 0
+			if b.KeyFrame == 0 && len(segment) > 0 && !last {
+				continue
+			}
+			segment = append(segment, keypoint.KeyPoint{
+				BoundingBox: toPercentages(keypoint.BoundingBox{X1: b.XTL, Y1: b.YTL, X2: b.XBR, Y2: b.YBR}, size.Width, size.Height),
+				Time:        frameTime(b.Frame, opts.FPS),
+			})
+		}
+		if len(segment) > 0 {
+			segments = append(segments, segment)
+		}
+
+		if len(segments) == 0 {
+			tracks = append(tracks, importTrack{
+				Source: fmt.Sprintf("track %d", t.ID),
+				Label:  t.Label,
+				Err:    errors.New("track has no boxes inside the video"),
+			})
+		}
+		for i, s := range segments {
+			source := fmt.Sprintf("track %d", t.ID)
+			if len(segments) > 1 {
+				source = fmt.Sprintf("track %d segment %d", t.ID, i)
+			}
+			tracks = append(tracks, importTrack{Source: source, Label: t.Label, KeyPoints: sortKeyPoints(s)})
+		}
+	}
+
+	return importTracks(tracks, opts), nil
+}
+
+// cocoImport is a COCO JSON file to import. Images must have a frame number (frame_id) or a video time (time),
+// so their time in the video is known. Annotations with the same track ID are imported as one annotation.
+type cocoImport struct {
+	Images []struct {
+		ID      int64   `json:"id"`
+		Width   int     `json:"width"`
+		Height  int     `json:"height"`
+		FrameID *int    `json:"frame_id"`
+		Time    *string `json:"time"`
+	} `json:"images"`
+	Annotations []struct {
+		ID         int64      `json:"id"`
+		ImageID    int64      `json:"image_id"`
+		CategoryID int64      `json:"category_id"`
+		BBox       [4]float32 `json:"bbox"`
+		TrackID    *int64     `json:"track_id"`
+		Attributes struct {
+			TrackID *int64 `json:"track_id"`
+		} `json:"attributes"`
+	} `json:"annotations"`
+	Categories []struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	} `json:"categories"`
+}
+
+// ImportCOCO imports annotations from a COCO JSON file. Frame numbers are converted to times using the frame rate, and
+// boxes are converted to percentages using the resolution of the image, or of the video stream. Annotations are grouped
+// into tracks using their track ID, annotations without a track ID are imported individually.
+func ImportCOCO(r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	vs, err := validateImportOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc cocoImport
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+
+	categories := make(map[int64]string, len(doc.Categories))
+	for _, c := range doc.Categories {
+		categories[c.ID] = c.Name
+	}
+
+	// Index images by ID, finding their time and resolution.
+	type image struct {
+		time          videotime.VideoTime
+		width, height int
+		err           error
+	}
+	images := make(map[int64]image, len(doc.Images))
+	for _, img := range doc.Images {
+		i := image{width: cmp.Or(img.Width, vs.Width), height: cmp.Or(img.Height, vs.Height)}
+		switch {
+		case img.Time != nil:
+			i.time, i.err = videotime.Parse(*img.Time)
+		case img.FrameID != nil:
+			i.time = frameTime(*img.FrameID, opts.FPS)
+		default:
+			i.err = fmt.Errorf("image %d has no frame_id or time", img.ID)
+		}
+		if i.err == nil && (i.width <= 0 || i.height <= 0) {
+			i.err = fmt.Errorf("image %d has no resolution", img.ID)
+		}
+		images[img.ID] = i
+	}
+
+	// Group annotations into tracks.
+	tracks := make([]importTrack, 0)
+	trackIndex := make(map[int64]int)
+	for _, a := range doc.Annotations {
+		trackID := cmp.Or(a.TrackID, a.Attributes.TrackID)
+		i, ok := 0, false
+		if trackID != nil {
+			i, ok = trackIndex[*trackID]
+		}
+		var t *importTrack
+		if ok {
+			t = &tracks[i]
+		} else {
+			source := fmt.Sprintf("annotation %d", a.ID)
+			if trackID != nil {
+				source = fmt.Sprintf("track %d", *trackID)
+				trackIndex[*trackID] = len(tracks)
+			}
+			label, ok := categories[a.CategoryID]
+			tracks = append(tracks, importTrack{Source: source, Label: label})
+			t = &tracks[len(tracks)-1]
+			if !ok {
+				t.Err = fmt.Errorf("category %d does not exist", a.CategoryID)
+			}
+		}
+		if t.Err != nil {
+			continue
+		}
+
+		img, ok := images[a.ImageID]
+		if !ok {
+			t.Err = fmt.Errorf("image %d does not exist", a.ImageID)
+			continue
+		}
+		if img.err != nil {
+			t.Err = img.err
+			continue
+		}
+		x, y, w, h := a.BBox[0], a.BBox[1], a.BBox[2], a.BBox[3]
+		t.KeyPoints = append(t.KeyPoints, keypoint.KeyPoint{
+			BoundingBox: toPercentages(keypoint.BoundingBox{X1: x, Y1: y, X2: x + w, Y2: y + h}, img.width, img.height),
+			Time:        img.time,
+		})
+	}
+	for i := range tracks {
+		tracks[i].KeyPoints = sortKeyPoints(tracks[i].KeyPoints)
+	}
+
+	return importTracks(tracks, opts), nil
+}
@@ -0,0 +1,220 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+)
+
+const testCVAT = `<?xml version="1.0" encoding="utf-8"?>
+<annotations>
+  <version>1.1</version>
+  <meta>
+    <task>
+      <original_size>
+        <width>1000</width>
+        <height>500</height>
+      </original_size>
+    </task>
+  </meta>
+  <track id="0" label="squid" source="manual">
+    <box frame="0" outside="0" occluded="0" keyframe="1" xtl="100" ytl="50" xbr="200" ybr="100" z_order="0"></box>
+    <box frame="10" outside="0" occluded="0" keyframe="0" xtl="150" ytl="50" xbr="250" ybr="100" z_order="0"></box>
+    <box frame="25" outside="0" occluded="0" keyframe="1" xtl="200" ytl="50" xbr="300" ybr="100" z_order="0"></box>
+    <box frame="30" outside="1" occluded="0" keyframe="1" xtl="200" ytl="50" xbr="300" ybr="100" z_order="0"></box>
+    <box frame="50" outside="0" occluded="0" keyframe="1" xtl="100" ytl="50" xbr="200" ybr="100" z_order="0"></box>
+    <box frame="60" outside="0" occluded="0" keyframe="1" xtl="100" ytl="50" xbr="200" ybr="100" z_order="0"></box>
+  </track>
+  <track id="1" label="unknown fish" source="manual">
+    <box frame="0" outside="0" occluded="0" keyframe="1" xtl="100" ytl="50" xbr="200" ybr="100" z_order="0"></box>
+  </track>
+  <track id="2" label="squid" source="manual">
+    <box frame="0" outside="1" occluded="0" keyframe="1" xtl="100" ytl="50" xbr="200" ybr="100" z_order="0"></box>
+  </track>
+</annotations>`
+
+const testCOCO = `{
+  "images": [
+    {"id": 1, "width": 1000, "height": 500, "file_name": "frame_000000.jpg", "frame_id": 0},
+    {"id": 2, "width": 1000, "height": 500, "file_name": "frame_000025.jpg", "frame_id": 25}
+  ],
+  "annotations": [
+    {"id": 1, "image_id": 2, "category_id": 1, "bbox": [200, 50, 100, 50], "attributes": {"track_id": 7}},
+    {"id": 2, "image_id": 1, "category_id": 1, "bbox": [100, 50, 100, 50], "attributes": {"track_id": 7}},
+    {"id": 3, "image_id": 1, "category_id": 2, "bbox": [100, 50, 100, 50]},
+    {"id": 4, "image_id": 9, "category_id": 1, "bbox": [100, 50, 100, 50]}
+  ],
+  "categories": [
+    {"id": 1, "name": "squid"},
+    {"id": 2, "name": "unknown fish"}
+  ]
+}`
+
+// checkImportRow checks a row of an import report has the expected source and status.
+func checkImportRow(t *testing.T, report *services.ImportReport, i int, source string, status services.ImportStatus) {
+	t.Helper()
+	if i >= len(report.Rows) {
+		t.Fatalf("Expected at least %d rows, got %d", i+1, len(report.Rows))
+	}
+	row := report.Rows[i]
+	if row.Source != source || row.Status != status {
+		t.Errorf("Expected row %d to be %s %s, got %s %s (%s)", i, source, status, row.Source, row.Status, row.Message)
+	}
+}
+
+func TestImportCVAT(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	sp := createTestSpecies()
+	uid := createTestUserWithRole("admin", role.Admin)
+
+	report, err := services.ImportCVAT(strings.NewReader(testCVAT), services.ImportOptions{
+		VideoStreamID: vs.ID,
+		FPS:           25,
+		CreatedByID:   uid,
+		Labels:        map[string]int64{"squid": sp.ID},
+	})
+	if err != nil {
+		t.Fatalf("Could not import CVAT %s", err)
+	}
+
+	if report.Imported != 2 || report.Unresolved != 1 || report.Skipped != 1 {
+		t.Errorf("Expected 2 imported, 1 unresolved and 1 skipped, got %d, %d and %d", report.Imported, report.Unresolved, report.Skipped)
+	}
+	checkImportRow(t, report, 0, "track 0 segment 0", services.Imported)
+	checkImportRow(t, report, 1, "track 0 segment 1", services.Imported)
+	checkImportRow(t, report, 2, "track 1", services.Unresolved)
+	checkImportRow(t, report, 3, "track 2", services.Skipped)
+
+	// First segment uses the keyframes at frames 0 and 25.
+	a, err := services.GetAnnotationByID(*report.Rows[0].AnnotationID)
+	if err != nil {
+		t.Fatalf("Could not get imported annotation %s", err)
+	}
+	expected := []keypoint.KeyPoint{
+		{BoundingBox: keypoint.BoundingBox{X1: 10, Y1: 10, X2: 20, Y2: 20}},
+		{BoundingBox: keypoint.BoundingBox{X1: 20, Y1: 10, X2: 30, Y2: 20}},
+	}
+	if len(a.KeyPoints) != len(expected) {
+		t.Fatalf("Expected %d keypoints, got %d", len(expected), len(a.KeyPoints))
+	}
+	for i, kp := range a.KeyPoints {
+		if kp.BoundingBox != expected[i].BoundingBox {
+			t.Errorf("Expected keypoint %d box %v, got %v", i, expected[i].BoundingBox, kp.BoundingBox)
+		}
+	}
+	if a.KeyPoints[1].Time.String() != "00:00:01.000" {
+		t.Errorf("Expected frame 25 at 00:00:01.000, got %s", a.KeyPoints[1].Time.String())
+	}
+	if _, ok := a.Identifications[sp.ID]; !ok || a.CreatedByID != uid {
+		t.Errorf("Imported annotation does not match expected")
+	}
+}
+
+func TestImportCOCO(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	sp := createTestSpecies()
+	uid := createTestUserWithRole("admin", role.Admin)
+
+	report, err := services.ImportCOCO(strings.NewReader(testCOCO), services.ImportOptions{
+		VideoStreamID: vs.ID,
+		FPS:           25,
+		CreatedByID:   uid,
+		Labels:        map[string]int64{"squid": sp.ID},
+	})
+	if err != nil {
+		t.Fatalf("Could not import COCO %s", err)
+	}
+
+	checkImportRow(t, report, 0, "track 7", services.Imported)
+	checkImportRow(t, report, 1, "annotation 3", services.Unresolved)
+	checkImportRow(t, report, 2, "annotation 4", services.Skipped)
+
+	a, err := services.GetAnnotationByID(*report.Rows[0].AnnotationID)
+	if err != nil {
+		t.Fatalf("Could not get imported annotation %s", err)
+	}
+	if len(a.KeyPoints) != 2 || a.KeyPoints[0].Time.String() != "00:00:00.000" || a.KeyPoints[1].Time.String() != "00:00:01.000" {
+		t.Errorf("Imported keypoints do not match expected, got %v", a.KeyPoints)
+	}
+}
+
+func TestImportResolvesSpeciesByName(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	sp := createUniqueTestSpecies()
+	uid := createTestUserWithRole("admin", role.Admin)
+
+	// Labels are resolved by scientific name and by common name when they are not mapped.
+	for _, name := range []string{sp.ScientificName, sp.CommonName} {
+		report, err := services.ImportCVAT(strings.NewReader(strings.ReplaceAll(testCVAT, "squid", name)), services.ImportOptions{
+			VideoStreamID: vs.ID,
+			FPS:           25,
+			CreatedByID:   uid,
+		})
+		if err != nil {
+			t.Fatalf("Could not import CVAT %s", err)
+		}
+		checkImportRow(t, report, 0, "track 0 segment 0", services.Imported)
+		if report.Rows[0].AnnotationID == nil {
+			continue
+		}
+		a, _ := services.GetAnnotationByID(*report.Rows[0].AnnotationID)
+		if _, ok := a.Identifications[sp.ID]; !ok {
+			t.Errorf("Expected label %q to be resolved to species %d, got %v", name, sp.ID, a.Identifications)
+		}
+	}
+}
+
+func TestImportWithInvalidFile(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+
+	_, err := services.ImportCOCO(strings.NewReader("not json"), services.ImportOptions{VideoStreamID: vs.ID, FPS: 25})
+	if !errors.Is(err, services.ErrInvalidImport) {
+		t.Errorf("Expected invalid import error, got %v", err)
+	}
+
+	_, err = services.ImportCVAT(strings.NewReader(testCVAT), services.ImportOptions{VideoStreamID: vs.ID})
+	if !errors.Is(err, services.ErrInvalidImport) {
+		t.Errorf("Expected invalid import error for missing frame rate, got %v", err)
+	}
+}
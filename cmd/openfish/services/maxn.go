@@ -0,0 +1,186 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/ausocean/cloud/datastore"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// MaxN is the maximum number of individuals of a species annotated at the same time, the standard
+// measure of relative abundance for camera surveys. Time and DateTime are when MaxN first occurred.
+type MaxN struct {
+	Species       SpeciesSummary      `json:"species"`
+	MaxN          int                 `json:"maxn" example:"12"`
+	VideoStreamID int64               `json:"videostream_id" example:"1234567890"`
+	Time          videotime.VideoTime `json:"time" swaggertype:"string" example:"00:01:05.500"`
+	DateTime      time.Time           `json:"datetime" example:"2023-05-25T08:01:05.5Z"`
+}
+
+// maxNEvent is an annotated individual of a species appearing (+1) or leaving (-1) at a time in a video stream.
+type maxNEvent struct {
+	species int64
+	time    int64
+	delta   int
+}
+
+// maxNByStream computes the MaxN of each species in a video stream. Each annotation is counted as one individual
+// of its consensus species for the whole interval between its first and last keypoints, since its boxes are
+// interpolated between them. Only the part of each interval that falls within [from, to] is counted, if given.
+func maxNByStream(vs VideoStream, consensus *ConsensusStatus, from *time.Time, to *time.Time) (map[int64]MaxN, error) {
+	annotations, err := GetAnnotations(0, 0, nil, AnnotationFilter{VideoStreamID: &vs.ID, Consensus: consensus})
+	if err != nil {
+		return nil, err
+	}
+
+	// Clip intervals to the date range.
+	lo, hi := int64(0), int64(math.MaxInt64)
+	if from != nil {
+		lo = from.Sub(vs.StartTime).Milliseconds()
+	}
+	if to != nil {
+		hi = to.Sub(vs.StartTime).Milliseconds()
+	}
+
+	var events []maxNEvent
+	for _, a := range annotations {
+		if a.Consensus.SpeciesID == nil || len(a.KeyPoints) == 0 {
+			continue
+		}
+		start := max(a.KeyPoints[0].Time.Int(), lo)
+		end := min(a.KeyPoints[len(a.KeyPoints)-1].Time.Int(), hi)
+		if start > end {
+			continue
+		}
+		events = append(events,
+			maxNEvent{*a.Consensus.SpeciesID, start, 1},
+			maxNEvent{*a.Consensus.SpeciesID, end, -1},
+		)
+	}
+
+	// Sweep through the events in time order. Individuals appearing are counted before individuals leaving
+	// at the same time, so that annotations which touch are counted as overlapping.
+	slices.SortFunc(events, func(a, b maxNEvent) int {
+		return cmp.Or(cmp.Compare(a.time, b.time), cmp.Compare(b.delta, a.delta))
+	})
+	counts := make(map[int64]int)
+	results := make(map[int64]MaxN)
+	for _, e := range events {
+		counts[e.species] += e.delta
+		if counts[e.species] > results[e.species].MaxN {
+			t := videotime.FromInt(e.time)
+			results[e.species] = MaxN{
+				MaxN:          counts[e.species],
+				VideoStreamID: vs.ID,
+				Time:          t,
+				DateTime:      vs.TimeAt(t),
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// joinMaxN joins the species of each MaxN, returning them sorted by species ID.
+// Species that have been deleted are skipped.
+func joinMaxN(results map[int64]MaxN) ([]MaxN, error) {
+	ids := make([]int64, 0, len(results))
+	for id := range results {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	maxN := make([]MaxN, 0, len(ids))
+	for _, id := range ids {
+		species, err := GetSpeciesByID(id)
+		if errors.Is(err, datastore.ErrNoSuchEntity) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		m := results[id]
+		m.Species = species.ToSummary()
+		maxN = append(maxN, m)
+	}
+
+	return maxN, nil
+}
+
+// GetMaxN computes the MaxN of each species annotated in a video stream within a date range, sorted by species ID,
+// optionally only counting annotations with the given consensus status. Nil dates are not filtered on.
+func GetMaxN(videoStreamID int64, from *time.Time, to *time.Time, consensus *ConsensusStatus) ([]MaxN, error) {
+	vs, err := GetVideoStreamByID(videoStreamID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := maxNByStream(*vs, consensus, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return joinMaxN(results)
+}
+
+// GetMaxNByCaptureSource computes the MaxN of each species across the video streams produced by a capture source
+// within a date range, sorted by species ID. MaxN is the largest of the MaxN of each video stream, as individuals
+// may be counted again in different video streams. Nil dates are not filtered on.
+func GetMaxNByCaptureSource(captureSource int64, from *time.Time, to *time.Time, consensus *ConsensusStatus) ([]MaxN, error) {
+	streams, err := findVideoStreams(&captureSource, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[int64]MaxN)
+	for _, vs := range streams {
+		stream, err := maxNByStream(vs, consensus, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for id, m := range stream {
+			prev, ok := results[id]
+			if !ok || m.MaxN > prev.MaxN || (m.MaxN == prev.MaxN && m.DateTime.Before(prev.DateTime)) {
+				results[id] = m
+			}
+		}
+	}
+
+	return joinMaxN(results)
+}
@@ -0,0 +1,149 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+func TestGetMaxN(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	squid := createTestSpecies()
+	user := createTestUserWithRole("coral.fischer@example.com", role.Annotator)
+
+	box := keypoint.BoundingBox{X1: 10, Y1: 10, X2: 20, Y2: 20}
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "00:00:01.000", "00:00:03.000")
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "00:00:02.000", "00:00:04.000")
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "00:00:02.500", "00:00:03.000", "00:00:05.000")
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "00:00:06.000", "00:00:07.000")
+
+	maxN, err := services.GetMaxN(vs.ID, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Could not get MaxN %s", err)
+	}
+	if len(maxN) != 1 {
+		t.Fatalf("Expected MaxN for 1 species, got %d", len(maxN))
+	}
+	if maxN[0].Species.ID != squid.ID || maxN[0].MaxN != 3 {
+		t.Errorf("Expected MaxN of 3, got %d", maxN[0].MaxN)
+	}
+	if maxN[0].Time != videotime.UncheckedParse("00:00:02.500") {
+		t.Errorf("Expected MaxN to occur at 00:00:02.500, got %s", maxN[0].Time)
+	}
+	if !maxN[0].DateTime.Equal(vs.StartTime.Add(2500 * time.Millisecond)) {
+		t.Errorf("Expected MaxN date and time to be relative to the video stream, got %s", maxN[0].DateTime)
+	}
+}
+
+func TestGetMaxNTouchingAnnotations(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	squid := createTestSpecies()
+	user := createTestUserWithRole("coral.fischer@example.com", role.Annotator)
+
+	// Annotations that end when another starts are visible in the same frame.
+	box := keypoint.BoundingBox{X1: 10, Y1: 10, X2: 20, Y2: 20}
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "00:00:01.000", "00:00:02.000")
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "00:00:02.000", "00:00:03.000")
+
+	maxN, _ := services.GetMaxN(vs.ID, nil, nil, nil)
+	if len(maxN) != 1 || maxN[0].MaxN != 2 {
+		t.Errorf("Expected MaxN of 2, got %v", maxN)
+	}
+}
+
+func TestGetMaxNDateRange(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	squid := createTestSpecies()
+	user := createTestUserWithRole("coral.fischer@example.com", role.Annotator)
+
+	box := keypoint.BoundingBox{X1: 10, Y1: 10, X2: 20, Y2: 20}
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "00:00:01.000", "00:00:03.000")
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "00:00:02.000", "00:00:04.000")
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "00:00:06.000", "00:00:07.000")
+
+	// Only the last annotation is within the date range.
+	from := vs.StartTime.Add(5 * time.Second)
+	maxN, err := services.GetMaxN(vs.ID, &from, nil, nil)
+	if err != nil {
+		t.Fatalf("Could not get MaxN %s", err)
+	}
+	if len(maxN) != 1 || maxN[0].MaxN != 1 || maxN[0].Time != videotime.UncheckedParse("00:00:06.000") {
+		t.Errorf("Expected MaxN of 1 at 00:00:06.000, got %v", maxN)
+	}
+}
+
+func TestGetMaxNByCaptureSource(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	other, _ := services.CreateVideoStream(services.VideoStreamContents{
+		StartTime:     _8am.Add(24 * time.Hour),
+		EndTime:       ptr(_4pm.Add(24 * time.Hour)),
+		AnnotatorList: []int64{},
+		BaseVideoStreamFields: services.BaseVideoStreamFields{
+			StreamURL:     "http://youtube.com/watch?v=def456",
+			CaptureSource: vs.CaptureSource,
+		},
+	})
+	squid := createTestSpecies()
+	user := createTestUserWithRole("coral.fischer@example.com", role.Annotator)
+
+	box := keypoint.BoundingBox{X1: 10, Y1: 10, X2: 20, Y2: 20}
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "00:00:01.000", "00:00:03.000")
+	createTestAnnotationWithBoxes(vs.ID, user, squid.ID, box, "00:00:02.000", "00:00:04.000")
+	createTestAnnotationWithBoxes(other.ID, user, squid.ID, box, "00:00:01.000", "00:00:02.000")
+
+	maxN, err := services.GetMaxNByCaptureSource(vs.CaptureSource, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Could not get MaxN %s", err)
+	}
+	if len(maxN) != 1 || maxN[0].MaxN != 2 || maxN[0].VideoStreamID != vs.ID {
+		t.Errorf("Expected MaxN of 2 on the first video stream, got %v", maxN)
+	}
+
+	// Only the part of each annotation within the date range is counted.
+	from := vs.StartTime.Add(3500 * time.Millisecond)
+	maxN, _ = services.GetMaxNByCaptureSource(vs.CaptureSource, &from, nil, nil)
+	if len(maxN) != 1 || maxN[0].MaxN != 1 || maxN[0].Time != videotime.UncheckedParse("00:00:03.500") {
+		t.Errorf("Expected MaxN of 1 at the start of the date range, got %v", maxN)
+	}
+}
@@ -30,14 +30,23 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/url"
 
 	"github.com/ausocean/openfish/cmd/openfish/globals"
 	"github.com/ausocean/openfish/cmd/openfish/types/mediatype"
 	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+	"github.com/ausocean/openfish/storage"
 )
 
+// ErrInvalidMedia is returned when media is requested with a type that does not match its time.
+var ErrInvalidMedia = errors.New("invalid media")
+
+// ErrMediaNotFound is returned when media has not been stored.
+var ErrMediaNotFound = errors.New("media not found")
+
 // MediaKey is a composite key with all the attributes that define media.
 // All media are uniquely identified by the combination of these three parameters.
 type MediaKey struct {
@@ -62,27 +71,59 @@ func (q *MediaKey) ToStorageName() string {
 	}
 }
 
-// GetMedia gets the media with the specified type, source video stream and time.
-func GetMedia(q MediaKey) ([]byte, error) {
+// ToURL returns the URL of the API endpoint that gets the media.
+func (q *MediaKey) ToURL() *url.URL {
+	query := url.Values{"time": {q.StartTime.String()}}
+	if q.EndTime != nil {
+		query = url.Values{"time[start]": {q.StartTime.String()}, "time[end]": {q.EndTime.String()}}
+	}
+	return &url.URL{
+		Path:     fmt.Sprintf("/api/v1/videostreams/%d/media/%s", q.VideoStreamID, q.Type.MimeType()),
+		RawQuery: query.Encode(),
+	}
+}
+
+// timeArg returns the time of an image, or the time span of a video, as a job argument.
+func (q *MediaKey) timeArg() string {
+	if q.EndTime == nil {
+		return q.StartTime.String()
+	}
+	return fmt.Sprintf("%s-%s", q.StartTime.String(), q.EndTime.String())
+}
+
+// ToArgs returns the command-line arguments of the job that extracts the media.
+func (q *MediaKey) ToArgs() []string {
+	return []string{
+		fmt.Sprintf("--videostream=%d", q.VideoStreamID),
+		fmt.Sprintf("--type=%s", q.Type.MimeType()),
+		fmt.Sprintf("--time=%s", q.timeArg()),
+	}
+}
 
+// GetMediaAttrs gets the size, ETag and last modified time of the media, or ErrMediaNotFound if it does not exist.
+func GetMediaAttrs(q MediaKey) (*storage.ObjectAttrs, error) {
 	if !q.Valid() {
-		return nil, fmt.Errorf("video media types must be provided with an end time and image media types must not")
+		return nil, fmt.Errorf("%w: video media types must be provided with an end time and image media types must not", ErrInvalidMedia)
 	}
 
-	// Get file from storage.
-	storage := globals.GetStorage()
-	name := q.ToStorageName()
-	handle := storage.Object(name)
-	r, err := handle.NewReader(context.Background())
-	if err != nil {
-		return nil, err
+	handle := globals.GetStorage().Object(q.ToStorageName())
+	attrs, err := handle.Attrs(context.Background())
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrMediaNotFound, q.ToStorageName())
 	}
+	return attrs, err
+}
 
-	bytes, err := io.ReadAll(r)
-	if err != nil {
-		return nil, err
+// NewMediaReader returns a reader of length bytes of the media, starting at offset, so that media can be streamed
+// without reading it all into memory. If length is negative, it reads to the end of the media.
+// The caller is responsible for closing the reader.
+func NewMediaReader(q MediaKey, offset int64, length int64) (io.ReadCloser, error) {
+	if !q.Valid() {
+		return nil, fmt.Errorf("%w: video media types must be provided with an end time and image media types must not", ErrInvalidMedia)
 	}
-	return bytes, nil
+
+	handle := globals.GetStorage().Object(q.ToStorageName())
+	return handle.NewRangeReader(context.Background(), offset, length)
 }
 
 // MediaExists checks if the media exists in the datastore.
@@ -34,6 +34,8 @@ LICENSE
 package services_test
 
 import (
+	"errors"
+	"io"
 	"reflect"
 	"testing"
 
@@ -111,7 +113,7 @@ func TestMediaExistsForNonexistentObject(t *testing.T) {
 	}
 }
 
-func TestGetMedia(t *testing.T) {
+func TestNewMediaReaderWholeMedia(t *testing.T) {
 	setup()
 
 	// Create a new media entity.
@@ -125,16 +127,18 @@ func TestGetMedia(t *testing.T) {
 	expected := []byte{1, 2, 3, 4, 5}
 	services.CreateMedia(mq, expected)
 
-	bytes, err := services.GetMedia(mq)
+	r, err := services.NewMediaReader(mq, 0, -1)
 	if err != nil {
-		t.Errorf("Could not get media object: %s", err)
+		t.Fatalf("Could not read media object: %s", err)
 	}
+	defer r.Close()
+	bytes, _ := io.ReadAll(r)
 	if !reflect.DeepEqual(expected, bytes) {
 		t.Errorf("Media object does not match expected object, %+v, %+v", bytes, expected)
 	}
 }
 
-func TestGetMediaForNonexistentObject(t *testing.T) {
+func TestNewMediaReaderForNonexistentObject(t *testing.T) {
 	setup()
 
 	mq := services.MediaKey{
@@ -143,9 +147,9 @@ func TestGetMediaForNonexistentObject(t *testing.T) {
 		StartTime:     videotime.UncheckedParse("00:00:01.000"),
 		EndTime:       nil,
 	}
-	_, err := services.GetMedia(mq)
+	_, err := services.NewMediaReader(mq, 0, -1)
 	if err == nil {
-		t.Errorf("GetMediaByID returned non-existing object %s", err)
+		t.Errorf("NewMediaReader returned a reader for a non-existing object")
 	}
 }
 
@@ -188,3 +192,53 @@ func TestDeleteMediaForNonexistentObject(t *testing.T) {
 		t.Errorf("Did not receive expected error when deleting non-existent media")
 	}
 }
+
+func TestNewMediaReader(t *testing.T) {
+	setup()
+
+	// Create a new media entity.
+	vs := createTestVideoStream()
+	start := videotime.UncheckedParse("00:00:01.000")
+	end := videotime.UncheckedParse("00:00:01.500")
+	mq := services.MediaKey{
+		Type:          mediatype.MP4,
+		VideoStreamID: vs.ID,
+		StartTime:     start,
+		EndTime:       &end,
+	}
+	services.CreateMedia(mq, []byte{1, 2, 3, 4, 5})
+
+	attrs, err := services.GetMediaAttrs(mq)
+	if err != nil {
+		t.Fatalf("Could not get media attributes: %s", err)
+	}
+	if attrs.Size != 5 || attrs.ETag == "" {
+		t.Errorf("Unexpected media attributes %+v", attrs)
+	}
+
+	// Read part of the media.
+	r, err := services.NewMediaReader(mq, 1, 3)
+	if err != nil {
+		t.Fatalf("Could not read media: %s", err)
+	}
+	defer r.Close()
+	bytes, _ := io.ReadAll(r)
+	if !reflect.DeepEqual(bytes, []byte{2, 3, 4}) {
+		t.Errorf("Expected %v, got %v", []byte{2, 3, 4}, bytes)
+	}
+}
+
+func TestGetMediaAttrsForNonexistentObject(t *testing.T) {
+	setup()
+
+	vs := createTestVideoStream()
+	mq := services.MediaKey{
+		Type:          mediatype.JPEG,
+		VideoStreamID: vs.ID,
+		StartTime:     videotime.UncheckedParse("00:00:01.000"),
+	}
+	_, err := services.GetMediaAttrs(mq)
+	if !errors.Is(err, services.ErrMediaNotFound) {
+		t.Errorf("Expected ErrMediaNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"sync"
+
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/mediatype"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// ErrFrameNotFound is returned when a frame has not been extracted to media storage.
+var ErrFrameNotFound = errors.New("frame not found")
+
+// overlayQuality is the JPEG quality of rendered overlays.
+const overlayQuality = 90
+
+// overlayPalette is the colours of boxes and labels, chosen by the label so each species has a consistent colour.
+var overlayPalette = []color.RGBA{
+	{230, 25, 75, 255},
+	{60, 180, 75, 255},
+	{255, 225, 25, 255},
+	{0, 130, 200, 255},
+	{245, 130, 48, 255},
+	{145, 30, 180, 255},
+	{70, 240, 240, 255},
+	{240, 50, 230, 255},
+}
+
+// overlayFont is the font used for labels, parsed once when first needed.
+var overlayFont = sync.OnceValues(func() (*opentype.Font, error) {
+	return opentype.Parse(goregular.TTF)
+})
+
+// overlayBox is a bounding box drawn on a frame, with percentage coordinates, and its label.
+type overlayBox struct {
+	box   keypoint.BoundingBox
+	label string
+}
+
+// color returns the colour of the box, chosen by its label.
+func (b overlayBox) color() color.RGBA {
+	h := fnv.New32a()
+	io.WriteString(h, b.label)
+	return overlayPalette[h.Sum32()%uint32(len(overlayPalette))]
+}
+
+// getOverlayBoxes gets the bounding boxes of the annotations of a video stream at time t, linearly interpolated
+// between their keypoints, and labelled with their species.
+func getOverlayBoxes(videoStreamID int64, t videotime.VideoTime) ([]overlayBox, error) {
+	annotations, err := GetAnnotations(0, 0, nil, AnnotationFilter{VideoStreamID: &videoStreamID})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]string)
+	var boxes []overlayBox
+	for _, a := range annotations {
+		if len(a.KeyPoints) == 0 || t.Int() < a.KeyPoints[0].Time.Int() || t.Int() > a.KeyPoints[len(a.KeyPoints)-1].Time.Int() {
+			continue
+		}
+		kp, err := keypoint.Interpolate(a.KeyPoints, t)
+		if err != nil {
+			return nil, fmt.Errorf("could not interpolate annotation %d: %w", a.ID, err)
+		}
+		label, err := annotationLabel(a, names)
+		if err != nil {
+			return nil, err
+		}
+		boxes = append(boxes, overlayBox{box: kp.BoundingBox, label: label})
+	}
+	return boxes, nil
+}
+
+// overlayStorageName returns the name used to cache the overlay of a video stream at time t in storage. Each frame
+// has one cached overlay, which is overwritten when the annotations change, so that old overlays do not build up.
+func overlayStorageName(videoStreamID int64, t videotime.VideoTime) string {
+	return fmt.Sprintf("overlays/%d[%s].overlay", videoStreamID, t.String())
+}
+
+// overlayHash returns a hash of the boxes and labels of an overlay. The hash is stored before the cached overlay,
+// so that the overlay is rendered again when the annotations change.
+func overlayHash(boxes []overlayBox) []byte {
+	h := fnv.New64a()
+	for _, b := range boxes {
+		fmt.Fprintf(h, "%v|%s\n", b.box, b.label)
+	}
+	return h.Sum(nil)
+}
+
+// RenderOverlay renders the bounding boxes and species of every annotation of a video stream at time t onto
+// the frame at that time, returning a new JPEG image. The frame must have been extracted to media storage as
+// a JPEG. If cache is true, the overlay is read from and written to storage.
+func RenderOverlay(videoStreamID int64, t videotime.VideoTime, cache bool) ([]byte, error) {
+	boxes, err := getOverlayBoxes(videoStreamID, t)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the cached overlay if the annotations are unchanged.
+	storage := globals.GetStorage()
+	handle := storage.Object(overlayStorageName(videoStreamID, t))
+	hash := overlayHash(boxes)
+	if cache {
+		if exists, _ := handle.Exists(context.Background()); exists {
+			r, err := handle.NewReader(context.Background())
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			cached, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			if bytes.HasPrefix(cached, hash) {
+				return cached[len(hash):], nil
+			}
+		}
+	}
+
+	frame, err := getFrame(MediaKey{Type: mediatype.JPEG, VideoStreamID: videoStreamID, StartTime: t})
+	if err != nil {
+		return nil, err
+	}
+	data, err := drawOverlay(frame, boxes)
+	if err != nil {
+		return nil, err
+	}
+
+	if cache {
+		w, err := handle.NewWriter(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		_, err = w.Write(append(hash, data...))
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// drawOverlay draws boxes onto a frame, returning a JPEG image, each with its label above it, or inside it if there is no room above.
+// Line widths and the size of the labels are scaled to the frame.
+func drawOverlay(frame image.Image, boxes []overlayBox) ([]byte, error) {
+	bounds := frame.Bounds()
+	img := image.NewRGBA(bounds)
+	draw.Draw(img, bounds, frame, bounds.Min, draw.Src)
+
+	f, err := overlayFont()
+	if err != nil {
+		return nil, err
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    max(12, float64(bounds.Dy())/36),
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	thickness := max(2, bounds.Dx()/400)
+	padding := thickness * 2
+	metrics := face.Metrics()
+	labelHeight := (metrics.Ascent + metrics.Descent).Ceil() + padding
+
+	for _, b := range boxes {
+		c := b.color()
+		fill := image.NewUniform(c)
+
+		// Convert percentages to pixels.
+		r := image.Rect(
+			bounds.Min.X+int(float32(bounds.Dx())*b.box.X1/100),
+			bounds.Min.Y+int(float32(bounds.Dy())*b.box.Y1/100),
+			bounds.Min.X+int(float32(bounds.Dx())*b.box.X2/100),
+			bounds.Min.Y+int(float32(bounds.Dy())*b.box.Y2/100),
+		).Intersect(bounds)
+		if r.Empty() {
+			continue
+		}
+
+		// Outline the box.
+		draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+thickness).Intersect(r), fill, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(r.Min.X, r.Max.Y-thickness, r.Max.X, r.Max.Y).Intersect(r), fill, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+thickness, r.Max.Y).Intersect(r), fill, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(r.Max.X-thickness, r.Min.Y, r.Max.X, r.Max.Y).Intersect(r), fill, image.Point{}, draw.Src)
+
+		// Draw the label on a background of the box's colour.
+		width := font.MeasureString(face, b.label).Ceil() + padding
+		top := r.Min.Y - labelHeight
+		if top < bounds.Min.Y {
+			top = r.Min.Y
+		}
+		label := image.Rect(r.Min.X, top, r.Min.X+width, top+labelHeight).Intersect(bounds)
+		draw.Draw(img, label, fill, image.Point{}, draw.Src)
+		d := font.Drawer{
+			Dst:  img,
+			Src:  image.NewUniform(textColor(c)),
+			Face: face,
+			Dot:  fixed.P(label.Min.X+padding/2, top+padding/2+metrics.Ascent.Ceil()),
+		}
+		d.DrawString(b.label)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: overlayQuality}); err != nil {
+		return nil, fmt.Errorf("could not encode overlay: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// textColor returns black or white, whichever is more legible on the background colour c.
+func textColor(c color.RGBA) color.Color {
+	if 299*int(c.R)+587*int(c.G)+114*int(c.B) > 128000 {
+		return color.Black
+	}
+	return color.White
+}
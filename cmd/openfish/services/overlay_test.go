@@ -0,0 +1,126 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"path/filepath"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/mediatype"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// isBlue reports whether a pixel is close to the blue of the test frame, allowing for JPEG compression.
+func isBlue(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r>>8 < 40 && g>>8 < 40 && b>>8 > 215
+}
+
+func TestRenderOverlay(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	at := videotime.UncheckedParse("00:00:01.500")
+
+	// Extract a plain blue frame.
+	frame := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	draw.Draw(frame, frame.Bounds(), image.NewUniform(color.RGBA{0, 0, 255, 255}), image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	jpeg.Encode(&buf, frame, nil)
+	services.CreateMedia(services.MediaKey{Type: mediatype.JPEG, VideoStreamID: a.VideostreamID, StartTime: at}, buf.Bytes())
+
+	data, err := services.RenderOverlay(a.VideostreamID, at, true)
+	if err != nil {
+		t.Fatalf("Could not render overlay %s", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Could not decode overlay %s", err)
+	}
+	if img.Bounds() != frame.Bounds() {
+		t.Errorf("Expected overlay to be %v, got %v", frame.Bounds(), img.Bounds())
+	}
+
+	// The box is interpolated to 15%-25% by 65%-75%, which is (60, 130) to (100, 150) in pixels.
+	if isBlue(img.At(80, 131)) || isBlue(img.At(61, 140)) {
+		t.Errorf("Expected box to be drawn")
+	}
+	if !isBlue(img.At(80, 140)) || !isBlue(img.At(300, 50)) {
+		t.Errorf("Expected the rest of the frame to be unchanged")
+	}
+
+	// The cached overlay is returned while the annotations are unchanged.
+	cached, err := services.RenderOverlay(a.VideostreamID, at, true)
+	if err != nil {
+		t.Fatalf("Could not render cached overlay %s", err)
+	}
+	if !bytes.Equal(cached, data) {
+		t.Errorf("Expected cached overlay to be the same")
+	}
+
+	// The cached overlay is replaced when the annotations change.
+	services.DeleteAnnotation(a.ID, a.CreatedByID)
+	data, err = services.RenderOverlay(a.VideostreamID, at, true)
+	if err != nil {
+		t.Fatalf("Could not render overlay %s", err)
+	}
+	img, err = jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Could not decode overlay %s", err)
+	}
+	if !isBlue(img.At(80, 131)) {
+		t.Errorf("Expected box of deleted annotation to not be drawn")
+	}
+	overlays, _ := filepath.Glob(fmt.Sprintf("openfish-media/overlays/%d[[]*", a.VideostreamID))
+	if len(overlays) != 1 {
+		t.Errorf("Expected 1 cached overlay, got %d", len(overlays))
+	}
+}
+
+func TestRenderOverlayWithoutFrame(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+
+	_, err := services.RenderOverlay(a.VideostreamID, videotime.UncheckedParse("00:00:01.500"), false)
+	if !errors.Is(err, services.ErrFrameNotFound) {
+		t.Errorf("Expected ErrFrameNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,307 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/ausocean/openfish/cmd/openfish/entities"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/timespan"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// AnnotationType distinguishes the kinds of annotations served by the annotations API.
+type AnnotationType string
+
+const (
+	BoxAnnotationType   AnnotationType = "box"   // Bounding boxes or polygons tracking an individual over time.
+	PointAnnotationType AnnotationType = "point" // Points counting individuals of a species at a single time.
+)
+
+// UnmarshalText validates that the annotation type is one of the valid values.
+func (t *AnnotationType) UnmarshalText(text []byte) error {
+	switch AnnotationType(text) {
+	case BoxAnnotationType, PointAnnotationType:
+		*t = AnnotationType(text)
+		return nil
+	default:
+		return fmt.Errorf("invalid annotation type %q, must be box or point", text)
+	}
+}
+
+// ErrInvalidPoints is returned when a point annotation has no points, or points outside of the frame.
+var ErrInvalidPoints = errors.New("invalid points")
+
+// PointAnnotationContents is the contents of a point annotation. Each point marks an individual of the species,
+// as percentages of the width and height of the video.
+type PointAnnotationContents struct {
+	VideostreamID int64
+	Time          videotime.VideoTime
+	Points        []keypoint.Point
+	SpeciesID     int64
+	CreatedByID   int64
+}
+
+// PointAnnotation is a count of individuals of a species at a single time within a video stream, with a point marking each individual.
+type PointAnnotation struct {
+	ID int64
+	PointAnnotationContents
+}
+
+// PointAnnotationFilter describes the optional filters that can be applied when getting point annotations.
+// Nil fields are not filtered on.
+type PointAnnotationFilter struct {
+	VideoStreamID *int64             // Point annotations on this video stream.
+	SpeciesID     *int64             // Point annotations of this species.
+	CreatedByID   *int64             // Point annotations created by this user.
+	TimeSpan      *timespan.TimeSpan // Point annotations within this time span of the video.
+}
+
+// PointAnnotationWithJoins is a point annotation with its foreign key fields joined with
+// their respective entities.
+type PointAnnotationWithJoins struct {
+	ID          int64               `json:"id" example:"1234567890"`
+	Type        AnnotationType      `json:"type" example:"point"`
+	Time        videotime.VideoTime `json:"time" swaggertype:"string" example:"01:56:05.500"`
+	Points      []keypoint.Point    `json:"points"`
+	Count       int                 `json:"count" example:"300"`
+	Species     SpeciesSummary      `json:"species"`
+	Videostream VideoStreamSummary  `json:"videostream"`
+	CreatedBy   PublicUser          `json:"created_by"`
+}
+
+// SpeciesAbundance is the number of individuals of a species counted in a video stream. Total is the sum of
+// every point annotation, and Max is the largest number counted at a single time, which occurred at MaxTime.
+type SpeciesAbundance struct {
+	Species SpeciesSummary      `json:"species"`
+	Total   int64               `json:"total" example:"1200"`
+	Max     int64               `json:"max" example:"300"`
+	MaxTime videotime.VideoTime `json:"max_time" swaggertype:"string" example:"00:01:05.500"`
+}
+
+// JoinFields joins the foreign key fields of a point annotation with their respective entities.
+func (p *PointAnnotation) JoinFields() (*PointAnnotationWithJoins, error) {
+	videostream, err := GetVideoStreamByID(p.VideostreamID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := GetUserByID(p.CreatedByID)
+	if err != nil {
+		return nil, err
+	}
+	species, err := GetSpeciesByID(p.SpeciesID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PointAnnotationWithJoins{
+		ID:          p.ID,
+		Type:        PointAnnotationType,
+		Time:        p.Time,
+		Points:      p.Points,
+		Count:       len(p.Points),
+		Species:     species.ToSummary(),
+		Videostream: videostream.ToSummary(),
+		CreatedBy:   user.ToPublicUser(),
+	}, nil
+}
+
+// ToEntity converts a PointAnnotationContents struct to an entities.PointAnnotation struct.
+func (p *PointAnnotationContents) ToEntity() entities.PointAnnotation {
+	return entities.PointAnnotation{
+		VideoStreamID: p.VideostreamID,
+		Time:          p.Time.Int(),
+		Points:        pointsToEntity(p.Points),
+		Count:         int64(len(p.Points)),
+		SpeciesID:     p.SpeciesID,
+		CreatedBy:     p.CreatedByID,
+	}
+}
+
+// PointAnnotationContentsFromEntity converts an entities.PointAnnotation to a PointAnnotationContents struct.
+func PointAnnotationContentsFromEntity(e entities.PointAnnotation) PointAnnotationContents {
+	return PointAnnotationContents{
+		VideostreamID: e.VideoStreamID,
+		Time:          videotime.FromInt(e.Time),
+		Points:        pointsFromEntity(e.Points),
+		SpeciesID:     e.SpeciesID,
+		CreatedByID:   e.CreatedBy,
+	}
+}
+
+// GetPointAnnotationByID gets a point annotation when provided with an ID.
+func GetPointAnnotationByID(id int64) (*PointAnnotation, error) {
+	store := globals.GetStore()
+	key := store.IDKey(entities.POINT_ANNOTATION_KIND, id)
+	var e entities.PointAnnotation
+	err := store.Get(context.Background(), key, &e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PointAnnotation{
+		ID:                      id,
+		PointAnnotationContents: PointAnnotationContentsFromEntity(e),
+	}, nil
+}
+
+// GetPointAnnotations gets a list of point annotations, applying the given filters. A limit of zero returns all point annotations.
+func GetPointAnnotations(limit int, offset int, filter PointAnnotationFilter) ([]PointAnnotation, error) {
+	store := globals.GetStore()
+	query := store.NewQuery(entities.POINT_ANNOTATION_KIND, false)
+
+	// Apply filters.
+	if filter.VideoStreamID != nil {
+		query.FilterField("VideoStreamID", "=", *filter.VideoStreamID)
+	}
+	if filter.SpeciesID != nil {
+		query.FilterField("SpeciesID", "=", *filter.SpeciesID)
+	}
+	if filter.CreatedByID != nil {
+		query.FilterField("CreatedBy", "=", *filter.CreatedByID)
+	}
+	if filter.TimeSpan != nil {
+		query.FilterField("Time", ">=", filter.TimeSpan.Start.Int())
+		query.FilterField("Time", "<=", filter.TimeSpan.End.Int())
+	}
+
+	// Apply pagination.
+	if limit > 0 {
+		query.Limit(limit)
+	}
+	query.Offset(offset)
+
+	// Fetch entities from the datastore.
+	var ents []entities.PointAnnotation
+	_, err := store.GetAll(context.Background(), query, &ents)
+	if err != nil {
+		return []PointAnnotation{}, err
+	}
+
+	// Convert entities.
+	annotations := make([]PointAnnotation, len(ents))
+	for i := range ents {
+		annotations[i] = PointAnnotation{
+			ID:                      ents[i].Key.ID,
+			PointAnnotationContents: PointAnnotationContentsFromEntity(ents[i]),
+		}
+	}
+
+	return annotations, nil
+}
+
+// CreatePointAnnotation creates a new point annotation.
+func CreatePointAnnotation(contents PointAnnotationContents) (*PointAnnotation, error) {
+	// Verify points are within the frame.
+	if len(contents.Points) == 0 {
+		return nil, fmt.Errorf("%w: at least one point is required", ErrInvalidPoints)
+	}
+	for _, p := range contents.Points {
+		if p.X < 0 || p.X > 100 || p.Y < 0 || p.Y > 100 {
+			return nil, fmt.Errorf("%w: point (%g, %g) is outside of the frame", ErrInvalidPoints, p.X, p.Y)
+		}
+	}
+
+	// Verify VideoStream and Species exist.
+	if !VideoStreamExists(contents.VideostreamID) {
+		return nil, errors.New("VideoStream does not exist")
+	}
+	if !SpeciesExists(contents.SpeciesID) {
+		return nil, errors.New("Species does not exist")
+	}
+
+	// Create PointAnnotation entity and add to the datastore.
+	store := globals.GetStore()
+	key := store.IncompleteKey(entities.POINT_ANNOTATION_KIND)
+	ent := contents.ToEntity()
+	key, err := store.Put(context.Background(), key, &ent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PointAnnotation{
+		ID:                      key.ID,
+		PointAnnotationContents: contents,
+	}, nil
+}
+
+// DeletePointAnnotation deletes a point annotation, moving it to the trash.
+func DeletePointAnnotation(id int64, userID int64) error {
+	return moveToTrash(TrashPointAnnotation, id, userID)
+}
+
+// GetAbundance counts the individuals of each species marked by point annotations on a video stream, sorted by species ID.
+// Point annotations of the same species at the same time are added together, as they may mark different parts of a group.
+func GetAbundance(videoStreamID int64) ([]SpeciesAbundance, error) {
+	annotations, err := GetPointAnnotations(0, 0, PointAnnotationFilter{VideoStreamID: &videoStreamID})
+	if err != nil {
+		return nil, err
+	}
+
+	// Count individuals of each species at each time.
+	counts := make(map[int64]map[int64]int64)
+	for _, a := range annotations {
+		if counts[a.SpeciesID] == nil {
+			counts[a.SpeciesID] = make(map[int64]int64)
+		}
+		counts[a.SpeciesID][a.Time.Int()] += int64(len(a.Points))
+	}
+
+	abundance := make([]SpeciesAbundance, 0, len(counts))
+	for speciesID, byTime := range counts {
+		species, err := GetSpeciesByID(speciesID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get species %d: %w", speciesID, err)
+		}
+		sa := SpeciesAbundance{Species: species.ToSummary()}
+		for _, t := range slices.Sorted(maps.Keys(byTime)) {
+			sa.Total += byTime[t]
+			if byTime[t] > sa.Max {
+				sa.Max = byTime[t]
+				sa.MaxTime = videotime.FromInt(t)
+			}
+		}
+		abundance = append(abundance, sa)
+	}
+	slices.SortFunc(abundance, func(a, b SpeciesAbundance) int { return cmp.Compare(a.Species.ID, b.Species.ID) })
+
+	return abundance, nil
+}
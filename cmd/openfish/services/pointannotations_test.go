@@ -0,0 +1,186 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// createTestPointAnnotation creates a point annotation counting n individuals at the given time.
+func createTestPointAnnotation(vs services.VideoStream, sp services.Species, user int64, time string, n int) services.PointAnnotation {
+	points := make([]keypoint.Point, n)
+	for i := range points {
+		points[i] = keypoint.Point{X: float32(i), Y: 50}
+	}
+	created, _ := services.CreatePointAnnotation(services.PointAnnotationContents{
+		VideostreamID: vs.ID,
+		Time:          videotime.UncheckedParse(time),
+		Points:        points,
+		SpeciesID:     sp.ID,
+		CreatedByID:   user,
+	})
+	return *created
+}
+
+func TestCreatePointAnnotation(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	sp := createTestSpecies()
+	user := createTestUserWithRole("annotator", role.Annotator)
+
+	created, err := services.CreatePointAnnotation(services.PointAnnotationContents{
+		VideostreamID: vs.ID,
+		Time:          videotime.UncheckedParse("00:01:05.500"),
+		Points:        []keypoint.Point{{X: 10.5, Y: 20}, {X: 30, Y: 40.25}},
+		SpeciesID:     sp.ID,
+		CreatedByID:   user,
+	})
+	if err != nil {
+		t.Fatalf("Could not create point annotation %s", err)
+	}
+
+	annotation, err := services.GetPointAnnotationByID(created.ID)
+	if err != nil {
+		t.Fatalf("Could not get point annotation %s", err)
+	}
+	if annotation.Time.String() != "00:01:05.500" || len(annotation.Points) != 2 || annotation.Points[1] != (keypoint.Point{X: 30, Y: 40.25}) {
+		t.Errorf("Point annotation does not match expected, got %+v", annotation)
+	}
+
+	joined, err := annotation.JoinFields()
+	if err != nil {
+		t.Fatalf("Could not join fields %s", err)
+	}
+	if joined.Type != services.PointAnnotationType || joined.Count != 2 || joined.Species.ID != sp.ID {
+		t.Errorf("Joined point annotation does not match expected, got %+v", joined)
+	}
+}
+
+func TestCreatePointAnnotationInvalid(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	sp := createTestSpecies()
+	user := createTestUserWithRole("annotator", role.Annotator)
+
+	invalid := [][]keypoint.Point{
+		{},
+		{{X: 10, Y: 101}},
+	}
+	for _, points := range invalid {
+		_, err := services.CreatePointAnnotation(services.PointAnnotationContents{
+			VideostreamID: vs.ID,
+			Points:        points,
+			SpeciesID:     sp.ID,
+			CreatedByID:   user,
+		})
+		if !errors.Is(err, services.ErrInvalidPoints) {
+			t.Errorf("Expected ErrInvalidPoints for %v, got %v", points, err)
+		}
+	}
+
+	_, err := services.CreatePointAnnotation(services.PointAnnotationContents{
+		VideostreamID: vs.ID,
+		Points:        []keypoint.Point{{X: 10, Y: 10}},
+		SpeciesID:     int64(123456789),
+		CreatedByID:   user,
+	})
+	if err == nil {
+		t.Errorf("Did not receive expected error when creating point annotation with non-existent species")
+	}
+}
+
+func TestGetPointAnnotationsByVideoStream(t *testing.T) {
+	setup()
+	vs1 := createTestVideoStream()
+	vs2 := createTestVideoStream()
+	sp := createTestSpecies()
+	user := createTestUserWithRole("annotator", role.Annotator)
+	createTestPointAnnotation(vs1, sp, user, "00:00:01.000", 3)
+	createTestPointAnnotation(vs1, sp, user, "00:00:02.000", 5)
+	createTestPointAnnotation(vs2, sp, user, "00:00:01.000", 7)
+
+	annotations, err := services.GetPointAnnotations(0, 0, services.PointAnnotationFilter{VideoStreamID: &vs1.ID})
+	if err != nil {
+		t.Fatalf("Could not get point annotations %s", err)
+	}
+	if len(annotations) != 2 {
+		t.Errorf("Expected 2 point annotations, got %d", len(annotations))
+	}
+}
+
+func TestGetAbundance(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	sp := createTestSpecies()
+	user := createTestUserWithRole("annotator", role.Annotator)
+
+	// Two annotations at 2s mark different parts of the same school.
+	createTestPointAnnotation(vs, sp, user, "00:00:01.000", 30)
+	createTestPointAnnotation(vs, sp, user, "00:00:02.000", 20)
+	createTestPointAnnotation(vs, sp, user, "00:00:02.000", 25)
+
+	abundance, err := services.GetAbundance(vs.ID)
+	if err != nil {
+		t.Fatalf("Could not get abundance %s", err)
+	}
+	if len(abundance) != 1 {
+		t.Fatalf("Expected abundance of 1 species, got %d", len(abundance))
+	}
+	a := abundance[0]
+	if a.Species.ID != sp.ID || a.Total != 75 || a.Max != 45 || a.MaxTime.String() != "00:00:02.000" {
+		t.Errorf("Abundance does not match expected, got %+v", a)
+	}
+}
+
+func TestDeletePointAnnotation(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	sp := createTestSpecies()
+	user := createTestUserWithRole("annotator", role.Annotator)
+	created := createTestPointAnnotation(vs, sp, user, "00:00:01.000", 3)
+
+	err := services.DeletePointAnnotation(created.ID, user)
+	if err != nil {
+		t.Fatalf("Could not delete point annotation %s", err)
+	}
+	if _, err := services.GetPointAnnotationByID(created.ID); err == nil {
+		t.Errorf("Point annotation exists after delete")
+	}
+}
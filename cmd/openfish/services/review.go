@@ -0,0 +1,184 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ausocean/cloud/datastore"
+)
+
+// ErrInvalidTransition is returned when an annotation cannot move to a review status from its current status.
+var ErrInvalidTransition = errors.New("invalid review status transition")
+
+// ReviewStatus is the stage an annotation is at in the review workflow.
+//
+// State machine:
+//
+//	+-----------+      +-----------+      +-----------+
+//	|   Draft   | ---> | Submitted | ---> | Verified  |
+//	+-----------+      +-----------+      +-----------+
+//	                     |       ^
+//	                     v       |
+//	                   +-----------+
+//	                   | Rejected  |
+//	                   +-----------+
+//
+// A verified annotation goes back to submitted if its keypoints or consensus species change.
+type ReviewStatus string
+
+const (
+	Draft     ReviewStatus = "draft"     // The annotation is being worked on by its annotator.
+	Submitted ReviewStatus = "submitted" // The annotation is waiting for a curator to review it.
+	Verified  ReviewStatus = "verified"  // A curator has checked the annotation, it can be used in datasets.
+	Rejected  ReviewStatus = "rejected"  // A curator has rejected the annotation, it can be fixed and resubmitted.
+)
+
+// UnmarshalText is used for decoding query params or JSON into a ReviewStatus.
+func (s *ReviewStatus) UnmarshalText(text []byte) error {
+	switch status := ReviewStatus(text); status {
+	case Draft, Submitted, Verified, Rejected:
+		*s = status
+		return nil
+	}
+	return fmt.Errorf("invalid review status provided: %s", text)
+}
+
+// Review is the review state of an annotation. Reason is the reason a curator gave for rejecting it.
+type Review struct {
+	Status       ReviewStatus
+	SubmittedAt  *time.Time
+	ReviewedByID *int64
+	Reason       string
+}
+
+// ReviewWithJoins is a review with the reviewer joined.
+type ReviewWithJoins struct {
+	Status      ReviewStatus `json:"status" swaggertype:"string" enums:"draft,submitted,verified,rejected" example:"verified"`
+	SubmittedAt *time.Time   `json:"submitted_at,omitempty" example:"2023-05-25T08:00:00Z"`
+	ReviewedBy  *PublicUser  `json:"reviewed_by,omitempty"`
+	Reason      string       `json:"reason,omitempty" example:"bounding box does not cover the fish"`
+}
+
+// JoinFields joins the reviewer of the review. A reviewer who has since been deleted is left out.
+func (r *Review) JoinFields() (*ReviewWithJoins, error) {
+	var reviewer *PublicUser
+	if r.ReviewedByID != nil {
+		user, err := GetUserByID(*r.ReviewedByID)
+		if err == nil {
+			u := user.ToPublicUser()
+			reviewer = &u
+		} else if !errors.Is(err, datastore.ErrNoSuchEntity) {
+			return nil, err
+		}
+	}
+
+	return &ReviewWithJoins{
+		Status:      r.Status,
+		SubmittedAt: r.SubmittedAt,
+		ReviewedBy:  reviewer,
+		Reason:      r.Reason,
+	}, nil
+}
+
+// unverify sends a verified annotation back for review if its keypoints or consensus species differ from before,
+// as the curator's review no longer applies to it.
+func unverify(before, after *AnnotationContents) {
+	if after.Review.Status != Verified {
+		return
+	}
+	sameSpecies := (before.Consensus.SpeciesID == nil) == (after.Consensus.SpeciesID == nil) &&
+		(before.Consensus.SpeciesID == nil || *before.Consensus.SpeciesID == *after.Consensus.SpeciesID)
+	if sameSpecies && reflect.DeepEqual(before.KeyPoints, after.KeyPoints) {
+		return
+	}
+	now := time.Now()
+	after.Review = Review{Status: Submitted, SubmittedAt: &now}
+}
+
+// SubmitAnnotation submits a draft or rejected annotation for review.
+func SubmitAnnotation(id int64, userID int64) error {
+	rev := revisionInfo{change: ChangeSubmitted, userID: userID}
+	return modifyAnnotation(id, nil, rev, func(a *AnnotationContents) error {
+		if a.Review.Status != Draft && a.Review.Status != Rejected {
+			return fmt.Errorf("%w: cannot submit %s annotation", ErrInvalidTransition, a.Review.Status)
+		}
+		now := time.Now()
+		a.Review = Review{Status: Submitted, SubmittedAt: &now}
+		return nil
+	})
+}
+
+// VerifyAnnotation marks a submitted annotation as verified by the reviewer.
+func VerifyAnnotation(id int64, reviewerID int64) error {
+	rev := revisionInfo{change: ChangeVerified, userID: reviewerID}
+	return modifyAnnotation(id, nil, rev, func(a *AnnotationContents) error {
+		if a.Review.Status != Submitted {
+			return fmt.Errorf("%w: cannot verify %s annotation", ErrInvalidTransition, a.Review.Status)
+		}
+		a.Review.Status = Verified
+		a.Review.ReviewedByID = &reviewerID
+		return nil
+	})
+}
+
+// RejectAnnotation marks a submitted annotation as rejected by the reviewer, for the given reason.
+func RejectAnnotation(id int64, reviewerID int64, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("a reason is required to reject an annotation")
+	}
+
+	rev := revisionInfo{change: ChangeRejected, userID: reviewerID}
+	return modifyAnnotation(id, nil, rev, func(a *AnnotationContents) error {
+		if a.Review.Status != Submitted {
+			return fmt.Errorf("%w: cannot reject %s annotation", ErrInvalidTransition, a.Review.Status)
+		}
+		a.Review.Status = Rejected
+		a.Review.ReviewedByID = &reviewerID
+		a.Review.Reason = reason
+		return nil
+	})
+}
+
+// GetReviewQueue gets the annotations waiting for review, oldest submission first.
+func GetReviewQueue(limit int, offset int) ([]Annotation, error) {
+	status := Submitted
+	order := "SubmittedAt"
+	return GetAnnotations(limit, offset, &order, AnnotationFilter{Review: &status})
+}
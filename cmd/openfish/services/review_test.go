@@ -0,0 +1,252 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/entities"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// createTestVerifiedAnnotation creates an annotation that has been submitted and verified by a curator.
+func createTestVerifiedAnnotation() services.Annotation {
+	a := createTestAnnotation()
+	curator := createTestUserWithRole("curator", role.Curator)
+	services.SubmitAnnotation(a.ID, a.CreatedByID)
+	services.VerifyAnnotation(a.ID, curator)
+	return a
+}
+
+func TestNewAnnotationIsDraft(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+
+	annotation, _ := services.GetAnnotationByID(a.ID)
+	if annotation.Review.Status != services.Draft {
+		t.Errorf("Expected new annotation to be %s, got %s", services.Draft, annotation.Review.Status)
+	}
+}
+
+func TestSubmitAndVerifyAnnotation(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	curator := createTestUserWithRole("curator", role.Curator)
+
+	err := services.SubmitAnnotation(a.ID, a.CreatedByID)
+	if err != nil {
+		t.Fatalf("Could not submit annotation %s", err)
+	}
+	annotation, _ := services.GetAnnotationByID(a.ID)
+	if annotation.Review.Status != services.Submitted || annotation.Review.SubmittedAt == nil {
+		t.Errorf("Expected annotation to be submitted, got %+v", annotation.Review)
+	}
+
+	err = services.VerifyAnnotation(a.ID, curator)
+	if err != nil {
+		t.Fatalf("Could not verify annotation %s", err)
+	}
+	annotation, _ = services.GetAnnotationByID(a.ID)
+	if annotation.Review.Status != services.Verified || annotation.Review.ReviewedByID == nil || *annotation.Review.ReviewedByID != curator {
+		t.Errorf("Expected annotation to be verified by curator, got %+v", annotation.Review)
+	}
+}
+
+func TestJoinReviewWithDeletedReviewer(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	curator := createTestUserWithRole("deleted.curator", role.Curator)
+	services.SubmitAnnotation(a.ID, a.CreatedByID)
+	services.VerifyAnnotation(a.ID, curator)
+	services.DeleteUser(curator)
+
+	annotation, _ := services.GetAnnotationByID(a.ID)
+	review, err := annotation.Review.JoinFields()
+	if err != nil {
+		t.Fatalf("Could not join review by deleted reviewer %s", err)
+	}
+	if review.Status != services.Verified || review.ReviewedBy != nil {
+		t.Errorf("Expected verified review without a reviewer, got %+v", review)
+	}
+}
+
+func TestRejectAndResubmitAnnotation(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	curator := createTestUserWithRole("curator", role.Curator)
+	services.SubmitAnnotation(a.ID, a.CreatedByID)
+
+	err := services.RejectAnnotation(a.ID, curator, "")
+	if err == nil {
+		t.Errorf("Did not receive expected error when rejecting without a reason")
+	}
+
+	err = services.RejectAnnotation(a.ID, curator, "box is too large")
+	if err != nil {
+		t.Fatalf("Could not reject annotation %s", err)
+	}
+	annotation, _ := services.GetAnnotationByID(a.ID)
+	if annotation.Review.Status != services.Rejected || annotation.Review.Reason != "box is too large" {
+		t.Errorf("Expected annotation to be rejected with reason, got %+v", annotation.Review)
+	}
+
+	err = services.SubmitAnnotation(a.ID, a.CreatedByID)
+	if err != nil {
+		t.Fatalf("Could not resubmit annotation %s", err)
+	}
+	annotation, _ = services.GetAnnotationByID(a.ID)
+	if annotation.Review.Status != services.Submitted || annotation.Review.Reason != "" {
+		t.Errorf("Expected resubmitted annotation to clear rejection, got %+v", annotation.Review)
+	}
+}
+
+func TestInvalidReviewTransitions(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	curator := createTestUserWithRole("curator", role.Curator)
+
+	err := services.VerifyAnnotation(a.ID, curator)
+	if !errors.Is(err, services.ErrInvalidTransition) {
+		t.Errorf("Expected invalid transition when verifying draft annotation, got %v", err)
+	}
+
+	services.SubmitAnnotation(a.ID, a.CreatedByID)
+	err = services.SubmitAnnotation(a.ID, a.CreatedByID)
+	if !errors.Is(err, services.ErrInvalidTransition) {
+		t.Errorf("Expected invalid transition when submitting submitted annotation, got %v", err)
+	}
+
+	services.VerifyAnnotation(a.ID, curator)
+	err = services.RejectAnnotation(a.ID, curator, "changed my mind")
+	if !errors.Is(err, services.ErrInvalidTransition) {
+		t.Errorf("Expected invalid transition when rejecting verified annotation, got %v", err)
+	}
+}
+
+func TestModifyVerifiedAnnotation(t *testing.T) {
+	setup()
+	a := createTestVerifiedAnnotation()
+
+	// Changing attributes keeps the annotation verified.
+	current, _ := services.GetAnnotationByID(a.ID)
+	err := services.UpdateAnnotation(a.ID, current.Version, a.CreatedByID, services.PartialAnnotationContents{})
+	if err != nil {
+		t.Fatalf("Could not update annotation %s", err)
+	}
+	annotation, _ := services.GetAnnotationByID(a.ID)
+	if annotation.Review.Status != services.Verified {
+		t.Errorf("Expected annotation to stay %s, got %s", services.Verified, annotation.Review.Status)
+	}
+
+	// Changing the keypoints sends it back for review.
+	keypoints := []keypoint.KeyPoint{
+		{
+			BoundingBox: keypoint.BoundingBox{X1: 30, X2: 40, Y1: 30, Y2: 40},
+			Time:        videotime.UncheckedParse("00:00:01.000"),
+		},
+	}
+	err = services.UpdateAnnotation(a.ID, annotation.Version, a.CreatedByID, services.PartialAnnotationContents{KeyPoints: &keypoints})
+	if err != nil {
+		t.Fatalf("Could not update annotation %s", err)
+	}
+	annotation, _ = services.GetAnnotationByID(a.ID)
+	if annotation.Review.Status != services.Submitted || annotation.Review.ReviewedByID != nil {
+		t.Errorf("Expected annotation to be submitted for review, got %+v", annotation.Review)
+	}
+
+	// Changing the consensus species sends it back for review.
+	services.VerifyAnnotation(a.ID, createTestUserWithRole("curator", role.Curator))
+	sp := createTestSpecies()
+	services.AddIdentification(a.ID, createTestUserWithRole("expert", role.Curator), sp.ID)
+	services.AddIdentification(a.ID, createTestUserWithRole("expert2", role.Curator), sp.ID)
+	annotation, _ = services.GetAnnotationByID(a.ID)
+	if annotation.Consensus.SpeciesID == nil || *annotation.Consensus.SpeciesID != sp.ID {
+		t.Fatalf("Expected consensus species to change")
+	}
+	if annotation.Review.Status != services.Submitted {
+		t.Errorf("Expected annotation to be %s, got %s", services.Submitted, annotation.Review.Status)
+	}
+}
+
+func TestGetReviewQueue(t *testing.T) {
+	setup()
+	submitted := createTestAnnotation()
+	services.SubmitAnnotation(submitted.ID, submitted.CreatedByID)
+	draft := createTestAnnotation()
+
+	queue, err := services.GetReviewQueue(0, 0)
+	if err != nil {
+		t.Fatalf("Could not get review queue %s", err)
+	}
+	ids := make([]int64, len(queue))
+	for i, a := range queue {
+		if a.Review.Status != services.Submitted {
+			t.Errorf("Expected only submitted annotations in the review queue, got %s", a.Review.Status)
+		}
+		ids[i] = a.ID
+	}
+	if !slices.Contains(ids, submitted.ID) || slices.Contains(ids, draft.ID) {
+		t.Errorf("Expected only the submitted annotation in the review queue")
+	}
+}
+
+func TestFilterAnnotationsWithoutReview(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+
+	// Annotations created before the review workflow have no stored status.
+	store := globals.GetStore()
+	key := store.IDKey(entities.ANNOTATION_KIND, a.ID)
+	var e entities.Annotation
+	store.Get(context.Background(), key, &e)
+	e.ReviewStatus = ""
+	store.Put(context.Background(), key, &e)
+
+	draft := services.Draft
+	filter := services.AnnotationFilter{VideoStreamID: &a.VideostreamID, Review: &draft}
+	annotations, err := services.GetAnnotations(0, 0, nil, filter)
+	if err != nil {
+		t.Errorf("Could not get annotations %s", err)
+	}
+	if len(annotations) != 1 || annotations[0].ID != a.ID {
+		t.Errorf("Expected annotation without a review status to be a draft, got %d annotations", len(annotations))
+	}
+}
@@ -36,6 +36,8 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/ausocean/cloud/datastore"
@@ -167,23 +169,59 @@ func GetSpeciesByINaturalistID(id int) (*Species, error) {
 	query.FilterField("INaturalistTaxonID", "=", id)
 	query.Limit(1)
 
+	// The ID is taken from the entity's key, as the returned keys do not
+	// line up with the entities when filtering a file store.
 	var ents []entities.Species
-	keys, err := store.GetAll(context.Background(), query, &ents)
+	_, err := store.GetAll(context.Background(), query, &ents)
 	if err != nil {
 		return nil, err
 	}
-	if len(keys) == 0 {
+	if len(ents) == 0 {
 		return nil, nil
 	}
 
 	species := Species{
-		ID:              keys[0].ID,
+		ID:              ents[0].Key.ID,
 		SpeciesContents: SpeciesContentsFromEntity(ents[0]),
 	}
 
 	return &species, nil
 }
 
+// GetSpeciesByName gets a species by its scientific or common name, ignoring case. Candidates are found using the search index.
+// Returns nil if no species, or more than one species, has the name.
+func GetSpeciesByName(name string) (*Species, error) {
+	store := globals.GetStore()
+	query := store.NewQuery(entities.SPECIES_KIND, false)
+
+	name = strings.TrimSpace(name)
+	query.FilterField("SearchIndex", "=", strings.ToLower(name))
+
+	// The IDs are taken from the entities' keys, as the returned keys do not
+	// line up with the entities when filtering a file store.
+	var ents []entities.Species
+	_, err := store.GetAll(context.Background(), query, &ents)
+	if err != nil {
+		return nil, err
+	}
+
+	var match *Species
+	for i := range ents {
+		if !strings.EqualFold(ents[i].ScientificName, name) && !strings.EqualFold(ents[i].CommonName, name) {
+			continue
+		}
+		if match != nil {
+			return nil, nil
+		}
+		match = &Species{
+			ID:              ents[i].Key.ID,
+			SpeciesContents: SpeciesContentsFromEntity(ents[i]),
+		}
+	}
+
+	return match, nil
+}
+
 // SpeciesExists checks if a species exists with the given ID.
 func SpeciesExists(id int64) bool {
 	store := globals.GetStore()
@@ -274,10 +312,95 @@ func UpdateSpecies(id int64, updates PartialSpeciesContents) error {
 
 }
 
-// DeleteSpecies deletes a species.
-func DeleteSpecies(id int64) error {
-	// Delete entity.
-	store := globals.GetStore()
-	key := store.IDKey(entities.SPECIES_KIND, id)
-	return store.Delete(context.Background(), key)
+// DeleteSpecies deletes a species, moving it to the trash. If the species has been identified in annotations
+// or counted in point annotations, ErrHasDependants is returned unless cascade is true, in which case the
+// identifications are removed from the annotations and the point annotations are moved to the trash.
+// The cascade is all or nothing: if it fails part way, the identifications and point annotations are put back.
+// Restoring the species from the trash later does not put the identifications back, they can be restored
+// from the history of each annotation once the species has been restored.
+func DeleteSpecies(id int64, userID int64, cascade bool) error {
+	if !cascade {
+		annotations, err := countDependants(entities.ANNOTATION_KIND, "IdentificationSpeciesID", id)
+		if err != nil {
+			return err
+		}
+		points, err := countDependants(entities.POINT_ANNOTATION_KIND, "SpeciesID", id)
+		if err != nil {
+			return err
+		}
+		err = checkDependants(fmt.Sprintf("species %d", id),
+			dependants{"annotations", annotations},
+			dependants{"point annotations", points},
+		)
+		if err != nil {
+			return err
+		}
+		return moveToTrash(TrashSpecies, id, userID)
+	}
+
+	c := &cascadeDelete{userID: userID}
+	err := c.deleteSpecies(id)
+	if err != nil {
+		return c.rollback(err)
+	}
+	return nil
+}
+
+// deleteSpecies deletes a species and removes its dependants as part of a cascade. Dependants created
+// while the cascade runs are removed after the species is deleted.
+func (c *cascadeDelete) deleteSpecies(id int64) error {
+	err := c.removeSpeciesDependants(id)
+	if err != nil {
+		return err
+	}
+	err = c.trash(depthParent, TrashSpecies, id)
+	if err != nil {
+		return err
+	}
+	return c.removeSpeciesDependants(id)
+}
+
+// removeSpeciesDependants removes the identifications of a species from annotations and deletes the point
+// annotations that count it.
+func (c *cascadeDelete) removeSpeciesDependants(id int64) error {
+	annotations, err := GetAnnotations(0, 0, nil, AnnotationFilter{SpeciesID: &id})
+	if err != nil {
+		return err
+	}
+	points, err := GetPointAnnotations(0, 0, PointAnnotationFilter{SpeciesID: &id})
+	if err != nil {
+		return err
+	}
+
+	removedRev := revisionInfo{change: ChangeIdentificationRemoved, userID: c.userID, speciesID: &id}
+	addedRev := revisionInfo{change: ChangeIdentificationAdded, userID: c.userID, speciesID: &id}
+	for _, a := range annotations {
+		var removed []int64
+		err := c.do(depthAnnotation, func() error {
+			return modifyAnnotation(a.ID, nil, removedRev, func(a *AnnotationContents) error {
+				removed = a.Identifications[id]
+				delete(a.Identifications, id)
+				return nil
+			})
+		}, func() error {
+			return modifyAnnotation(a.ID, nil, addedRev, func(a *AnnotationContents) error {
+				for _, userID := range removed {
+					if !slices.Contains(a.Identifications[id], userID) {
+						a.Identifications[id] = append(a.Identifications[id], userID)
+					}
+				}
+				return nil
+			})
+		})
+		if ignoreDeleted(err) != nil {
+			return err
+		}
+	}
+	for _, p := range points {
+		err := ignoreDeleted(c.trash(depthAnnotation, TrashPointAnnotation, p.ID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
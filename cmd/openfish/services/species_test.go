@@ -34,10 +34,16 @@ LICENSE
 package services_test
 
 import (
+	"errors"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ausocean/openfish/cmd/openfish/entities"
 	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
 )
 
 func createTestSpecies() services.Species {
@@ -139,6 +145,40 @@ func TestGetSpeciesByINaturalistTaxonID(t *testing.T) {
 	}
 }
 
+// createUniqueTestSpecies creates a species with names that no other species has, so that it can be found by name.
+func createUniqueTestSpecies() services.Species {
+	n := strconv.FormatInt(time.Now().UnixNano(), 10)
+	species, _ := services.CreateSpecies(services.SpeciesContents{
+		ScientificName: "Sepia apama " + n,
+		CommonName:     "Giant Cuttlefish " + n,
+		Images:         []services.SpeciesImage{},
+	})
+
+	return *species
+}
+
+func TestGetSpeciesByName(t *testing.T) {
+	setup()
+	createTestSpecies()
+	expected := createUniqueTestSpecies()
+
+	for _, name := range []string{expected.ScientificName, expected.CommonName, strings.ToUpper(expected.CommonName)} {
+		found, err := services.GetSpeciesByName(name)
+		if err != nil {
+			t.Errorf("Could not get species %q %s", name, err)
+		}
+		if found == nil || !reflect.DeepEqual(expected, *found) {
+			t.Errorf("Found species does not match expected for %q, expected: %+v, found: %+v", name, expected, found)
+		}
+	}
+
+	// Only whole names match.
+	found, err := services.GetSpeciesByName("Giant Cuttlefish")
+	if found != nil || err != nil {
+		t.Errorf("Expected no species for part of a name, found: %+v, %v", found, err)
+	}
+}
+
 // TODO: Write tests for GetSpecies. Test limit, offset, and sorting.
 
 func TestDeleteSpecies(t *testing.T) {
@@ -148,7 +188,7 @@ func TestDeleteSpecies(t *testing.T) {
 	species := createTestSpecies()
 
 	// Delete the species entity.
-	err := services.DeleteSpecies(species.ID)
+	err := services.DeleteSpecies(species.ID, int64(123456789), false)
 	if err != nil {
 		t.Errorf("Could not delete species entity %d: %s", species.ID, err)
 	}
@@ -159,10 +199,53 @@ func TestDeleteSpecies(t *testing.T) {
 	}
 }
 
+func TestDeleteSpeciesWithPointAnnotations(t *testing.T) {
+	setup()
+	uid := createTestUserWithRole("coral.fischer@example.com", role.Annotator)
+	vs := createTestVideoStream()
+	species := createTestSpecies()
+	createTestPointAnnotation(vs, species, uid, "00:00:01.000", 3)
+
+	err := services.DeleteSpecies(species.ID, uid, false)
+	if !errors.Is(err, services.ErrHasDependants) {
+		t.Errorf("Expected ErrHasDependants, got %v", err)
+	}
+
+	err = services.DeleteSpecies(species.ID, uid, true)
+	if err != nil {
+		t.Fatalf("Could not delete species with cascade %s", err)
+	}
+	points, _ := services.GetPointAnnotations(0, 0, services.PointAnnotationFilter{VideoStreamID: &vs.ID})
+	if services.SpeciesExists(species.ID) || len(points) != 0 {
+		t.Errorf("Expected species and point annotations to be deleted")
+	}
+}
+
+func TestDeleteSpeciesCascadeRollsBack(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	var speciesID int64
+	for id := range a.Identifications {
+		speciesID = id
+	}
+
+	restore := failDelete(entities.SPECIES_KIND, speciesID)
+	err := services.DeleteSpecies(speciesID, a.CreatedByID, true)
+	restore()
+	if err == nil {
+		t.Fatalf("Expected cascade to fail")
+	}
+
+	annotation, _ := services.GetAnnotationByID(a.ID)
+	if !services.SpeciesExists(speciesID) || !reflect.DeepEqual(annotation.Identifications, a.Identifications) {
+		t.Errorf("Expected species and identifications to be kept, got %v", annotation.Identifications)
+	}
+}
+
 func TestDeleteSpeciesForNonexistentEntity(t *testing.T) {
 	setup()
 
-	err := services.DeleteSpecies(int64(123456789))
+	err := services.DeleteSpecies(int64(123456789), int64(123456789), false)
 	if err == nil {
 		t.Errorf("Did not receive expected error when deleting non-existent species")
 	}
@@ -0,0 +1,177 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/ausocean/openfish/cmd/openfish/entities"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+)
+
+// StatisticsFilter selects the video streams that statistics are computed for, produced by a capture source
+// or by any capture source at a site, within a date range. Nil fields are not filtered on.
+type StatisticsFilter struct {
+	CaptureSourceID *int64           // Video streams produced by this capture source.
+	SiteID          *int64           // Video streams produced by capture sources at this site.
+	From            *time.Time       // Only count annotations at or after this date and time.
+	To              *time.Time       // Only count annotations at or before this date and time.
+	Consensus       *ConsensusStatus // Only count annotations with this consensus status.
+}
+
+// SpeciesCount is the number of individuals of a species observed.
+type SpeciesCount struct {
+	Species SpeciesSummary `json:"species"`
+	Count   int            `json:"count" example:"12"`
+}
+
+// AccumulationPoint is the number of species observed after a number of video streams have been sampled.
+type AccumulationPoint struct {
+	Samples       int       `json:"samples" example:"3"`
+	VideoStreamID int64     `json:"videostream_id" example:"1234567890"`
+	StartTime     time.Time `json:"start_time" example:"2023-05-25T08:00:00Z"`
+	Richness      int       `json:"richness" example:"7"`
+}
+
+// Biodiversity describes the diversity of species observed. The number of individuals of each species is the sum
+// of its MaxN in each video stream. Shannon is the Shannon index -Σ p ln p, and Simpson is the Gini-Simpson
+// index 1 - Σ p², where p is the proportion of individuals of each species. Accumulation is the species
+// accumulation curve, with video streams sampled in the order they started.
+type Biodiversity struct {
+	Samples      int                 `json:"samples" example:"4"`
+	Individuals  int                 `json:"individuals" example:"40"`
+	Richness     int                 `json:"richness" example:"7"`
+	Shannon      float64             `json:"shannon" example:"1.52"`
+	Simpson      float64             `json:"simpson" example:"0.74"`
+	Species      []SpeciesCount      `json:"species"`
+	Accumulation []AccumulationPoint `json:"accumulation"`
+}
+
+// findStatisticsVideoStreams gets the video streams selected by the filter, in the order they started.
+func findStatisticsVideoStreams(filter StatisticsFilter) ([]VideoStream, error) {
+	var sources []int64
+	if filter.CaptureSourceID != nil {
+		sources = append(sources, *filter.CaptureSourceID)
+	}
+	if filter.SiteID != nil {
+		// Capture sources are few, and the site is optional, so they are filtered in memory.
+		store := globals.GetStore()
+		query := store.NewQuery(entities.CAPTURESOURCE_KIND, false)
+		var ents []entities.CaptureSource
+		keys, err := store.GetAll(context.Background(), query, &ents)
+		if err != nil {
+			return nil, err
+		}
+		for i, e := range ents {
+			if e.SiteID != nil && *e.SiteID == *filter.SiteID && !slices.Contains(sources, keys[i].ID) {
+				sources = append(sources, keys[i].ID)
+			}
+		}
+	}
+
+	var streams []VideoStream
+	for _, cs := range sources {
+		found, err := findVideoStreams(&cs, filter.From, filter.To)
+		if err != nil {
+			return nil, err
+		}
+		streams = append(streams, found...)
+	}
+
+	slices.SortStableFunc(streams, func(a, b VideoStream) int { return a.StartTime.Compare(b.StartTime) })
+	return streams, nil
+}
+
+// GetBiodiversity computes the species richness, diversity indices and species accumulation curve of
+// the annotations in the video streams selected by the filter, using the consensus species of each annotation.
+func GetBiodiversity(filter StatisticsFilter) (*Biodiversity, error) {
+	streams, err := findStatisticsVideoStreams(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	bio := Biodiversity{
+		Samples:      len(streams),
+		Species:      []SpeciesCount{},
+		Accumulation: make([]AccumulationPoint, 0, len(streams)),
+	}
+	counts := make(map[int64]*SpeciesCount)
+	for i, vs := range streams {
+		results, err := maxNByStream(vs, filter.Consensus, filter.From, filter.To)
+		if err != nil {
+			return nil, err
+		}
+		maxN, err := joinMaxN(results)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range maxN {
+			c, ok := counts[m.Species.ID]
+			if !ok {
+				c = &SpeciesCount{Species: m.Species}
+				counts[m.Species.ID] = c
+			}
+			c.Count += m.MaxN
+		}
+		bio.Accumulation = append(bio.Accumulation, AccumulationPoint{
+			Samples:       i + 1,
+			VideoStreamID: vs.ID,
+			StartTime:     vs.StartTime,
+			Richness:      len(counts),
+		})
+	}
+
+	// Compute diversity indices.
+	for _, c := range counts {
+		bio.Individuals += c.Count
+		bio.Species = append(bio.Species, *c)
+	}
+	for _, c := range counts {
+		p := float64(c.Count) / float64(bio.Individuals)
+		bio.Shannon -= p * math.Log(p)
+		bio.Simpson += p * p
+	}
+	if bio.Individuals > 0 {
+		bio.Simpson = 1 - bio.Simpson
+	}
+	bio.Richness = len(counts)
+
+	slices.SortFunc(bio.Species, func(a, b SpeciesCount) int { return cmp.Compare(a.Species.ID, b.Species.ID) })
+
+	return &bio, nil
+}
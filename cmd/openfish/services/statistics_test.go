@@ -0,0 +1,146 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/latlong"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+)
+
+// createTestSurvey creates two video streams a day apart on a capture source at a site. The first has two
+// squid at the same time, and the second has one squid and one leatherjacket.
+func createTestSurvey(site int64) (services.CaptureSource, services.Species, services.Species) {
+	cs, _ := services.CreateCaptureSource(services.CaptureSourceContents{
+		Name:           "Stony Point camera 1",
+		Location:       latlong.UncheckedParse("-37.000,145.000"),
+		CameraHardware: "RPI camera",
+		SiteID:         &site,
+	})
+	squid := createTestSpecies()
+	leatherjacket, _ := services.CreateSpecies(services.SpeciesContents{
+		ScientificName: "Meuschenia freycineti",
+		CommonName:     "Six-spine Leatherjacket",
+		Images:         []services.SpeciesImage{},
+	})
+	user := createTestUserWithRole("coral.fischer@example.com", role.Annotator)
+
+	var streams []int64
+	for i := range 2 {
+		vs, _ := services.CreateVideoStream(services.VideoStreamContents{
+			StartTime:     _8am.Add(time.Duration(i) * 24 * time.Hour),
+			EndTime:       ptr(_4pm.Add(time.Duration(i) * 24 * time.Hour)),
+			AnnotatorList: []int64{},
+			BaseVideoStreamFields: services.BaseVideoStreamFields{
+				StreamURL:     "http://youtube.com/watch?v=abc123",
+				CaptureSource: cs.ID,
+			},
+		})
+		streams = append(streams, vs.ID)
+	}
+
+	box := keypoint.BoundingBox{X1: 10, Y1: 10, X2: 20, Y2: 20}
+	createTestAnnotationWithBoxes(streams[0], user, squid.ID, box, "00:00:01.000", "00:00:03.000")
+	createTestAnnotationWithBoxes(streams[0], user, squid.ID, box, "00:00:02.000", "00:00:04.000")
+	createTestAnnotationWithBoxes(streams[1], user, squid.ID, box, "00:00:01.000", "00:00:02.000")
+	createTestAnnotationWithBoxes(streams[1], user, leatherjacket.ID, box, "00:00:01.000", "00:00:02.000")
+
+	return *cs, squid, *leatherjacket
+}
+
+func TestGetBiodiversity(t *testing.T) {
+	setup()
+	cs, squid, _ := createTestSurvey(7)
+
+	bio, err := services.GetBiodiversity(services.StatisticsFilter{CaptureSourceID: &cs.ID})
+	if err != nil {
+		t.Fatalf("Could not get biodiversity %s", err)
+	}
+
+	if bio.Samples != 2 || bio.Individuals != 4 || bio.Richness != 2 {
+		t.Errorf("Expected 2 samples, 4 individuals and 2 species, got %d, %d and %d", bio.Samples, bio.Individuals, bio.Richness)
+	}
+	if len(bio.Species) != 2 || bio.Species[0].Species.ID > bio.Species[1].Species.ID {
+		t.Fatalf("Expected species to be sorted by ID, got %v", bio.Species)
+	}
+	for _, c := range bio.Species {
+		if c.Species.ID == squid.ID && c.Count != 3 {
+			t.Errorf("Expected 3 squid, got %d", c.Count)
+		}
+	}
+
+	// Proportions are 0.75 and 0.25.
+	shannon := -(0.75*math.Log(0.75) + 0.25*math.Log(0.25))
+	if math.Abs(bio.Shannon-shannon) > 1e-9 {
+		t.Errorf("Expected Shannon index %f, got %f", shannon, bio.Shannon)
+	}
+	if math.Abs(bio.Simpson-0.375) > 1e-9 {
+		t.Errorf("Expected Simpson index 0.375, got %f", bio.Simpson)
+	}
+
+	if len(bio.Accumulation) != 2 || bio.Accumulation[0].Richness != 1 || bio.Accumulation[1].Richness != 2 {
+		t.Errorf("Expected species accumulation of 1 then 2, got %v", bio.Accumulation)
+	}
+}
+
+func TestGetBiodiversityBySiteAndDate(t *testing.T) {
+	setup()
+	// Sites are not created, so a random site is used to avoid matching other tests' capture sources.
+	site := rand.Int63()
+	createTestSurvey(site)
+
+	from := _8am.Add(24 * time.Hour)
+	bio, err := services.GetBiodiversity(services.StatisticsFilter{SiteID: &site, From: &from})
+	if err != nil {
+		t.Fatalf("Could not get biodiversity %s", err)
+	}
+	if bio.Samples != 1 || bio.Richness != 2 || bio.Individuals != 2 {
+		t.Errorf("Expected only the second day to be included, got %d samples, %d species and %d individuals", bio.Samples, bio.Richness, bio.Individuals)
+	}
+	if math.Abs(bio.Shannon-math.Log(2)) > 1e-9 || math.Abs(bio.Simpson-0.5) > 1e-9 {
+		t.Errorf("Expected maximum diversity for two equally abundant species, got %f and %f", bio.Shannon, bio.Simpson)
+	}
+
+	other := rand.Int63()
+	bio, _ = services.GetBiodiversity(services.StatisticsFilter{SiteID: &other})
+	if bio.Samples != 0 || bio.Richness != 0 {
+		t.Errorf("Expected no samples for another site, got %d", bio.Samples)
+	}
+}
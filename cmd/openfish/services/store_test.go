@@ -0,0 +1,184 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+
+	"github.com/ausocean/cloud/datastore"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+)
+
+// testStore wraps a file store so that queries behave as they do in the cloud datastore. Equality filters
+// on list fields match entities with the value anywhere in the list, limits and offsets are applied after
+// filtering, and keys-only queries are filtered. A file store only matches whole fields, limits entities
+// before filtering them, and ignores field filters in keys-only queries.
+type testStore struct {
+	datastore.Store
+}
+
+// testQuery is a query of a testStore, which holds back equality filters on list fields, limits and offsets.
+type testQuery struct {
+	datastore.Query
+	kind     string
+	keysOnly bool
+	filters  map[string][]any // Values by list field name.
+	limit    int              // Zero for no limit, as in the cloud datastore.
+	offset   int
+}
+
+// NewQuery returns a new query of kind. Keys-only queries read whole entities from the file store, so that
+// they can be filtered.
+func (s testStore) NewQuery(kind string, keysOnly bool, keyParts ...string) datastore.Query {
+	return &testQuery{
+		Query:    s.Store.NewQuery(kind, false, keyParts...),
+		kind:     kind,
+		keysOnly: keysOnly,
+		filters:  map[string][]any{},
+	}
+}
+
+// FilterField filters the query, holding back equality filters on list fields.
+func (q *testQuery) FilterField(fieldName string, operator string, value interface{}) error {
+	e, err := datastore.NewEntity(q.kind)
+	if err != nil {
+		return err
+	}
+	field := reflect.Indirect(reflect.ValueOf(e)).FieldByName(fieldName)
+	if operator == "=" && field.Kind() == reflect.Slice {
+		q.filters[fieldName] = append(q.filters[fieldName], value)
+		return nil
+	}
+	return q.Query.FilterField(fieldName, operator, value)
+}
+
+// Limit limits the number of results returned.
+func (q *testQuery) Limit(limit int) {
+	q.limit = limit
+	q.Query.Limit(limit)
+}
+
+// Offset sets the number of results to skip.
+func (q *testQuery) Offset(offset int) {
+	q.offset = offset
+	q.Query.Offset(offset)
+}
+
+// GetAll runs a query, reading every entity of its kind before filtering them and applying its limit and offset.
+// Keys-only queries return the keys of the matching entities.
+func (s testStore) GetAll(ctx context.Context, q datastore.Query, dst interface{}) ([]*datastore.Key, error) {
+	tq, ok := q.(*testQuery)
+	if !ok {
+		return s.Store.GetAll(ctx, q, dst)
+	}
+	if tq.keysOnly {
+		e, err := datastore.NewEntity(tq.kind)
+		if err != nil {
+			return nil, err
+		}
+		ents := reflect.New(reflect.SliceOf(reflect.TypeOf(e).Elem()))
+		tq.keysOnly = false
+		_, err = s.GetAll(ctx, tq, ents.Interface())
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]*datastore.Key, ents.Elem().Len())
+		for i := range keys {
+			keys[i] = ents.Elem().Index(i).FieldByName("Key").Interface().(*datastore.Key)
+		}
+		return keys, nil
+	}
+	tq.Query.Limit(math.MaxInt32)
+	tq.Query.Offset(0)
+	keys, err := s.Store.GetAll(ctx, tq.Query, dst)
+	if err != nil {
+		return keys, err
+	}
+
+	dv := reflect.ValueOf(dst).Elem()
+	matched := reflect.MakeSlice(dv.Type(), 0, dv.Len())
+	for i := 0; i < dv.Len(); i++ {
+		if matchesLists(dv.Index(i), tq.filters) {
+			matched = reflect.Append(matched, dv.Index(i))
+		}
+	}
+	matched = matched.Slice(min(tq.offset, matched.Len()), matched.Len())
+	if tq.limit > 0 {
+		matched = matched.Slice(0, min(tq.limit, matched.Len()))
+	}
+	dv.Set(matched)
+	return keys, nil
+}
+
+// matchesLists returns true if each list field of entity contains all of its filter values.
+func matchesLists(entity reflect.Value, filters map[string][]any) bool {
+	for name, values := range filters {
+		list := entity.FieldByName(name)
+		for _, v := range values {
+			found := false
+			for i := 0; i < list.Len() && !found; i++ {
+				found = list.Index(i).Interface() == v
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// failingStore wraps a store so that deleting one entity fails, to test recovering from failed writes.
+type failingStore struct {
+	datastore.Store
+	kind string
+	id   int64
+}
+
+// failDelete makes deleting the entity of kind with id fail, until the returned function is called.
+func failDelete(kind string, id int64) func() {
+	store := globals.GetStore()
+	globals.SetStore(failingStore{store, kind, id})
+	return func() { globals.SetStore(store) }
+}
+
+// Delete deletes an entity, failing if it is the entity that cannot be deleted.
+func (s failingStore) Delete(ctx context.Context, key *datastore.Key) error {
+	if key.Kind == s.kind && key.ID == s.id {
+		return errors.New("delete failed")
+	}
+	return s.Store.Delete(ctx, key)
+}
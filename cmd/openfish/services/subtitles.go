@@ -0,0 +1,180 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// minCueDuration is the shortest time in milliseconds a cue is shown for, so annotations of a single
+// keypoint are still visible.
+const minCueDuration = 1000
+
+// subtitleCue is an annotation's time span and the species names shown during it.
+type subtitleCue struct {
+	start videotime.VideoTime
+	end   videotime.VideoTime
+	box   keypoint.BoundingBox
+	text  string
+}
+
+// annotationLabel returns the names of an annotation's consensus species, or every species identified when there
+// is no consensus, using names as a cache of species IDs to names.
+func annotationLabel(a Annotation, names map[int64]string) (string, error) {
+	var ids []int64
+	if a.Consensus.SpeciesID != nil {
+		ids = []int64{*a.Consensus.SpeciesID}
+	} else {
+		for id := range a.Identifications {
+			ids = append(ids, id)
+		}
+		slices.Sort(ids)
+	}
+	if len(ids) == 0 {
+		return "Unidentified", nil
+	}
+
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		name, ok := names[id]
+		if !ok {
+			s, err := GetSpeciesByID(id)
+			if err != nil {
+				return "", fmt.Errorf("could not get species %d: %w", id, err)
+			}
+			name = s.ScientificName
+			if s.CommonName != "" {
+				name = fmt.Sprintf("%s (%s)", s.CommonName, s.ScientificName)
+			}
+			names[id] = name
+		}
+		parts[i] = name
+	}
+	return strings.Join(parts, " or "), nil
+}
+
+// getSubtitleCues gets a cue for each annotation of a video stream, optionally only the annotations with the
+// given consensus status. Cues are sorted by start time, and are positioned at the bounding box of the first keypoint.
+func getSubtitleCues(videoStreamID int64, consensus *ConsensusStatus) ([]subtitleCue, error) {
+	annotations, err := GetAnnotations(0, 0, nil, AnnotationFilter{VideoStreamID: &videoStreamID, Consensus: consensus})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]string)
+	cues := make([]subtitleCue, 0, len(annotations))
+	for _, a := range annotations {
+		if len(a.KeyPoints) == 0 {
+			continue
+		}
+		text, err := annotationLabel(a, names)
+		if err != nil {
+			return nil, err
+		}
+
+		start := a.KeyPoints[0].Time
+		end := a.KeyPoints[len(a.KeyPoints)-1].Time
+		if end.Int()-start.Int() < minCueDuration {
+			end = videotime.FromInt(start.Int() + minCueDuration)
+		}
+		cues = append(cues, subtitleCue{start: start, end: end, box: a.KeyPoints[0].BoundingBox, text: text})
+	}
+
+	slices.SortStableFunc(cues, func(a, b subtitleCue) int {
+		return cmp.Or(cmp.Compare(a.start.Int(), b.start.Int()), cmp.Compare(a.end.Int(), b.end.Int()))
+	})
+	return cues, nil
+}
+
+// webVTTEscaper escapes the characters that are not allowed in the text of WebVTT cues.
+var webVTTEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// formatPercent formats a bounding box coordinate as a WebVTT percentage, clamped to the video.
+func formatPercent(v float32) string {
+	return strconv.FormatFloat(float64(max(0, min(100, v))), 'f', -1, 32) + "%"
+}
+
+// WriteWebVTT writes the annotations of a video stream as a WebVTT subtitle track to w, optionally only the
+// annotations with the given consensus status. Each annotation is a cue from its first keypoint to its last,
+// containing the names of its species, and positioned at the top left of its first bounding box.
+func WriteWebVTT(w io.Writer, videoStreamID int64, consensus *ConsensusStatus) error {
+	cues, err := getSubtitleCues(videoStreamID, consensus)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, "WEBVTT\n")
+	for i, c := range cues {
+		fmt.Fprintf(bw, "\n%d\n%s --> %s position:%s,line-left line:%s,start size:%s align:left\n%s\n",
+			i+1, c.start, c.end,
+			formatPercent(c.box.X1), formatPercent(c.box.Y1), formatPercent(c.box.X2-c.box.X1),
+			webVTTEscaper.Replace(c.text),
+		)
+	}
+	return bw.Flush()
+}
+
+// WriteSRT writes the annotations of a video stream as a SubRip subtitle track to w, optionally only the
+// annotations with the given consensus status. Each annotation is a cue from its first keypoint to its last,
+// containing the names of its species. SubRip has no standard way to position cues.
+func WriteSRT(w io.Writer, videoStreamID int64, consensus *ConsensusStatus) error {
+	cues, err := getSubtitleCues(videoStreamID, consensus)
+	if err != nil {
+		return err
+	}
+
+	// SubRip uses a comma before the milliseconds.
+	srtTime := func(t videotime.VideoTime) string {
+		return strings.Replace(t.String(), ".", ",", 1)
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, c := range cues {
+		if i > 0 {
+			fmt.Fprint(bw, "\n")
+		}
+		fmt.Fprintf(bw, "%d\n%s --> %s\n%s\n", i+1, srtTime(c.start), srtTime(c.end), c.text)
+	}
+	return bw.Flush()
+}
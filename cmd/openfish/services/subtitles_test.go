@@ -0,0 +1,99 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+)
+
+func TestWriteWebVTT(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+
+	var buf bytes.Buffer
+	err := services.WriteWebVTT(&buf, a.VideostreamID, nil)
+	if err != nil {
+		t.Fatalf("Could not write WebVTT %s", err)
+	}
+
+	expected := "WEBVTT\n\n" +
+		"1\n00:00:01.000 --> 00:00:02.000 position:10%,line-left line:70%,start size:10% align:left\n" +
+		"Southern Reef Squid (Sepioteuthis australis)\n"
+	if buf.String() != expected {
+		t.Errorf("Expected WebVTT %q, got %q", expected, buf.String())
+	}
+}
+
+func TestWriteWebVTTEscapesText(t *testing.T) {
+	setup()
+	sp, _ := services.CreateSpecies(services.SpeciesContents{
+		ScientificName: "Nemadactylus <douglasii>",
+		CommonName:     "Blue Morwong & Friends",
+	})
+	a := createTestAnnotation()
+	contents := a.AnnotationContents
+	contents.Identifications = map[int64][]int64{sp.ID: {a.CreatedByID}}
+	services.CreateAnnotation(contents)
+
+	var buf bytes.Buffer
+	err := services.WriteWebVTT(&buf, a.VideostreamID, nil)
+	if err != nil {
+		t.Fatalf("Could not write WebVTT %s", err)
+	}
+
+	expected := "Blue Morwong &amp; Friends (Nemadactylus &lt;douglasii&gt;)\n"
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("Expected WebVTT to contain %q, got %q", expected, buf.String())
+	}
+}
+
+func TestWriteSRT(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+
+	var buf bytes.Buffer
+	err := services.WriteSRT(&buf, a.VideostreamID, nil)
+	if err != nil {
+		t.Fatalf("Could not write SRT %s", err)
+	}
+
+	expected := "1\n00:00:01,000 --> 00:00:02,000\nSouthern Reef Squid (Sepioteuthis australis)\n"
+	if buf.String() != expected {
+		t.Errorf("Expected SRT %q, got %q", expected, buf.String())
+	}
+}
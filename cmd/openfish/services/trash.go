@@ -0,0 +1,330 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ausocean/cloud/datastore"
+	"github.com/ausocean/openfish/cmd/openfish/entities"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// ErrRestoreConflict is returned when a deleted entity cannot be restored because
+// an entity it depends on no longer exists.
+var ErrRestoreConflict = errors.New("cannot restore")
+
+// ErrNotInTrash is returned when restoring an entity that is not in the trash.
+var ErrNotInTrash = errors.New("not in trash")
+
+// TrashKind is the kind of entity that can be deleted and restored.
+type TrashKind string
+
+const (
+	TrashAnnotation      TrashKind = "annotation"
+	TrashPointAnnotation TrashKind = "pointannotation"
+	TrashVideoStream     TrashKind = "videostream"
+	TrashSpecies         TrashKind = "species"
+	TrashCaptureSource   TrashKind = "capturesource"
+)
+
+// trashKinds maps each TrashKind to its datastore kind and entity constructor.
+var trashKinds = map[TrashKind]struct {
+	kind      string
+	newEntity func() datastore.Entity
+}{
+	TrashAnnotation:      {entities.ANNOTATION_KIND, entities.NewAnnotation},
+	TrashPointAnnotation: {entities.POINT_ANNOTATION_KIND, entities.NewPointAnnotation},
+	TrashVideoStream:     {entities.VIDEOSTREAM_KIND, entities.NewVideoStream},
+	TrashSpecies:         {entities.SPECIES_KIND, entities.NewSpecies},
+	TrashCaptureSource:   {entities.CAPTURESOURCE_KIND, entities.NewCaptureSource},
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (k *TrashKind) UnmarshalText(text []byte) error {
+	if _, ok := trashKinds[TrashKind(text)]; !ok {
+		return fmt.Errorf("invalid kind: %s", text)
+	}
+	*k = TrashKind(text)
+	return nil
+}
+
+// TrashItem is a deleted entity that can be restored until it is purged.
+//
+// Rather than marking entities with a deleted-at time, deleting an entity moves a snapshot of it into the trash
+// and removes it from its own kind. This way every existing list and get excludes deleted entities, without
+// each query needing a filter that could be forgotten.
+type TrashItem struct {
+	Kind        TrashKind `json:"kind" swaggertype:"string" enums:"annotation,pointannotation,videostream,species,capturesource" example:"videostream"`
+	ID          int64     `json:"id" example:"1234567890"` // ID of the deleted entity.
+	Name        string    `json:"name" example:"https://www.youtube.com/watch?v=abcdefghijk"`
+	DeletedAt   time.Time `json:"deleted_at" example:"2023-05-25T08:00:00Z"`
+	DeletedByID int64     `json:"deleted_by_id" example:"1234567890"`
+}
+
+// trashKey returns the key of the trash item for a deleted entity.
+func trashKey(kind string, id int64) *datastore.Key {
+	return globals.GetStore().NameKey(entities.TRASH_KIND, fmt.Sprintf("%s.%d", kind, id))
+}
+
+// trashName describes a deleted entity so that it can be recognised in the trash.
+func trashName(e datastore.Entity) string {
+	switch e := e.(type) {
+	case *entities.Annotation:
+		return fmt.Sprintf("video stream %d at %s", e.VideoStreamID, videotime.FromInt(e.Start).String())
+	case *entities.PointAnnotation:
+		return fmt.Sprintf("%d points in video stream %d at %s", e.Count, e.VideoStreamID, videotime.FromInt(e.Time).String())
+	case *entities.VideoStream:
+		return e.StreamURL
+	case *entities.Species:
+		return e.ScientificName
+	case *entities.CaptureSource:
+		return e.Name
+	default:
+		return ""
+	}
+}
+
+// putTrash stores a snapshot of an entity in the trash. The entity itself is not deleted.
+func putTrash(kind TrashKind, id int64, e datastore.Entity, userID int64) error {
+	snapshot, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	store := globals.GetStore()
+	_, err = store.Put(context.Background(), trashKey(trashKinds[kind].kind, id), &entities.TrashItem{
+		Kind:      string(kind),
+		EntityID:  id,
+		Name:      trashName(e),
+		DeletedAt: time.Now(),
+		DeletedBy: userID,
+		Snapshot:  snapshot,
+	})
+	return err
+}
+
+// discardTrash removes the trash items of entities that failed to be deleted, so that they are not left both
+// in use and in the trash. Trash items are kept for entities that were deleted despite the failure.
+func discardTrash(kind TrashKind, ids ...int64) error {
+	k := trashKinds[kind]
+	store := globals.GetStore()
+	var keys []*datastore.Key
+	for _, id := range ids {
+		err := store.Get(context.Background(), store.IDKey(k.kind, id), k.newEntity())
+		if errors.Is(err, datastore.ErrNoSuchEntity) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		keys = append(keys, trashKey(k.kind, id))
+	}
+	_, err := datastore.DeleteMulti(context.Background(), store, keys)
+	return err
+}
+
+// moveToTrash deletes an entity, keeping a snapshot of it in the trash. If the entity cannot be deleted, its
+// snapshot is removed from the trash again.
+func moveToTrash(kind TrashKind, id int64, userID int64) error {
+	k := trashKinds[kind]
+	store := globals.GetStore()
+	key := store.IDKey(k.kind, id)
+	e := k.newEntity()
+	err := store.Get(context.Background(), key, e)
+	if err != nil {
+		return err
+	}
+
+	err = putTrash(kind, id, e, userID)
+	if err != nil {
+		return err
+	}
+
+	err = store.Delete(context.Background(), key)
+	if err != nil {
+		return errors.Join(err, discardTrash(kind, id))
+	}
+	return nil
+}
+
+// GetTrash gets a list of deleted entities, most recently deleted first, filtering by kind if specified.
+func GetTrash(limit int, offset int, kind *TrashKind) ([]TrashItem, error) {
+	store := globals.GetStore()
+	query := store.NewQuery(entities.TRASH_KIND, false)
+	if kind != nil {
+		query.FilterField("Kind", "=", string(*kind))
+	}
+
+	// Apply pagination and ordering.
+	if limit > 0 {
+		query.Limit(limit)
+	}
+	query.Offset(offset)
+	query.Order("-DeletedAt")
+
+	var ents []entities.TrashItem
+	_, err := store.GetAll(context.Background(), query, &ents)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]TrashItem, len(ents))
+	for i, e := range ents {
+		items[i] = TrashItem{
+			Kind:        TrashKind(e.Kind),
+			ID:          e.EntityID,
+			Name:        e.Name,
+			DeletedAt:   e.DeletedAt,
+			DeletedByID: e.DeletedBy,
+		}
+	}
+
+	return items, nil
+}
+
+// RestoreFromTrash puts a deleted entity back under its original ID and removes it from the trash.
+// Restoring an annotation adds a revision to its history. Returns ErrRestoreConflict if the
+// annotation's video stream, the point annotation's video stream or species, or the video stream's
+// capture source no longer exists. If the entity was never deleted, because deleting it failed after
+// it was put in the trash, its trash item is removed and ErrNotInTrash is returned, so that the
+// entity is not overwritten with an out of date snapshot.
+func RestoreFromTrash(kind TrashKind, id int64, userID int64) error {
+	k := trashKinds[kind]
+	store := globals.GetStore()
+	tkey := trashKey(k.kind, id)
+
+	var item entities.TrashItem
+	err := store.Get(context.Background(), tkey, &item)
+	if errors.Is(err, datastore.ErrNoSuchEntity) {
+		return fmt.Errorf("%w: %s %d", ErrNotInTrash, kind, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	key := store.IDKey(k.kind, id)
+	err = store.Get(context.Background(), key, k.newEntity())
+	if err == nil {
+		return errors.Join(fmt.Errorf("%w: %s %d was not deleted", ErrNotInTrash, kind, id), store.Delete(context.Background(), tkey))
+	}
+	if !errors.Is(err, datastore.ErrNoSuchEntity) {
+		return err
+	}
+
+	e := k.newEntity()
+	err = json.Unmarshal(item.Snapshot, e)
+	if err != nil {
+		return err
+	}
+
+	// Check that the entities this depends on have not been deleted.
+	switch e := e.(type) {
+	case *entities.Annotation:
+		if !VideoStreamExists(e.VideoStreamID) {
+			return fmt.Errorf("%w: video stream %d does not exist", ErrRestoreConflict, e.VideoStreamID)
+		}
+		revisions, err := getRevisionEntities(id)
+		if err != nil {
+			return err
+		}
+		if len(revisions) > 0 {
+			e.Version = revisions[len(revisions)-1].Version + 1
+		}
+		setLastChange(e, revisionInfo{change: ChangeRestored, userID: userID})
+	case *entities.PointAnnotation:
+		if !VideoStreamExists(e.VideoStreamID) {
+			return fmt.Errorf("%w: video stream %d does not exist", ErrRestoreConflict, e.VideoStreamID)
+		}
+		if !SpeciesExists(e.SpeciesID) {
+			return fmt.Errorf("%w: species %d does not exist", ErrRestoreConflict, e.SpeciesID)
+		}
+	case *entities.VideoStream:
+		if !CaptureSourceExists(e.CaptureSource) {
+			return fmt.Errorf("%w: capture source %d does not exist", ErrRestoreConflict, e.CaptureSource)
+		}
+	}
+
+	_, err = store.Put(context.Background(), key, e)
+	if err != nil {
+		return err
+	}
+
+	err = store.Delete(context.Background(), tkey)
+	if err != nil {
+		return err
+	}
+
+	if a, ok := e.(*entities.Annotation); ok {
+		return recordRevision(id, *a)
+	}
+	return nil
+}
+
+// PurgeTrash permanently removes entities that were deleted before the given time,
+// returning the number of entities removed. The revisions of purged annotations are
+// deleted too, so that they cannot be restored from their history.
+func PurgeTrash(before time.Time) (int, error) {
+	store := globals.GetStore()
+	query := store.NewQuery(entities.TRASH_KIND, true)
+	query.FilterField("DeletedAt", "<", before)
+	keys, err := store.GetAll(context.Background(), query, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// Trash items are keyed by the kind and ID of the deleted entity.
+	for _, k := range keys {
+		kind, id, _ := strings.Cut(k.Name, ".")
+		if kind != entities.ANNOTATION_KIND {
+			continue
+		}
+		annotationID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid trash key %s: %w", k.Name, err)
+		}
+		err = deleteRevisions(annotationID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return datastore.DeleteMulti(context.Background(), store, keys)
+}
@@ -0,0 +1,264 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ausocean/openfish/cmd/openfish/entities"
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+)
+
+// findTrash returns the trash item for a deleted entity, or nil if it is not in the trash.
+func findTrash(t *testing.T, kind services.TrashKind, id int64) *services.TrashItem {
+	trash, err := services.GetTrash(0, 0, &kind)
+	if err != nil {
+		t.Fatalf("Could not get trash %s", err)
+	}
+	for i := range trash {
+		if trash[i].ID == id {
+			return &trash[i]
+		}
+	}
+	return nil
+}
+
+func TestDeleteAnnotationMovesToTrash(t *testing.T) {
+	setup()
+	created := createTestAnnotation()
+
+	err := services.DeleteAnnotation(created.ID, created.CreatedByID)
+	if err != nil {
+		t.Fatalf("Could not delete annotation %s", err)
+	}
+
+	// Deleted annotations should not be returned.
+	_, err = services.GetAnnotationByID(created.ID)
+	if err == nil {
+		t.Errorf("Expected deleted annotation to not be returned")
+	}
+	annotations, _ := services.GetAnnotations(0, 0, nil, services.AnnotationFilter{VideoStreamID: &created.VideostreamID})
+	if len(annotations) != 0 {
+		t.Errorf("Expected no annotations to be listed, got %d", len(annotations))
+	}
+
+	item := findTrash(t, services.TrashAnnotation, created.ID)
+	if item == nil || item.DeletedByID != created.CreatedByID {
+		t.Errorf("Expected deleted annotation in trash, got %v", item)
+	}
+}
+
+func TestRestoreAnnotationFromTrash(t *testing.T) {
+	setup()
+	created := createTestAnnotation()
+	services.DeleteAnnotation(created.ID, created.CreatedByID)
+
+	err := services.RestoreFromTrash(services.TrashAnnotation, created.ID, created.CreatedByID)
+	if err != nil {
+		t.Fatalf("Could not restore annotation %s", err)
+	}
+
+	restored, err := services.GetAnnotationByID(created.ID)
+	if err != nil {
+		t.Fatalf("Expected annotation to be restored %s", err)
+	}
+	if restored.VideostreamID != created.VideostreamID || len(restored.KeyPoints) != len(created.KeyPoints) {
+		t.Errorf("Restored annotation does not match original, expected %v, got %v", created.AnnotationContents, restored.AnnotationContents)
+	}
+
+	history, _ := services.GetAnnotationHistory(created.ID)
+	last := history[len(history)-1]
+	if last.Change != services.ChangeRestored || last.Version != restored.Version {
+		t.Errorf("Expected restore to be recorded in history")
+	}
+
+	if findTrash(t, services.TrashAnnotation, created.ID) != nil {
+		t.Errorf("Expected restored annotation to be removed from the trash")
+	}
+}
+
+func TestRestoreVideoStreamFromTrash(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	services.DeleteVideoStream(vs.ID, int64(123456789), false)
+
+	if services.VideoStreamExists(vs.ID) {
+		t.Errorf("Expected video stream to be deleted")
+	}
+
+	err := services.RestoreFromTrash(services.TrashVideoStream, vs.ID, int64(123456789))
+	if err != nil {
+		t.Fatalf("Could not restore video stream %s", err)
+	}
+
+	restored, err := services.GetVideoStreamByID(vs.ID)
+	if err != nil {
+		t.Fatalf("Expected video stream to be restored %s", err)
+	}
+	if restored.StreamURL != vs.StreamURL {
+		t.Errorf("Restored video stream does not match original, expected %s, got %s", vs.StreamURL, restored.StreamURL)
+	}
+}
+
+func TestRestoreWithDeletedParent(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	services.DeleteCaptureSource(vs.CaptureSource, int64(123456789), true)
+
+	err := services.RestoreFromTrash(services.TrashVideoStream, vs.ID, int64(123456789))
+	if !errors.Is(err, services.ErrRestoreConflict) {
+		t.Errorf("Expected ErrRestoreConflict, got %v", err)
+	}
+
+	// Restoring the capture source first allows the video stream to be restored.
+	services.RestoreFromTrash(services.TrashCaptureSource, vs.CaptureSource, int64(123456789))
+	err = services.RestoreFromTrash(services.TrashVideoStream, vs.ID, int64(123456789))
+	if err != nil {
+		t.Errorf("Could not restore video stream %s", err)
+	}
+}
+
+func TestRestorePointAnnotationFromTrash(t *testing.T) {
+	setup()
+	vs := createTestVideoStream()
+	sp := createTestSpecies()
+	user := createTestUserWithRole("annotator", role.Annotator)
+	created := createTestPointAnnotation(vs, sp, user, "00:00:01.000", 3)
+	services.DeleteSpecies(sp.ID, user, true)
+
+	if findTrash(t, services.TrashPointAnnotation, created.ID) == nil {
+		t.Fatalf("Expected cascaded point annotation in trash")
+	}
+
+	// The species must be restored before the point annotation.
+	err := services.RestoreFromTrash(services.TrashPointAnnotation, created.ID, user)
+	if !errors.Is(err, services.ErrRestoreConflict) {
+		t.Errorf("Expected ErrRestoreConflict, got %v", err)
+	}
+
+	services.RestoreFromTrash(services.TrashSpecies, sp.ID, user)
+	err = services.RestoreFromTrash(services.TrashPointAnnotation, created.ID, user)
+	if err != nil {
+		t.Fatalf("Could not restore point annotation %s", err)
+	}
+	restored, err := services.GetPointAnnotationByID(created.ID)
+	if err != nil {
+		t.Fatalf("Expected point annotation to be restored %s", err)
+	}
+	if len(restored.Points) != 3 {
+		t.Errorf("Expected 3 points, got %d", len(restored.Points))
+	}
+}
+
+func TestRestoreNotDeleted(t *testing.T) {
+	setup()
+	sp := createTestSpecies()
+
+	// A trash item left behind by a delete that failed.
+	store := globals.GetStore()
+	key := store.NameKey(entities.TRASH_KIND, fmt.Sprintf("%s.%d", entities.SPECIES_KIND, sp.ID))
+	store.Put(context.Background(), key, &entities.TrashItem{Kind: string(services.TrashSpecies), EntityID: sp.ID, Snapshot: []byte("{}")})
+
+	err := services.RestoreFromTrash(services.TrashSpecies, sp.ID, int64(123456789))
+	if !errors.Is(err, services.ErrNotInTrash) {
+		t.Errorf("Expected ErrNotInTrash, got %v", err)
+	}
+	if findTrash(t, services.TrashSpecies, sp.ID) != nil {
+		t.Errorf("Expected trash item of species that was not deleted to be removed")
+	}
+	species, _ := services.GetSpeciesByID(sp.ID)
+	if species == nil || species.ScientificName != sp.ScientificName {
+		t.Errorf("Expected species to be unchanged, got %v", species)
+	}
+}
+
+func TestRestoreNotInTrash(t *testing.T) {
+	setup()
+
+	err := services.RestoreFromTrash(services.TrashSpecies, int64(123456789), int64(123456789))
+	if !errors.Is(err, services.ErrNotInTrash) {
+		t.Errorf("Expected ErrNotInTrash, got %v", err)
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	setup()
+	sp := createTestSpecies()
+	services.DeleteSpecies(sp.ID, int64(123456789), false)
+
+	// Items deleted after the cutoff are kept.
+	_, err := services.PurgeTrash(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Could not purge trash %s", err)
+	}
+	if findTrash(t, services.TrashSpecies, sp.ID) == nil {
+		t.Errorf("Expected recently deleted species to be kept")
+	}
+
+	n, _ := services.PurgeTrash(time.Now().Add(time.Hour))
+	if n < 1 {
+		t.Errorf("Expected at least 1 item to be purged, got %d", n)
+	}
+
+	err = services.RestoreFromTrash(services.TrashSpecies, sp.ID, int64(123456789))
+	if !errors.Is(err, services.ErrNotInTrash) {
+		t.Errorf("Expected purged species to not be restorable, got %v", err)
+	}
+}
+
+func TestPurgeTrashDeletesRevisions(t *testing.T) {
+	setup()
+	created := createTestAnnotation()
+	services.DeleteAnnotation(created.ID, created.CreatedByID)
+
+	_, err := services.PurgeTrash(time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Could not purge trash %s", err)
+	}
+
+	history, _ := services.GetAnnotationHistory(created.ID)
+	if len(history) != 0 {
+		t.Errorf("Expected revisions of purged annotation to be deleted, got %d", len(history))
+	}
+	err = services.RestoreAnnotation(created.ID, created.Version, created.CreatedByID)
+	if !errors.Is(err, services.ErrRevisionNotFound) {
+		t.Errorf("Expected purged annotation to not be restorable, got %v", err)
+	}
+}
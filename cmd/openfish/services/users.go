@@ -122,20 +122,20 @@ func GetUserByEmail(email string) (*User, error) {
 	query := store.NewQuery(entities.USER_KIND, false)
 
 	query.FilterField("Email", "=", email)
-	query.Limit(1)
 
+	// The query is not limited, as a file store limits the results before filtering them.
 	var users []entities.User
-	keys, err := store.GetAll(context.Background(), query, &users)
+	_, err := store.GetAll(context.Background(), query, &users)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(keys) == 0 || len(users) == 0 {
+	if len(users) == 0 {
 		return nil, datastore.ErrNoSuchEntity
 	}
 
 	return &User{
-		ID:           keys[0].ID,
+		ID:           users[0].Key.ID,
 		UserContents: UserContentsFromEntity(users[0]),
 	}, nil
 }
@@ -44,6 +44,7 @@ import (
 	"github.com/ausocean/openfish/cmd/openfish/globals"
 	"github.com/ausocean/openfish/cmd/openfish/types/timespan"
 	"github.com/ausocean/openfish/cmd/openfish/types/timezone"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
 )
 
 // VideoStream is a video stream registered to OpenFish, for annotation and playback.
@@ -65,6 +66,8 @@ type BaseVideoStreamFields struct {
 	TimeZone      timezone.TimeZone `json:"timezone" swaggertype:"string" example:"Australia/Adelaide"`
 	StreamURL     string            `json:"stream_url" example:"https://www.youtube.com/watch?v=abcdefghijk"`
 	CaptureSource int64             `json:"capturesource" example:"1234567890"`
+	Width         int               `json:"width,omitempty" example:"1920"`  // Width of the video in pixels.
+	Height        int               `json:"height,omitempty" example:"1080"` // Height of the video in pixels.
 }
 
 // PartialVideoStreamContents represents optional fields that can be updated for a video stream.
@@ -75,6 +78,8 @@ type PartialVideoStreamContents struct {
 	StreamURL     *string            `json:"stream_url,omitempty" example:"https://www.youtube.com/watch?v=abcdefghijk"`
 	CaptureSource *int64             `json:"capturesource,omitempty" example:"1234567890"`
 	AnnotatorList *[]int64           `json:"annotator_list,omitempty" example:"1234567890"`
+	Width         *int               `json:"width,omitempty" example:"1920"`
+	Height        *int               `json:"height,omitempty" example:"1080"`
 }
 
 // VideoStreamWithJoins represents a video stream with its related entities joined.
@@ -86,6 +91,8 @@ type VideoStreamWithJoins struct {
 	StreamURL     string               `json:"stream_url" example:"https://www.youtube.com/watch?v=abcdefghijk"`
 	CaptureSource CaptureSourceSummary `json:"capturesource"`
 	AnnotatorList []PublicUser         `json:"annotator_list"`
+	Width         int                  `json:"width,omitempty" example:"1920"`
+	Height        int                  `json:"height,omitempty" example:"1080"`
 }
 
 // VideoStreamSummary is a summary of a video stream.
@@ -102,6 +109,11 @@ func (v *VideoStream) ToSummary() VideoStreamSummary {
 	}
 }
 
+// TimeAt returns the date and time at the given time within the video stream.
+func (v *VideoStream) TimeAt(t videotime.VideoTime) time.Time {
+	return v.StartTime.Add(time.Duration(t.Int()) * time.Millisecond)
+}
+
 // JoinFields joins the foreign key fields of a videostream with their respective entities.
 func (v *VideoStream) JoinFields() (*VideoStreamWithJoins, error) {
 
@@ -131,6 +143,8 @@ func (v *VideoStream) JoinFields() (*VideoStreamWithJoins, error) {
 		StreamURL:     v.StreamURL,
 		CaptureSource: captureSource.ToSummary(),
 		AnnotatorList: annotatorList,
+		Width:         v.Width,
+		Height:        v.Height,
 	}, nil
 }
 
@@ -144,6 +158,8 @@ func VideoStreamContentsFromEntity(v entities.VideoStream) VideoStreamContents {
 			TimeZone:      timezone.UncheckedParse(v.TimeZone),
 			StreamURL:     v.StreamURL,
 			CaptureSource: v.CaptureSource,
+			Width:         v.Width,
+			Height:        v.Height,
 		},
 	}
 }
@@ -157,6 +173,8 @@ func (v *VideoStreamContents) ToEntity() entities.VideoStream {
 		StreamURL:     v.StreamURL,
 		CaptureSource: v.CaptureSource,
 		AnnotatorList: v.AnnotatorList,
+		Width:         v.Width,
+		Height:        v.Height,
 	}
 }
 
@@ -186,13 +204,14 @@ func VideoStreamExists(id int64) bool {
 }
 
 // GetVideoStreams gets a list of video streams, filtering by timespan, capturesource if specified.
+// A limit of zero returns all video streams.
 func GetVideoStreams(limit int, offset int, timespan *timespan.TimeSpan, captureSource *int64) ([]VideoStream, error) {
 	// Fetch data from the datastore.
 	store := globals.GetStore()
 	query := store.NewQuery(entities.VIDEOSTREAM_KIND, false)
 
 	if captureSource != nil {
-		query.FilterField("CaptureSource", "=", captureSource)
+		query.FilterField("CaptureSource", "=", *captureSource)
 	}
 
 	if timespan != nil {
@@ -204,11 +223,13 @@ func GetVideoStreams(limit int, offset int, timespan *timespan.TimeSpan, capture
 
 	// TODO: implement filtering based on location
 
-	query.Limit(limit)
+	if limit > 0 {
+		query.Limit(limit)
+	}
 	query.Offset(offset)
 
 	var ents []entities.VideoStream
-	keys, err := store.GetAll(context.Background(), query, &ents)
+	_, err := store.GetAll(context.Background(), query, &ents)
 	if err != nil {
 		return []VideoStream{}, err
 	}
@@ -217,7 +238,7 @@ func GetVideoStreams(limit int, offset int, timespan *timespan.TimeSpan, capture
 	videoStreams := make([]VideoStream, len(ents))
 	for i := range ents {
 		videoStreams[i] = VideoStream{
-			ID:                  keys[i].ID,
+			ID:                  ents[i].Key.ID,
 			VideoStreamContents: VideoStreamContentsFromEntity(ents[i]),
 		}
 	}
@@ -225,6 +246,40 @@ func GetVideoStreams(limit int, offset int, timespan *timespan.TimeSpan, capture
 	return videoStreams, nil
 }
 
+// findVideoStreams gets all video streams produced by the capture source that overlap the given date range.
+// Nil arguments are not filtered on.
+func findVideoStreams(captureSource *int64, from *time.Time, to *time.Time) ([]VideoStream, error) {
+	store := globals.GetStore()
+	query := store.NewQuery(entities.VIDEOSTREAM_KIND, false)
+
+	if captureSource != nil {
+		query.FilterField("CaptureSource", "=", *captureSource)
+	}
+	if to != nil {
+		query.FilterField("StartTime", "<=", *to)
+	}
+
+	var ents []entities.VideoStream
+	_, err := store.GetAll(context.Background(), query, &ents)
+	if err != nil {
+		return nil, err
+	}
+
+	// Datastore only supports inequality filters on one field, so the end time is filtered here.
+	videoStreams := make([]VideoStream, 0, len(ents))
+	for i := range ents {
+		if from != nil && ents[i].EndTime != nil && ents[i].EndTime.Before(*from) {
+			continue
+		}
+		videoStreams = append(videoStreams, VideoStream{
+			ID:                  ents[i].Key.ID,
+			VideoStreamContents: VideoStreamContentsFromEntity(ents[i]),
+		})
+	}
+
+	return videoStreams, nil
+}
+
 // CreateVideoStream puts a video stream in the datastore, checking if the capture source exists.
 func CreateVideoStream(contents VideoStreamContents) (*VideoStream, error) {
 
@@ -277,16 +332,83 @@ func UpdateVideoStream(id int64, updates PartialVideoStreamContents) error {
 			if updates.AnnotatorList != nil {
 				v.AnnotatorList = *updates.AnnotatorList
 			}
+			if updates.Width != nil {
+				v.Width = *updates.Width
+			}
+			if updates.Height != nil {
+				v.Height = *updates.Height
+			}
 		}
 	}, &videoStream)
 }
 
-// DeleteVideoStream deletes a video stream.
-func DeleteVideoStream(id int64) error {
-	// TODO: Check that video stream has no annotations associated with it.
+// DeleteVideoStream deletes a video stream, moving it to the trash. If the video stream has annotations
+// or point annotations, ErrHasDependants is returned unless cascade is true, in which case they are moved
+// to the trash too. The cascade is all or nothing: if it fails part way, everything it deleted is restored.
+func DeleteVideoStream(id int64, userID int64, cascade bool) error {
+	if !cascade {
+		annotations, err := countDependants(entities.ANNOTATION_KIND, "VideoStreamID", id)
+		if err != nil {
+			return err
+		}
+		points, err := countDependants(entities.POINT_ANNOTATION_KIND, "VideoStreamID", id)
+		if err != nil {
+			return err
+		}
+		err = checkDependants(fmt.Sprintf("video stream %d", id),
+			dependants{"annotations", annotations},
+			dependants{"point annotations", points},
+		)
+		if err != nil {
+			return err
+		}
+		return moveToTrash(TrashVideoStream, id, userID)
+	}
 
-	// Delete entity.
-	store := globals.GetStore()
-	key := store.IDKey(entities.VIDEOSTREAM_KIND, id)
-	return store.Delete(context.Background(), key)
+	c := &cascadeDelete{userID: userID}
+	err := c.deleteVideoStream(id)
+	if err != nil {
+		return c.rollback(err)
+	}
+	return nil
+}
+
+// deleteVideoStream deletes a video stream and its dependants as part of a cascade. Dependants created
+// while the cascade runs are deleted after the video stream.
+func (c *cascadeDelete) deleteVideoStream(id int64) error {
+	err := c.deleteVideoStreamDependants(id)
+	if err != nil {
+		return err
+	}
+	err = c.trash(depthVideoStream, TrashVideoStream, id)
+	if err != nil {
+		return err
+	}
+	return c.deleteVideoStreamDependants(id)
+}
+
+// deleteVideoStreamDependants deletes the annotations and point annotations on a video stream.
+func (c *cascadeDelete) deleteVideoStreamDependants(id int64) error {
+	annotations, err := GetAnnotations(0, 0, nil, AnnotationFilter{VideoStreamID: &id})
+	if err != nil {
+		return err
+	}
+	points, err := GetPointAnnotations(0, 0, PointAnnotationFilter{VideoStreamID: &id})
+	if err != nil {
+		return err
+	}
+
+	for _, a := range annotations {
+		err := ignoreDeleted(c.trash(depthAnnotation, TrashAnnotation, a.ID))
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range points {
+		err := ignoreDeleted(c.trash(depthAnnotation, TrashPointAnnotation, p.ID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
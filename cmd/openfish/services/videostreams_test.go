@@ -34,10 +34,12 @@ LICENSE
 package services_test
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/ausocean/openfish/cmd/openfish/entities"
 	"github.com/ausocean/openfish/cmd/openfish/services"
 	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
 	"github.com/ausocean/openfish/cmd/openfish/types/role"
@@ -203,7 +205,7 @@ func TestDeleteVideoStream(t *testing.T) {
 	vs := createTestVideoStream()
 
 	// Delete the video stream entity.
-	err := services.DeleteVideoStream(vs.ID)
+	err := services.DeleteVideoStream(vs.ID, int64(123456789), false)
 	if err != nil {
 		t.Errorf("Could not delete video stream entity %d: %s", vs.ID, err)
 	}
@@ -217,18 +219,13 @@ func TestDeleteVideoStream(t *testing.T) {
 func TestDeleteVideoStreamForNonexistentEntity(t *testing.T) {
 	setup()
 
-	err := services.DeleteVideoStream(int64(123456789))
+	err := services.DeleteVideoStream(int64(123456789), int64(123456789), false)
 	if err == nil {
 		t.Errorf("Did not receive expected error when deleting non-existent video stream")
 	}
 }
 
 func TestDeleteVideoStreamWithAssociatedAnnotations(t *testing.T) {
-	// TODO: Run test in CI when issue is fixed.
-	if testing.Short() {
-		t.Skip("skipping testing in short mode")
-	}
-
 	setup()
 
 	// Create a new video stream entity and an annotation that references it.
@@ -255,8 +252,51 @@ func TestDeleteVideoStreamWithAssociatedAnnotations(t *testing.T) {
 		CreatedByID:   uid,
 	})
 
-	err := services.DeleteVideoStream(vs.ID)
-	if err == nil {
+	err := services.DeleteVideoStream(vs.ID, int64(123456789), false)
+	if !errors.Is(err, services.ErrHasDependants) {
 		t.Errorf("Did not receive expected error when deleting video stream with associated annotation")
 	}
 }
+
+func TestDeleteVideoStreamCascade(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+
+	err := services.DeleteVideoStream(a.VideostreamID, int64(123456789), true)
+	if err != nil {
+		t.Fatalf("Could not delete video stream with cascade %s", err)
+	}
+	if services.VideoStreamExists(a.VideostreamID) || services.AnnotationExists(a.ID) {
+		t.Errorf("Expected video stream and annotation to be deleted")
+	}
+
+	// Deleted annotations can be restored along with their video stream.
+	if findTrash(t, services.TrashAnnotation, a.ID) == nil {
+		t.Errorf("Expected deleted annotation in trash")
+	}
+}
+
+func TestDeleteVideoStreamCascadeRollsBack(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+	vs, _ := services.GetVideoStreamByID(a.VideostreamID)
+	sp := createTestSpecies()
+	p := createTestPointAnnotation(*vs, sp, a.CreatedByID, "00:00:01.000", 3)
+
+	restore := failDelete(entities.VIDEOSTREAM_KIND, vs.ID)
+	err := services.DeleteVideoStream(vs.ID, int64(123456789), true)
+	restore()
+	if err == nil {
+		t.Fatalf("Expected cascade to fail")
+	}
+
+	if !services.VideoStreamExists(vs.ID) || !services.AnnotationExists(a.ID) {
+		t.Errorf("Expected video stream and annotation to be kept")
+	}
+	if _, err := services.GetPointAnnotationByID(p.ID); err != nil {
+		t.Errorf("Expected point annotation to be restored %s", err)
+	}
+	if findTrash(t, services.TrashAnnotation, a.ID) != nil || findTrash(t, services.TrashPointAnnotation, p.ID) != nil || findTrash(t, services.TrashVideoStream, vs.ID) != nil {
+		t.Errorf("Expected nothing to be left in the trash")
+	}
+}
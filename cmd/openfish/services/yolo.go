@@ -0,0 +1,184 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ausocean/openfish/cmd/openfish/globals"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+)
+
+// clamp limits v to the range [0, 1].
+func clamp(v float32) float32 {
+	return min(max(v, 0), 1)
+}
+
+// yoloName returns the file name (without extension) of the image and labels of a frame in a YOLO dataset.
+func yoloName(f *exportFrame) string {
+	return fmt.Sprintf("%d_%08d", f.VideoStream.ID, f.Time.Int())
+}
+
+// yoloLabels returns the labels of a frame in YOLO format, one line per bounding box with the class and
+// normalised center x, center y, width and height. If segments is true, each line is instead the class
+// and the normalised vertices of the annotation's polygon, with bounding boxes written as rectangles.
+func yoloLabels(f *exportFrame, segments bool) string {
+	var sb strings.Builder
+	for _, b := range f.Boxes {
+		x1, x2 := clamp(b.BoundingBox.X1/100), clamp(b.BoundingBox.X2/100)
+		y1, y2 := clamp(b.BoundingBox.Y1/100), clamp(b.BoundingBox.Y2/100)
+		if !segments {
+			fmt.Fprintf(&sb, "%d %.6f %.6f %.6f %.6f\n", b.Category, (x1+x2)/2, (y1+y2)/2, x2-x1, y2-y1)
+			continue
+		}
+		polygon := b.Polygon
+		if polygon == nil {
+			polygon = keypoint.Polygon{{X: x1 * 100, Y: y1 * 100}, {X: x2 * 100, Y: y1 * 100}, {X: x2 * 100, Y: y2 * 100}, {X: x1 * 100, Y: y2 * 100}}
+		}
+		fmt.Fprintf(&sb, "%d", b.Category)
+		for _, v := range polygon {
+			fmt.Fprintf(&sb, " %.6f %.6f", clamp(v.X/100), clamp(v.Y/100))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// hasPolygons reports whether any annotation in the frames has a polygon.
+func hasPolygons(frames []exportFrame) bool {
+	for _, f := range frames {
+		for _, b := range f.Boxes {
+			if b.Polygon != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// yoloDataYAML returns the data.yaml file of a YOLO dataset, which lists the class names.
+func yoloDataYAML(species []Species) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("path: .\ntrain: images\nval: images\nnames:\n")
+	for i, s := range species {
+		// JSON strings are valid YAML.
+		name, err := json.Marshal(s.ScientificName)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&sb, "  %d: %s\n", i, name)
+	}
+	return sb.String(), nil
+}
+
+// ExportYOLO writes a zip file of a YOLO dataset to w, from the annotations matching the filter. Annotations are sampled
+// every interval milliseconds, and each species is a class. The zip contains a data.yaml file and a labels directory, with
+// a label file for each frame. Images of frames that have been extracted to media storage are included in the images directory.
+// If any annotation has polygons, the dataset is written in the YOLO segmentation format.
+func ExportYOLO(w io.Writer, filter AnnotationFilter, interval int64) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid interval %d, must be greater than zero", interval)
+	}
+
+	species, frames, err := collectExport(filter, interval)
+	if err != nil {
+		return err
+	}
+
+	segments := hasPolygons(frames)
+	zw := zip.NewWriter(w)
+
+	data, err := yoloDataYAML(species)
+	if err != nil {
+		return err
+	}
+	f, err := zw.Create("data.yaml")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, data); err != nil {
+		return err
+	}
+
+	for i := range frames {
+		frame := &frames[i]
+		name := yoloName(frame)
+
+		f, err := zw.Create("labels/" + name + ".txt")
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, yoloLabels(frame, segments)); err != nil {
+			return err
+		}
+
+		// Include the image if it has been extracted.
+		key := frame.MediaKey()
+		if !MediaExists(key) {
+			continue
+		}
+		f, err = zw.Create("images/" + name + "." + key.Type.FileExtension())
+		if err != nil {
+			return err
+		}
+		r, err := globals.GetStorage().Object(key.ToStorageName()).NewReader(context.Background())
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(f, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// StartYOLOExport starts a task that exports the annotations matching the filter as a YOLO dataset, sampled
+// every interval milliseconds. Once complete, the task's resource is the zip file of the dataset.
+func StartYOLOExport(filter AnnotationFilter, interval int64) (int64, error) {
+	if interval <= 0 {
+		return 0, fmt.Errorf("invalid interval %d, must be greater than zero", interval)
+	}
+
+	return startExport("yolo.zip", func(w io.Writer) error {
+		return ExportYOLO(w, filter, interval)
+	})
+}
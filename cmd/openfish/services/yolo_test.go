@@ -0,0 +1,164 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/services"
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/mediatype"
+	"github.com/ausocean/openfish/cmd/openfish/types/role"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+// readZip reads the files in a zip archive into a map of names to contents.
+func readZip(t *testing.T, data []byte) map[string]string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Could not read zip %s", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, _ := f.Open()
+		b, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(b)
+	}
+	return files
+}
+
+func TestExportYOLO(t *testing.T) {
+	setup()
+	a := createTestVerifiedAnnotation()
+
+	// Extract the image of the first frame.
+	services.CreateMedia(services.MediaKey{
+		Type:          mediatype.JPEG,
+		VideoStreamID: a.VideostreamID,
+		StartTime:     videotime.UncheckedParse("00:00:01.000"),
+	}, []byte("jpeg"))
+
+	var buf bytes.Buffer
+	err := services.ExportYOLO(&buf, services.AnnotationFilter{VideoStreamID: &a.VideostreamID}, 500)
+	if err != nil {
+		t.Fatalf("Could not export YOLO dataset %s", err)
+	}
+	files := readZip(t, buf.Bytes())
+
+	// Annotation is from 1s to 2s, so there should be 3 frames.
+	if len(files) != 5 {
+		t.Errorf("Expected data.yaml, 3 label files and 1 image, got %d files", len(files))
+	}
+
+	if !strings.Contains(files["data.yaml"], `0: "Sepioteuthis australis"`) {
+		t.Errorf("data.yaml does not contain expected class names, got %s", files["data.yaml"])
+	}
+
+	// First keypoint is x 10%-20%, y 70%-80%.
+	name := fmt.Sprintf("%d_00001000", a.VideostreamID)
+	expected := "0 0.150000 0.750000 0.100000 0.100000\n"
+	if files["labels/"+name+".txt"] != expected {
+		t.Errorf("Expected label %q, got %q", expected, files["labels/"+name+".txt"])
+	}
+
+	if files["images/"+name+".jpeg"] != "jpeg" {
+		t.Errorf("Expected image of first frame to be included")
+	}
+}
+
+func TestExportYOLOShortAnnotation(t *testing.T) {
+	setup()
+	a := createTestVerifiedAnnotation()
+
+	// Annotation is from 1s to 2s, so no multiple of the interval falls within it.
+	var buf bytes.Buffer
+	err := services.ExportYOLO(&buf, services.AnnotationFilter{VideoStreamID: &a.VideostreamID}, 5000)
+	if err != nil {
+		t.Fatalf("Could not export YOLO dataset %s", err)
+	}
+	files := readZip(t, buf.Bytes())
+
+	name := fmt.Sprintf("%d_00001000", a.VideostreamID)
+	if len(files) != 2 || files["labels/"+name+".txt"] == "" {
+		t.Errorf("Expected data.yaml and a label file for the first keypoint, got %d files", len(files))
+	}
+}
+
+func TestExportYOLOSegments(t *testing.T) {
+	setup()
+	a := createTestVerifiedAnnotation()
+	keypoints := []keypoint.KeyPoint{
+		{
+			Polygon: keypoint.Polygon{{X: 10, Y: 70}, {X: 20, Y: 70}, {X: 20, Y: 80}},
+			Time:    videotime.UncheckedParse("00:00:01.000"),
+		},
+	}
+	current, _ := services.GetAnnotationByID(a.ID)
+	err := services.UpdateAnnotation(a.ID, current.Version, a.CreatedByID, services.PartialAnnotationContents{KeyPoints: &keypoints})
+	if err != nil {
+		t.Fatalf("Could not update annotation %s", err)
+	}
+
+	// Changing the keypoints sends the annotation back for review.
+	services.VerifyAnnotation(a.ID, createTestUserWithRole("curator", role.Curator))
+
+	var buf bytes.Buffer
+	err = services.ExportYOLO(&buf, services.AnnotationFilter{VideoStreamID: &a.VideostreamID}, 500)
+	if err != nil {
+		t.Fatalf("Could not export YOLO dataset %s", err)
+	}
+	files := readZip(t, buf.Bytes())
+
+	name := fmt.Sprintf("%d_00001000", a.VideostreamID)
+	expected := "0 0.100000 0.700000 0.200000 0.700000 0.200000 0.800000\n"
+	if files["labels/"+name+".txt"] != expected {
+		t.Errorf("Expected label %q, got %q", expected, files["labels/"+name+".txt"])
+	}
+}
+
+func TestExportYOLOWithInvalidInterval(t *testing.T) {
+	setup()
+	a := createTestAnnotation()
+
+	err := services.ExportYOLO(io.Discard, services.AnnotationFilter{VideoStreamID: &a.VideostreamID}, 0)
+	if err == nil {
+		t.Errorf("Did not receive expected error when exporting with zero interval")
+	}
+}
@@ -31,23 +31,204 @@ LICENSE
   OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 */
 
+// Package keypoint provides the KeyPoint type, which is the position of something interesting in a video at a
+// point in time, and functions for interpolating between keypoints.
 package keypoint
 
 import (
+	"fmt"
+	"math"
+	"slices"
+
 	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
 )
 
+// MaxSamples is the maximum number of keypoints that can be sampled, e.g. an hour at 25 fps,
+// so that a high frame rate over a long annotation cannot exhaust memory.
+const MaxSamples = 90000
+
 // BoundingBox is a rectangle enclosing something interesting in a video.
 // It is represented using two x y coordinates, top left corner and bottom right corner of the rectangle.
 type BoundingBox struct {
-	X1 float32 `json:"x1" example:"10.00`
+	X1 float32 `json:"x1" example:"10.00"`
 	X2 float32 `json:"x2" example:"50.00"`
 	Y1 float32 `json:"y1" example:"25.00"`
 	Y2 float32 `json:"y2" example:"75.00"`
 }
 
-// KeyPoint is a bounding box and time within the video.
+// KeyPoint is a bounding box and time within the video. A keypoint can optionally have a polygon outlining
+// its shape, in which case the bounding box is derived from the polygon.
 type KeyPoint struct {
 	BoundingBox BoundingBox         `json:"box"`
+	Polygon     Polygon             `json:"polygon,omitempty" validate:"optional"`
 	Time        videotime.VideoTime `json:"time" swaggertype:"string" example:"01:56:05.500"`
 }
+
+// Normalise returns a copy of the keypoints with any closing vertex that repeats the first vertex of each
+// polygon removed, and the bounding box of each keypoint with a polygon set to the bounds of the polygon.
+func Normalise(keypoints []KeyPoint) []KeyPoint {
+	normalised := slices.Clone(keypoints)
+	for i := range normalised {
+		if normalised[i].Polygon != nil {
+			normalised[i].Polygon = normalised[i].Polygon.close()
+			normalised[i].BoundingBox = normalised[i].Polygon.Bounds()
+		}
+	}
+	return normalised
+}
+
+// Validate checks that there is at least one keypoint and that their times are strictly increasing.
+// If any keypoint has a polygon, then all keypoints must have valid polygons with the same number of
+// vertices, so that they can be interpolated.
+func Validate(keypoints []KeyPoint) error {
+	if len(keypoints) == 0 {
+		return fmt.Errorf("at least one keypoint is required")
+	}
+	for i := 1; i < len(keypoints); i++ {
+		if keypoints[i].Time.Int() <= keypoints[i-1].Time.Int() {
+			return fmt.Errorf("keypoint times must be increasing, %s is not after %s", keypoints[i].Time.String(), keypoints[i-1].Time.String())
+		}
+	}
+
+	if keypoints[0].Polygon == nil {
+		for _, k := range keypoints {
+			if k.Polygon != nil {
+				return fmt.Errorf("keypoint at %s has a polygon, either all or no keypoints must have polygons", k.Time.String())
+			}
+		}
+		return nil
+	}
+	for _, k := range keypoints {
+		if k.Polygon == nil {
+			return fmt.Errorf("keypoint at %s has no polygon, either all or no keypoints must have polygons", k.Time.String())
+		}
+		if len(k.Polygon) != len(keypoints[0].Polygon) {
+			return fmt.Errorf("polygon at %s has %d vertices, all polygons must have %d vertices", k.Time.String(), len(k.Polygon), len(keypoints[0].Polygon))
+		}
+		if err := k.Polygon.validate(); err != nil {
+			return fmt.Errorf("invalid polygon at %s: %w", k.Time.String(), err)
+		}
+	}
+	return nil
+}
+
+// IoU returns the intersection over union of two bounding boxes, from 0 if they do not overlap to 1 if they are identical.
+func IoU(a BoundingBox, b BoundingBox) float32 {
+	w := min(a.X2, b.X2) - max(a.X1, b.X1)
+	h := min(a.Y2, b.Y2) - max(a.Y1, b.Y1)
+	if w <= 0 || h <= 0 {
+		return 0
+	}
+	intersection := w * h
+	union := (a.X2-a.X1)*(a.Y2-a.Y1) + (b.X2-b.X1)*(b.Y2-b.Y1) - intersection
+	return intersection / union
+}
+
+// lerp linearly interpolates between two bounding boxes, where f is between 0 (box a) and 1 (box b).
+func lerp(a BoundingBox, b BoundingBox, f float32) BoundingBox {
+	return BoundingBox{
+		X1: a.X1 + (b.X1-a.X1)*f,
+		X2: a.X2 + (b.X2-a.X2)*f,
+		Y1: a.Y1 + (b.Y1-a.Y1)*f,
+		Y2: a.Y2 + (b.Y2-a.Y2)*f,
+	}
+}
+
+// Interpolate returns the keypoint at time t, linearly interpolating the bounding box (or polygon) between the
+// keypoints either side of t. The keypoints must be valid, and t must be within the time span
+// of the keypoints.
+func Interpolate(keypoints []KeyPoint, t videotime.VideoTime) (KeyPoint, error) {
+	if err := Validate(keypoints); err != nil {
+		return KeyPoint{}, err
+	}
+	first, last := keypoints[0].Time, keypoints[len(keypoints)-1].Time
+	if t.Int() < first.Int() || t.Int() > last.Int() {
+		return KeyPoint{}, fmt.Errorf("time %s is outside of keypoints %s-%s", t.String(), first.String(), last.String())
+	}
+
+	s := sampler{keypoints: keypoints}
+	return s.at(t.Int()), nil
+}
+
+// sampler interpolates keypoints at increasing times. It keeps its position in the keypoints, so that sampling
+// passes over them once. The keypoints must be valid, and times must be within their time span.
+type sampler struct {
+	keypoints []KeyPoint
+	i         int // Index of the first keypoint at or after the last time sampled.
+}
+
+// at returns the keypoint at time t, which must not be before the last time sampled.
+func (s *sampler) at(t int64) KeyPoint {
+	// Find the keypoints either side of t.
+	s.i = max(s.i, 1)
+	for s.i < len(s.keypoints) && s.keypoints[s.i].Time.Int() < t {
+		s.i++
+	}
+	if s.i == len(s.keypoints) || s.keypoints[s.i-1].Time.Int() == t {
+		a := s.keypoints[s.i-1]
+		return KeyPoint{BoundingBox: a.BoundingBox, Polygon: a.Polygon, Time: videotime.FromInt(t)}
+	}
+	a, b := s.keypoints[s.i-1], s.keypoints[s.i]
+	f := float32(t-a.Time.Int()) / float32(b.Time.Int()-a.Time.Int())
+
+	if a.Polygon != nil && len(a.Polygon) == len(b.Polygon) {
+		p := lerpPolygon(a.Polygon, b.Polygon, f)
+		return KeyPoint{BoundingBox: p.Bounds(), Polygon: p, Time: videotime.FromInt(t)}
+	}
+	return KeyPoint{BoundingBox: lerp(a.BoundingBox, b.BoundingBox, f), Time: videotime.FromInt(t)}
+}
+
+// Sample returns keypoints sampled at the given frame rate across the time span of the keypoints,
+// starting at the first keypoint. Frame times are rounded to the nearest millisecond, so the frame
+// rate cannot exceed 1000 fps, and at most MaxSamples keypoints are sampled.
+func Sample(keypoints []KeyPoint, fps float64) ([]KeyPoint, error) {
+	if err := Validate(keypoints); err != nil {
+		return nil, err
+	}
+	if !(fps > 0 && fps <= 1000) {
+		return nil, fmt.Errorf("invalid frame rate %f, must be greater than 0 and at most 1000", fps)
+	}
+
+	start, end := keypoints[0].Time.Int(), keypoints[len(keypoints)-1].Time.Int()
+	frames := int(math.Floor(float64(end-start)*fps/1000)) + 1
+	if frames > MaxSamples {
+		return nil, fmt.Errorf("too many samples %d, must be at most %d", frames, MaxSamples)
+	}
+	s := sampler{keypoints: keypoints}
+	samples := make([]KeyPoint, 0, frames)
+	for i := 0; i < frames; i++ {
+		t := start + int64(math.Round(float64(i)*1000/fps))
+		if t > end {
+			break
+		}
+		samples = append(samples, s.at(t))
+	}
+
+	return samples, nil
+}
+
+// SampleEvery returns the first keypoint followed by keypoints sampled at every multiple of interval milliseconds
+// after it within the time span of the keypoints. Sampling at multiples of interval, rather than from the first
+// keypoint, aligns samples across keypoints that start at different times. At most MaxSamples keypoints are sampled.
+func SampleEvery(keypoints []KeyPoint, interval int64) ([]KeyPoint, error) {
+	if err := Validate(keypoints); err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid interval %d, must be greater than 0", interval)
+	}
+
+	start, end := keypoints[0].Time.Int(), keypoints[len(keypoints)-1].Time.Int()
+	n := end/interval - start/interval + 1
+	if n > MaxSamples {
+		return nil, fmt.Errorf("too many samples %d, must be at most %d", n, MaxSamples)
+	}
+	s := sampler{keypoints: keypoints}
+	samples := make([]KeyPoint, 1, n)
+	samples[0] = s.at(start)
+	for t := (start/interval + 1) * interval; t <= end; t += interval {
+		samples = append(samples, s.at(t))
+	}
+
+	return samples, nil
+}
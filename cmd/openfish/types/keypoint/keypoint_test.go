@@ -0,0 +1,200 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package keypoint_test
+
+import (
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+var testKeyPoints = []keypoint.KeyPoint{
+	{
+		BoundingBox: keypoint.BoundingBox{X1: 0, Y1: 0, X2: 10, Y2: 10},
+		Time:        videotime.UncheckedParse("00:00:01.000"),
+	},
+	{
+		BoundingBox: keypoint.BoundingBox{X1: 10, Y1: 20, X2: 30, Y2: 40},
+		Time:        videotime.UncheckedParse("00:00:02.000"),
+	},
+}
+
+func TestValidate(t *testing.T) {
+	if err := keypoint.Validate(testKeyPoints); err != nil {
+		t.Errorf("Unexpected error validating keypoints: %v", err)
+	}
+
+	if err := keypoint.Validate([]keypoint.KeyPoint{}); err == nil {
+		t.Errorf("Expected error validating empty keypoints")
+	}
+
+	unordered := []keypoint.KeyPoint{testKeyPoints[1], testKeyPoints[0]}
+	if err := keypoint.Validate(unordered); err == nil {
+		t.Errorf("Expected error validating unordered keypoints")
+	}
+
+	duplicate := []keypoint.KeyPoint{testKeyPoints[0], testKeyPoints[0]}
+	if err := keypoint.Validate(duplicate); err == nil {
+		t.Errorf("Expected error validating keypoints with duplicate times")
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	kp, err := keypoint.Interpolate(testKeyPoints, videotime.UncheckedParse("00:00:01.500"))
+	if err != nil {
+		t.Errorf("Unexpected error interpolating keypoints: %v", err)
+	}
+
+	expected := keypoint.BoundingBox{X1: 5, Y1: 10, X2: 20, Y2: 25}
+	if kp.BoundingBox != expected {
+		t.Errorf("Expected bounding box %v, but got %v", expected, kp.BoundingBox)
+	}
+
+	kp, err = keypoint.Interpolate(testKeyPoints, videotime.UncheckedParse("00:00:02.000"))
+	if err != nil {
+		t.Errorf("Unexpected error interpolating keypoints: %v", err)
+	}
+	if kp.BoundingBox != testKeyPoints[1].BoundingBox {
+		t.Errorf("Expected bounding box %v, but got %v", testKeyPoints[1].BoundingBox, kp.BoundingBox)
+	}
+}
+
+func TestInterpolateOutsideSpan(t *testing.T) {
+	_, err := keypoint.Interpolate(testKeyPoints, videotime.UncheckedParse("00:00:00.999"))
+	if err == nil {
+		t.Errorf("Expected error interpolating before first keypoint")
+	}
+
+	_, err = keypoint.Interpolate(testKeyPoints, videotime.UncheckedParse("00:00:02.001"))
+	if err == nil {
+		t.Errorf("Expected error interpolating after last keypoint")
+	}
+}
+
+func TestSample(t *testing.T) {
+	samples, err := keypoint.Sample(testKeyPoints, 4)
+	if err != nil {
+		t.Errorf("Unexpected error sampling keypoints: %v", err)
+	}
+
+	expected := []string{"00:00:01.000", "00:00:01.250", "00:00:01.500", "00:00:01.750", "00:00:02.000"}
+	if len(samples) != len(expected) {
+		t.Fatalf("Expected %d samples, but got %d", len(expected), len(samples))
+	}
+	for i, s := range samples {
+		if s.Time.String() != expected[i] {
+			t.Errorf("Expected sample %d at %s, but got %s", i, expected[i], s.Time.String())
+		}
+	}
+
+	if _, err := keypoint.Sample(testKeyPoints, 0); err == nil {
+		t.Errorf("Expected error sampling with zero frame rate")
+	}
+
+	long := []keypoint.KeyPoint{testKeyPoints[0], {BoundingBox: testKeyPoints[1].BoundingBox, Time: videotime.UncheckedParse("10:00:00.000")}}
+	if _, err := keypoint.Sample(long, 1000); err == nil {
+		t.Errorf("Expected error sampling more than %d keypoints", keypoint.MaxSamples)
+	}
+}
+
+func TestSampleMatchesInterpolate(t *testing.T) {
+	keypoints := []keypoint.KeyPoint{
+		testKeyPoints[0],
+		testKeyPoints[1],
+		{
+			BoundingBox: keypoint.BoundingBox{X1: 50, Y1: 10, X2: 60, Y2: 30},
+			Time:        videotime.UncheckedParse("00:00:04.000"),
+		},
+	}
+	samples, err := keypoint.Sample(keypoints, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error sampling keypoints: %v", err)
+	}
+	if len(samples) != 10 {
+		t.Fatalf("Expected 10 samples, but got %d", len(samples))
+	}
+	for _, s := range samples {
+		expected, _ := keypoint.Interpolate(keypoints, s.Time)
+		if s.BoundingBox != expected.BoundingBox {
+			t.Errorf("Expected sample at %s to be %v, but got %v", s.Time.String(), expected.BoundingBox, s.BoundingBox)
+		}
+	}
+}
+
+func TestSampleEvery(t *testing.T) {
+	keypoints := []keypoint.KeyPoint{
+		{BoundingBox: testKeyPoints[0].BoundingBox, Time: videotime.UncheckedParse("00:00:01.100")},
+		testKeyPoints[1],
+	}
+	samples, err := keypoint.SampleEvery(keypoints, 300)
+	if err != nil {
+		t.Fatalf("Unexpected error sampling keypoints: %v", err)
+	}
+
+	// The first keypoint is followed by multiples of the interval.
+	expected := []string{"00:00:01.100", "00:00:01.200", "00:00:01.500", "00:00:01.800"}
+	if len(samples) != len(expected) {
+		t.Fatalf("Expected %d samples, but got %d", len(expected), len(samples))
+	}
+	for i, s := range samples {
+		if s.Time.String() != expected[i] {
+			t.Errorf("Expected sample %d at %s, but got %s", i, expected[i], s.Time.String())
+		}
+	}
+
+	if _, err := keypoint.SampleEvery(keypoints, 0); err == nil {
+		t.Errorf("Expected error sampling with zero interval")
+	}
+
+	long := []keypoint.KeyPoint{testKeyPoints[0], {BoundingBox: testKeyPoints[1].BoundingBox, Time: videotime.UncheckedParse("10:00:00.000")}}
+	if _, err := keypoint.SampleEvery(long, 1); err == nil {
+		t.Errorf("Expected error sampling more than %d keypoints", keypoint.MaxSamples)
+	}
+}
+
+func TestIoU(t *testing.T) {
+	a := keypoint.BoundingBox{X1: 0, Y1: 0, X2: 10, Y2: 10}
+	b := keypoint.BoundingBox{X1: 5, Y1: 0, X2: 15, Y2: 10}
+	if iou := keypoint.IoU(a, b); iou != float32(50)/150 {
+		t.Errorf("Expected IoU of 1/3, but got %f", iou)
+	}
+	if iou := keypoint.IoU(a, a); iou != 1 {
+		t.Errorf("Expected IoU of identical boxes to be 1, but got %f", iou)
+	}
+	c := keypoint.BoundingBox{X1: 10, Y1: 10, X2: 20, Y2: 20}
+	if iou := keypoint.IoU(a, c); iou != 0 {
+		t.Errorf("Expected IoU of boxes that only touch to be 0, but got %f", iou)
+	}
+}
@@ -0,0 +1,165 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package keypoint
+
+import (
+	"fmt"
+	"math"
+)
+
+// Point is a vertex of a polygon, as percentages of the width and height of the video.
+type Point struct {
+	X float32 `json:"x" example:"10.00"`
+	Y float32 `json:"y" example:"25.00"`
+}
+
+// Polygon is an outline of something interesting in a video, such as kelp or the body of a fish.
+// Polygons are implicitly closed, the last vertex connects to the first.
+type Polygon []Point
+
+// Bounds returns the smallest bounding box enclosing the polygon.
+func (p Polygon) Bounds() BoundingBox {
+	if len(p) == 0 {
+		return BoundingBox{}
+	}
+	box := BoundingBox{X1: p[0].X, X2: p[0].X, Y1: p[0].Y, Y2: p[0].Y}
+	for _, v := range p[1:] {
+		box.X1, box.X2 = min(box.X1, v.X), max(box.X2, v.X)
+		box.Y1, box.Y2 = min(box.Y1, v.Y), max(box.Y2, v.Y)
+	}
+	return box
+}
+
+// Area returns the area of the polygon, using the shoelace formula.
+func (p Polygon) Area() float32 {
+	var sum float64
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		sum += float64(a.X)*float64(b.Y) - float64(b.X)*float64(a.Y)
+	}
+	return float32(math.Abs(sum) / 2)
+}
+
+// close returns the polygon without a closing vertex that repeats the first vertex.
+func (p Polygon) close() Polygon {
+	if len(p) > 1 && p[0] == p[len(p)-1] {
+		return p[:len(p)-1]
+	}
+	return p
+}
+
+// validate checks that the polygon has at least three vertices, is within the frame, and does not intersect itself.
+func (p Polygon) validate() error {
+	if len(p) < 3 {
+		return fmt.Errorf("polygon must have at least 3 vertices, got %d", len(p))
+	}
+	for _, v := range p {
+		if v.X < 0 || v.X > 100 || v.Y < 0 || v.Y > 100 {
+			return fmt.Errorf("polygon vertex (%g, %g) is outside of the frame", v.X, v.Y)
+		}
+	}
+	if p.Area() == 0 {
+		return fmt.Errorf("polygon has no area")
+	}
+
+	// Check every pair of edges. Adjacent edges share a vertex, so they only intersect if they
+	// double back over each other.
+	n := len(p)
+	for i := 0; i < n; i++ {
+		a, b := p[i], p[(i+1)%n]
+		if a == b {
+			return fmt.Errorf("polygon has repeated vertex (%g, %g)", a.X, a.Y)
+		}
+		for j := i + 1; j < n; j++ {
+			c, d := p[j], p[(j+1)%n]
+			switch {
+			case j == i+1:
+				if orientation(a, b, d) == 0 && onSegment(a, b, d) || orientation(b, d, a) == 0 && onSegment(b, d, a) {
+					return fmt.Errorf("polygon intersects itself")
+				}
+			case i == 0 && j == n-1:
+				if orientation(c, a, b) == 0 && onSegment(c, a, b) || orientation(a, b, c) == 0 && onSegment(a, b, c) {
+					return fmt.Errorf("polygon intersects itself")
+				}
+			default:
+				if intersects(a, b, c, d) {
+					return fmt.Errorf("polygon intersects itself")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// orientation returns the orientation of the triangle a, b, c: positive if counter-clockwise,
+// negative if clockwise and zero if the points are collinear.
+func orientation(a, b, c Point) int {
+	v := (float64(b.X)-float64(a.X))*(float64(c.Y)-float64(a.Y)) - (float64(b.Y)-float64(a.Y))*(float64(c.X)-float64(a.X))
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// onSegment reports whether c, which is collinear with a and b, lies on the segment from a to b.
+func onSegment(a, b, c Point) bool {
+	return min(a.X, b.X) <= c.X && c.X <= max(a.X, b.X) && min(a.Y, b.Y) <= c.Y && c.Y <= max(a.Y, b.Y)
+}
+
+// intersects reports whether the segment from a to b intersects or touches the segment from c to d.
+func intersects(a, b, c, d Point) bool {
+	o1, o2 := orientation(a, b, c), orientation(a, b, d)
+	o3, o4 := orientation(c, d, a), orientation(c, d, b)
+	if o1 != o2 && o3 != o4 {
+		return true
+	}
+	return o1 == 0 && onSegment(a, b, c) ||
+		o2 == 0 && onSegment(a, b, d) ||
+		o3 == 0 && onSegment(c, d, a) ||
+		o4 == 0 && onSegment(c, d, b)
+}
+
+// lerpPolygon linearly interpolates between the vertices of two polygons with the same number of vertices,
+// where f is between 0 (polygon a) and 1 (polygon b).
+func lerpPolygon(a Polygon, b Polygon, f float32) Polygon {
+	p := make(Polygon, len(a))
+	for i := range a {
+		p[i] = Point{X: a[i].X + (b[i].X-a[i].X)*f, Y: a[i].Y + (b[i].Y-a[i].Y)*f}
+	}
+	return p
+}
@@ -0,0 +1,125 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package keypoint_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/types/keypoint"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+var testPolygonKeyPoints = []keypoint.KeyPoint{
+	{
+		Polygon: keypoint.Polygon{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}},
+		Time:    videotime.UncheckedParse("00:00:01.000"),
+	},
+	{
+		Polygon: keypoint.Polygon{{X: 10, Y: 20}, {X: 30, Y: 20}, {X: 30, Y: 40}, {X: 10, Y: 40}},
+		Time:    videotime.UncheckedParse("00:00:02.000"),
+	},
+}
+
+func TestNormalise(t *testing.T) {
+	closed := []keypoint.KeyPoint{{
+		Polygon: keypoint.Polygon{{X: 5, Y: 5}, {X: 15, Y: 5}, {X: 10, Y: 20}, {X: 5, Y: 5}},
+		Time:    videotime.UncheckedParse("00:00:01.000"),
+	}}
+	normalised := keypoint.Normalise(closed)
+
+	if len(normalised[0].Polygon) != 3 {
+		t.Errorf("Expected closing vertex to be removed, got %v", normalised[0].Polygon)
+	}
+	expected := keypoint.BoundingBox{X1: 5, Y1: 5, X2: 15, Y2: 20}
+	if normalised[0].BoundingBox != expected {
+		t.Errorf("Expected bounding box %v, but got %v", expected, normalised[0].BoundingBox)
+	}
+	if len(closed[0].Polygon) != 4 {
+		t.Errorf("Expected original keypoints to be unchanged")
+	}
+}
+
+func TestValidatePolygons(t *testing.T) {
+	if err := keypoint.Validate(testPolygonKeyPoints); err != nil {
+		t.Errorf("Unexpected error validating polygons: %v", err)
+	}
+
+	invalid := map[string]keypoint.Polygon{
+		"too few vertices":  {{X: 0, Y: 0}, {X: 10, Y: 0}},
+		"outside frame":     {{X: 0, Y: 0}, {X: 110, Y: 0}, {X: 10, Y: 10}},
+		"self-intersecting": {{X: 0, Y: 0}, {X: 10, Y: 10}, {X: 10, Y: 0}, {X: 0, Y: 10}},
+		"no area":           {{X: 0, Y: 0}, {X: 5, Y: 5}, {X: 10, Y: 10}},
+		"doubles back":      {{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 5, Y: 0}, {X: 5, Y: 10}},
+	}
+	for name, p := range invalid {
+		kp := []keypoint.KeyPoint{{Polygon: p, Time: videotime.UncheckedParse("00:00:01.000")}}
+		if err := keypoint.Validate(kp); err == nil {
+			t.Errorf("Expected error validating polygon that is %s", name)
+		}
+	}
+
+	mixed := []keypoint.KeyPoint{testPolygonKeyPoints[0], testKeyPoints[1]}
+	if err := keypoint.Validate(mixed); err == nil {
+		t.Errorf("Expected error validating keypoints with and without polygons")
+	}
+
+	mismatched := slices.Clone(testPolygonKeyPoints)
+	mismatched[1].Polygon = keypoint.Polygon{{X: 10, Y: 20}, {X: 30, Y: 20}, {X: 30, Y: 40}}
+	if err := keypoint.Validate(mismatched); err == nil {
+		t.Errorf("Expected error validating polygons with different vertex counts")
+	}
+}
+
+func TestInterpolatePolygon(t *testing.T) {
+	kp, err := keypoint.Interpolate(keypoint.Normalise(testPolygonKeyPoints), videotime.UncheckedParse("00:00:01.500"))
+	if err != nil {
+		t.Fatalf("Unexpected error interpolating keypoints: %v", err)
+	}
+
+	expected := keypoint.Polygon{{X: 5, Y: 10}, {X: 20, Y: 10}, {X: 20, Y: 25}, {X: 5, Y: 25}}
+	if !slices.Equal(kp.Polygon, expected) {
+		t.Errorf("Expected polygon %v, but got %v", expected, kp.Polygon)
+	}
+	box := keypoint.BoundingBox{X1: 5, Y1: 10, X2: 20, Y2: 25}
+	if kp.BoundingBox != box {
+		t.Errorf("Expected bounding box %v, but got %v", box, kp.BoundingBox)
+	}
+}
+
+func TestPolygonArea(t *testing.T) {
+	if area := testPolygonKeyPoints[1].Polygon.Area(); area != 400 {
+		t.Errorf("Expected area 400, but got %f", area)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package latlong
+
+import (
+	"math"
+	"time"
+)
+
+// Constants for the sunrise equation, see https://en.wikipedia.org/wiki/Sunrise_equation.
+const (
+	j2000     = 2451545.0 // Julian date of the J2000 epoch.
+	unixEpoch = 2440587.5 // Julian date of the Unix epoch.
+	obliquity = 23.4397   // Axial tilt of the Earth in degrees.
+	horizon   = -0.833    // Altitude of the sun at sunrise and sunset in degrees, accounting for refraction and the sun's radius.
+)
+
+// julian converts a time to a Julian date.
+func julian(t time.Time) float64 {
+	return float64(t.UnixMilli())/86400000 + unixEpoch
+}
+
+// fromJulian converts a Julian date to a time in UTC.
+func fromJulian(j float64) time.Time {
+	return time.UnixMilli(int64(math.Round((j - unixEpoch) * 86400000))).UTC()
+}
+
+func sin(deg float64) float64 { return math.Sin(deg * math.Pi / 180) }
+func cos(deg float64) float64 { return math.Cos(deg * math.Pi / 180) }
+
+// sunrise computes the Julian dates of solar noon and the hour angle of sunrise and sunset in degrees,
+// for the solar day closest to t. cosHourAngle is less than -1 if the sun does not set that day, and
+// greater than 1 if the sun does not rise.
+func (l LatLong) sunrise(t time.Time) (noon float64, hourAngle float64, cosHourAngle float64) {
+	// Mean solar time of the closest solar noon.
+	n := math.Round(julian(t) - j2000 - 0.0009 + l.Lng/360)
+	mean := j2000 + 0.0009 + n - l.Lng/360
+
+	// Position of the sun.
+	anomaly := math.Mod(357.5291+0.98560028*(mean-j2000), 360)
+	center := 1.9148*sin(anomaly) + 0.02*sin(2*anomaly) + 0.0003*sin(3*anomaly)
+	longitude := math.Mod(anomaly+center+180+102.9372, 360)
+	noon = mean + 0.0053*sin(anomaly) - 0.0069*sin(2*longitude)
+	declination := math.Asin(sin(longitude)*sin(obliquity)) * 180 / math.Pi
+
+	cosHourAngle = (sin(horizon) - sin(l.Lat)*sin(declination)) / (cos(l.Lat) * cos(declination))
+	hourAngle = math.Acos(max(-1, min(1, cosHourAngle))) * 180 / math.Pi
+	return noon, hourAngle, cosHourAngle
+}
+
+// SunriseSunset returns the times of sunrise and sunset at this location, in UTC, on the solar day
+// closest to t. ok is false if the sun does not rise or set that day, because of polar day or night.
+func (l LatLong) SunriseSunset(t time.Time) (rise time.Time, set time.Time, ok bool) {
+	noon, hourAngle, cosHourAngle := l.sunrise(t)
+	if cosHourAngle < -1 || cosHourAngle > 1 {
+		return time.Time{}, time.Time{}, false
+	}
+	return fromJulian(noon - hourAngle/360), fromJulian(noon + hourAngle/360), true
+}
+
+// IsDaytime reports whether the sun is above the horizon at this location at time t.
+func (l LatLong) IsDaytime(t time.Time) bool {
+	noon, hourAngle, cosHourAngle := l.sunrise(t)
+	switch {
+	case cosHourAngle < -1:
+		return true // Polar day.
+	case cosHourAngle > 1:
+		return false // Polar night.
+	}
+	j := julian(t)
+	return j >= noon-hourAngle/360 && j < noon+hourAngle/360
+}
@@ -0,0 +1,111 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package latlong
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSunriseSunset(t *testing.T) {
+	melbourne, _ := time.LoadLocation("Australia/Melbourne")
+	london, _ := time.LoadLocation("Europe/London")
+
+	tests := []struct {
+		location LatLong
+		date     time.Time
+		rise     time.Time
+		set      time.Time
+	}{
+		{
+			location: UncheckedParse("-37.8136,144.9631"),
+			date:     time.Date(2024, 1, 1, 12, 0, 0, 0, melbourne),
+			rise:     time.Date(2024, 1, 1, 5, 59, 0, 0, melbourne),
+			set:      time.Date(2024, 1, 1, 20, 45, 0, 0, melbourne),
+		},
+		{
+			location: UncheckedParse("-37.8136,144.9631"),
+			date:     time.Date(2024, 6, 21, 12, 0, 0, 0, melbourne),
+			rise:     time.Date(2024, 6, 21, 7, 35, 0, 0, melbourne),
+			set:      time.Date(2024, 6, 21, 17, 8, 0, 0, melbourne),
+		},
+		{
+			location: UncheckedParse("51.5074,-0.1278"),
+			date:     time.Date(2024, 6, 21, 12, 0, 0, 0, london),
+			rise:     time.Date(2024, 6, 21, 4, 43, 0, 0, london),
+			set:      time.Date(2024, 6, 21, 21, 21, 0, 0, london),
+		},
+	}
+
+	// The sunrise equation is accurate to within a few minutes.
+	for i, test := range tests {
+		rise, set, ok := test.location.SunriseSunset(test.date)
+		if !ok {
+			t.Errorf("Test %d: expected the sun to rise and set", i)
+			continue
+		}
+		if d := rise.Sub(test.rise).Abs(); d > 5*time.Minute {
+			t.Errorf("Test %d: expected sunrise at %s, got %s", i, test.rise, rise.In(test.rise.Location()))
+		}
+		if d := set.Sub(test.set).Abs(); d > 5*time.Minute {
+			t.Errorf("Test %d: expected sunset at %s, got %s", i, test.set, set.In(test.set.Location()))
+		}
+	}
+}
+
+func TestIsDaytime(t *testing.T) {
+	melbourne := UncheckedParse("-37.8136,144.9631")
+	tz, _ := time.LoadLocation("Australia/Melbourne")
+	if !melbourne.IsDaytime(time.Date(2024, 1, 1, 12, 0, 0, 0, tz)) {
+		t.Errorf("Expected midday to be daytime")
+	}
+	if melbourne.IsDaytime(time.Date(2024, 1, 1, 23, 0, 0, 0, tz)) {
+		t.Errorf("Expected 11pm to be night time")
+	}
+	if melbourne.IsDaytime(time.Date(2024, 1, 1, 4, 0, 0, 0, tz)) {
+		t.Errorf("Expected 4am to be night time")
+	}
+
+	// Polar day and night.
+	tromso := UncheckedParse("69.6492,18.9553")
+	if !tromso.IsDaytime(time.Date(2024, 6, 21, 23, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected midnight sun in summer")
+	}
+	if _, _, ok := tromso.SunriseSunset(time.Date(2024, 12, 21, 11, 0, 0, 0, time.UTC)); ok {
+		t.Errorf("Expected no sunrise in polar night")
+	}
+	if tromso.IsDaytime(time.Date(2024, 12, 21, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected polar night in winter")
+	}
+}
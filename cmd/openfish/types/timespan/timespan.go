@@ -68,7 +68,7 @@ func Parse(s string) (*TimeSpan, error) {
 	if err != nil {
 		return nil, err
 	}
-	end, err := videotime.Parse(str[0])
+	end, err := videotime.Parse(str[1])
 	if err != nil {
 		return nil, err
 	}
@@ -78,9 +78,12 @@ func Parse(s string) (*TimeSpan, error) {
 
 // UnmarshalText is used for decoding query params or JSON into a TimeSpan.
 func (t *TimeSpan) UnmarshalText(text []byte) error {
-	var err error
-	t, err = Parse(string(text))
-	return err
+	ts, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*t = *ts
+	return nil
 }
 
 // MarshalText is used for encoding a TimeSpan into JSON or query params.
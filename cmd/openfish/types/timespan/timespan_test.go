@@ -0,0 +1,86 @@
+/*
+AUTHORS
+  Scott Barnard <scott@ausocean.org>
+
+LICENSE
+  Copyright (c) 2026, The OpenFish Contributors.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions are met:
+
+  1. Redistributions of source code must retain the above copyright notice, this
+     list of conditions and the following disclaimer.
+
+  2. Redistributions in binary form must reproduce the above copyright notice,
+     this list of conditions and the following disclaimer in the documentation
+     and/or other materials provided with the distribution.
+
+  3. Neither the name of The Australian Ocean Lab Ltd. ("AusOcean")
+     nor the names of its contributors may be used to endorse or promote
+     products derived from this software without specific prior written permission.
+
+  THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+  AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+  IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+  DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+  FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+  DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+  SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+  CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+  OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+  OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package timespan_test
+
+import (
+	"testing"
+
+	"github.com/ausocean/openfish/cmd/openfish/types/timespan"
+	"github.com/ausocean/openfish/cmd/openfish/types/videotime"
+)
+
+func TestParse(t *testing.T) {
+	str := "00:00:01.000-00:00:02.500"
+	expected := timespan.TimeSpan{
+		Start: videotime.UncheckedParse("00:00:01.000"),
+		End:   videotime.UncheckedParse("00:00:02.500"),
+	}
+
+	ts, err := timespan.Parse(str)
+	if err != nil {
+		t.Errorf("Error parsing timespan: %v", err)
+	}
+
+	if *ts != expected {
+		t.Errorf("Expected value %s, but got %s", expected.String(), ts.String())
+	}
+}
+
+func TestUnmarshalText(t *testing.T) {
+	str := "00:00:01.000-00:00:02.500"
+	expected := timespan.TimeSpan{
+		Start: videotime.UncheckedParse("00:00:01.000"),
+		End:   videotime.UncheckedParse("00:00:02.500"),
+	}
+
+	ts := timespan.TimeSpan{}
+
+	err := ts.UnmarshalText([]byte(str))
+	if err != nil {
+		t.Errorf("Error unmarshalling text: %v", err)
+	}
+
+	if ts != expected {
+		t.Errorf("Expected value %s, but got %s", expected.String(), ts.String())
+	}
+}
+
+func TestUnmarshalTextInvalid(t *testing.T) {
+	ts := timespan.TimeSpan{}
+
+	err := ts.UnmarshalText([]byte("00:00:01.000"))
+	if err == nil {
+		t.Errorf("Expected error unmarshalling timespan without an end time")
+	}
+}
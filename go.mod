@@ -10,6 +10,7 @@ require (
 	cloud.google.com/go/storage v1.56.0
 	github.com/ausocean/cloud v0.1.0
 	github.com/gofiber/fiber/v2 v2.52.10
+	golang.org/x/image v0.32.0
 	google.golang.org/api v0.247.0
 )
 
@@ -41,6 +41,7 @@ package jobrunner
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"os/exec"
 
@@ -61,6 +62,8 @@ type CloudRunJobRunner struct {
 
 // DockerJobRunner implements JobRunner for containers executed using docker on your local machine.
 type DockerJobRunner struct {
+	volumes []string // Volumes mounted in each container, as host-path:container-path.
+	args    []string // Arguments passed to each job before its own arguments.
 }
 
 // NewCloudRunJobRunner creates a new CloudRunJobRunner.
@@ -71,8 +74,10 @@ func NewCloudRunJobRunner(project string, location string) JobRunner {
 }
 
 // NewDockerJobRunner creates a new DockerJobRunner.
-func NewDockerJobRunner() JobRunner {
-	return &DockerJobRunner{}
+// volumes are mounted in each container, and args are passed to each job,
+// e.g. to share a local datastore with the jobs.
+func NewDockerJobRunner(volumes []string, args ...string) JobRunner {
+	return &DockerJobRunner{volumes, args}
 }
 
 // Run executes the given job in the background. Run is non-blocking,
@@ -106,21 +111,39 @@ func (r *CloudRunJobRunner) Run(name string, args ...string) error {
 // as using the datastore and polling.
 func (r *DockerJobRunner) Run(name string, args ...string) error {
 
-	googleAppCredentials := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	// Containers are removed once they exit, so that each job does not leave one behind.
+	dockerArgs := []string{"run", "--rm"}
 
-	dockerArgs := []string{
-		"run",
-		"-e",
-		"GOOGLE_APPLICATION_CREDENTIALS=/tmp/gcloud.json",
-		"-v",
-		fmt.Sprintf("%s:/tmp/gcloud.json:z", googleAppCredentials),
-		name,
+	// Credentials are only needed to access Google Cloud, so they are not required when running locally.
+	googleAppCredentials := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if googleAppCredentials != "" {
+		dockerArgs = append(dockerArgs,
+			"-e",
+			"GOOGLE_APPLICATION_CREDENTIALS=/tmp/gcloud.json",
+			"-v",
+			fmt.Sprintf("%s:/tmp/gcloud.json:z", googleAppCredentials),
+		)
+	}
+	for _, v := range r.volumes {
+		dockerArgs = append(dockerArgs, "-v", fmt.Sprintf("%s:z", v))
 	}
+
+	dockerArgs = append(dockerArgs, name)
+	dockerArgs = append(dockerArgs, r.args...)
 	dockerArgs = append(dockerArgs, args...)
 
-	fmt.Println(dockerArgs)
+	// Return errors launching the container, e.g. if docker is not installed, then wait for it in the background.
 	cmd := exec.Command("docker", dockerArgs...)
-	go cmd.Run()
+	err := cmd.Start()
+	if err != nil {
+		return fmt.Errorf("could not start docker: %w", err)
+	}
+	go func() {
+		err := cmd.Wait()
+		if err != nil {
+			log.Printf("%s job failed: %v", name, err)
+		}
+	}()
 
 	return nil
 }
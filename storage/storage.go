@@ -40,27 +40,43 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path"
+	"time"
 
 	"cloud.google.com/go/storage"
 )
 
+// ErrObjectNotExist is returned when getting the attributes of an object that does not exist.
+var ErrObjectNotExist = errors.New("object does not exist")
+
 // Storage defines the storage interface. It lets us store large binary data in a bucket or file.
 type Storage interface {
 	Object(name string) ObjectHandle
 }
 
 // ObjectHandle defines the object handle interface. It provides a ReadCloser and WriteCloser for reading
-// and writing to the object.
+// and writing to the object, and a ReadCloser for reading part of the object.
 type ObjectHandle interface {
 	NewWriter(ctx context.Context) (io.WriteCloser, error)
 	NewReader(ctx context.Context) (io.ReadCloser, error)
+	NewRangeReader(ctx context.Context, offset int64, length int64) (io.ReadCloser, error)
+	Attrs(ctx context.Context) (*ObjectAttrs, error)
 	Delete(ctx context.Context) error
 	Exists(ctx context.Context) (bool, error)
 }
 
+// ObjectAttrs are the attributes of an object.
+type ObjectAttrs struct {
+	Size    int64     // Size in bytes.
+	ETag    string    // Changes whenever the object is written to.
+	Updated time.Time // When the object was last written to.
+}
+
 // CloudStorage implements Storage using Google Cloud Buckets.
 type CloudStorage struct {
 	bkt *storage.BucketHandle
@@ -101,6 +117,24 @@ func (h *CloudObjectHandle) NewReader(ctx context.Context) (io.ReadCloser, error
 	return h.objHandle.NewReader(ctx)
 }
 
+// NewRangeReader returns a ReadCloser that reads length bytes from the storage object, starting at offset.
+// If length is negative, it reads to the end of the object.
+func (h *CloudObjectHandle) NewRangeReader(ctx context.Context, offset int64, length int64) (io.ReadCloser, error) {
+	return h.objHandle.NewRangeReader(ctx, offset, length)
+}
+
+// Attrs returns the attributes of the storage object, or ErrObjectNotExist if it does not exist.
+func (h *CloudObjectHandle) Attrs(ctx context.Context) (*ObjectAttrs, error) {
+	attrs, err := h.objHandle.Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, ErrObjectNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectAttrs{Size: attrs.Size, ETag: attrs.Etag, Updated: attrs.Updated}, nil
+}
+
 func (h *CloudObjectHandle) Delete(ctx context.Context) error {
 	return h.objHandle.Delete(ctx)
 }
@@ -140,8 +174,12 @@ func (s *FileStorage) Object(name string) ObjectHandle {
 	}
 }
 
-// NewWriter returns a WriteCloser that writes to the storage object.
+// NewWriter returns a WriteCloser that writes to the storage object, creating its directory if needed.
 func (h *FileObjectHandle) NewWriter(ctx context.Context) (io.WriteCloser, error) {
+	err := os.MkdirAll(path.Dir(h.filepath), os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
 	return os.Create(h.filepath)
 }
 
@@ -150,6 +188,47 @@ func (h *FileObjectHandle) NewReader(ctx context.Context) (io.ReadCloser, error)
 	return os.Open(h.filepath)
 }
 
+// NewRangeReader returns a ReadCloser that reads length bytes from the storage object, starting at offset.
+// If length is negative, it reads to the end of the object.
+func (h *FileObjectHandle) NewRangeReader(ctx context.Context, offset int64, length int64) (io.ReadCloser, error) {
+	f, err := os.Open(h.filepath)
+	if err != nil {
+		return nil, err
+	}
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if length < 0 {
+		return f, nil
+	}
+	return &limitedReadCloser{Reader: io.LimitReader(f, length), Closer: f}, nil
+}
+
+// limitedReadCloser reads from a limited reader, and closes the underlying file.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Attrs returns the attributes of the storage object, or ErrObjectNotExist if it does not exist.
+// The ETag is derived from the file's modification time and size.
+func (h *FileObjectHandle) Attrs(ctx context.Context) (*ObjectAttrs, error) {
+	info, err := os.Stat(h.filepath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrObjectNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectAttrs{
+		Size:    info.Size(),
+		ETag:    fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()),
+		Updated: info.ModTime(),
+	}, nil
+}
+
 func (h *FileObjectHandle) Delete(ctx context.Context) error {
 	return os.Remove(h.filepath)
 }
@@ -159,7 +238,7 @@ func (h *FileObjectHandle) Exists(ctx context.Context) (bool, error) {
 	if err == nil {
 		return true, nil
 	}
-	if err == os.ErrNotExist {
+	if errors.Is(err, fs.ErrNotExist) {
 		return false, nil
 	}
 	return false, err
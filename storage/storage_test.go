@@ -82,6 +82,32 @@ func TestWriteObject(t *testing.T) {
 	}
 }
 
+// TestWriteObjectInDirectory verifies that writing an object creates its directory.
+func TestWriteObjectInDirectory(t *testing.T) {
+
+	os.MkdirAll(baseDir, os.ModePerm)
+	defer os.RemoveAll(baseDir)
+
+	storage := NewFileStorage(baseDir)
+	handle := storage.Object("my-directory/my-object")
+
+	writer, err := handle.NewWriter(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		t.Fatalf("expected no error while closing writer, got %v", err)
+	}
+
+	// Verify the file has been created
+	_, err = os.Stat(path.Join(baseDir, "my-directory", "my-object"))
+	if err != nil {
+		t.Fatalf("expected file to be created, but got error: %v", err)
+	}
+}
+
 // TestReadObject verifies that we can read data from a file.
 func TestReadObject(t *testing.T) {
 
@@ -116,3 +142,77 @@ func TestReadObject(t *testing.T) {
 		t.Fatalf("expected content %v, got %v", expectedContent, string(content))
 	}
 }
+
+// TestReadObjectRange verifies that we can read part of a file.
+func TestReadObjectRange(t *testing.T) {
+
+	os.MkdirAll(baseDir, 0755)
+	defer os.RemoveAll(baseDir)
+
+	storage := NewFileStorage(baseDir)
+	handle := storage.Object("my-object")
+
+	err := os.WriteFile(path.Join(baseDir, "my-object"), []byte("test data"), 0644)
+	if err != nil {
+		t.Fatalf("expected no error while writing file, got %v", err)
+	}
+
+	tests := []struct {
+		offset   int64
+		length   int64
+		expected string
+	}{
+		{0, 4, "test"},
+		{5, 2, "da"},
+		{5, -1, "data"},
+		{5, 100, "data"},
+	}
+	for _, test := range tests {
+		reader, err := handle.NewRangeReader(context.Background(), test.offset, test.length)
+		if err != nil {
+			t.Fatalf("expected no error while creating range reader, got %v", err)
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatalf("expected no error while reading file, got %v", err)
+		}
+		if string(content) != test.expected {
+			t.Errorf("expected content %v for offset %d and length %d, got %v", test.expected, test.offset, test.length, string(content))
+		}
+	}
+}
+
+// TestObjectAttrs verifies that we can get the attributes of a file, and that the ETag changes when it is written to.
+func TestObjectAttrs(t *testing.T) {
+
+	os.MkdirAll(baseDir, 0755)
+	defer os.RemoveAll(baseDir)
+
+	storage := NewFileStorage(baseDir)
+	handle := storage.Object("my-object")
+
+	_, err := handle.Attrs(context.Background())
+	if err != ErrObjectNotExist {
+		t.Fatalf("expected ErrObjectNotExist, got %v", err)
+	}
+
+	filePath := path.Join(baseDir, "my-object")
+	os.WriteFile(filePath, []byte("test data"), 0644)
+	attrs, err := handle.Attrs(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error while getting attributes, got %v", err)
+	}
+	if attrs.Size != 9 || attrs.ETag == "" || attrs.Updated.IsZero() {
+		t.Fatalf("unexpected attributes %+v", attrs)
+	}
+
+	os.WriteFile(filePath, []byte("more test data"), 0644)
+	updated, err := handle.Attrs(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error while getting attributes, got %v", err)
+	}
+	if updated.ETag == attrs.ETag {
+		t.Fatalf("expected ETag to change, got %v", updated.ETag)
+	}
+}